@@ -4,7 +4,9 @@ package org.gioui;
 
 import android.app.Activity;
 import android.os.Bundle;
+import android.content.Intent;
 import android.content.res.Configuration;
+import android.net.Uri;
 import android.view.ViewGroup;
 import android.view.View;
 import android.view.ViewGroup;
@@ -29,6 +31,18 @@ public final class GioActivity extends Activity {
 
             layer.addView(view);
             setContentView(layer);
+            openIntent(getIntent());
+	}
+
+	@Override protected void onNewIntent(Intent intent) {
+		super.onNewIntent(intent);
+		openIntent(intent);
+	}
+
+	private void openIntent(Intent intent) {
+		Uri uri = intent.getData();
+		if (uri != null)
+			GioView.onOpenURI(uri.toString());
 	}
 
 	@Override public void onDestroy() {
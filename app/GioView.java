@@ -26,6 +26,7 @@ import android.text.SpannableStringBuilder;
 import android.util.AttributeSet;
 import android.util.TypedValue;
 import android.view.Choreographer;
+import android.view.Display;
 import android.view.KeyCharacterMap;
 import android.view.KeyEvent;
 import android.view.MotionEvent;
@@ -376,7 +377,14 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 
 	@Override protected boolean fitSystemWindows(Rect insets) {
 		if (nhandle != 0) {
-			onWindowInsets(nhandle, insets.top, insets.right, insets.bottom, insets.left);
+			int ime = 0;
+			if (Build.VERSION.SDK_INT >= Build.VERSION_CODES.R) {
+				WindowInsets root = getRootWindowInsets();
+				if (root != null) {
+					ime = root.getInsets(WindowInsets.Type.ime()).bottom;
+				}
+			}
+			onWindowInsets(nhandle, insets.top, insets.right, insets.bottom, insets.left, ime);
 		}
 		return true;
 	}
@@ -400,6 +408,14 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		return getResources().getConfiguration().fontScale;
 	}
 
+	float getRefreshRate() {
+		Display d = getDisplay();
+		if (d == null) {
+			return 0;
+		}
+		return d.getRefreshRate();
+	}
+
 	public void start() {
 		if (nhandle != 0) {
 			onStartView(nhandle);
@@ -481,8 +497,9 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 	static private native void onSurfaceDestroyed(long handle);
 	static private native void onSurfaceChanged(long handle, Surface surface);
 	static private native void onConfigurationChanged(long handle);
-	static private native void onWindowInsets(long handle, int top, int right, int bottom, int left);
+	static private native void onWindowInsets(long handle, int top, int right, int bottom, int left, int ime);
 	static public native void onLowMemory();
+	static public native void onOpenURI(String uri);
 	static private native void onTouchEvent(long handle, int action, int pointerID, int tool, float x, float y, float scrollX, float scrollY, int buttons, long time);
 	static private native void onKeyEvent(long handle, int code, int character, boolean pressed, long time);
 	static private native void onFrameCallback(long handle);
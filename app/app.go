@@ -6,6 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+
+	"gioui.org/io/event"
+	"gioui.org/io/system"
 )
 
 // extraArgs contains extra arguments to append to
@@ -27,19 +31,50 @@ var extraArgs string
 //	go build -ldflags="-X 'gioui.org/app.ID=org.gioui.example.Kitchen'" .
 //
 // Note that ID is treated as a constant, and that changing it at runtime
-// is not supported. Default value of ID is filepath.Base(os.Args[0]).
+// is not supported except through SetID. Default value of ID is
+// filepath.Base(os.Args[0]).
 var ID = ""
 
+// customID is set when ID was set explicitly, either by the linker or
+// by SetID.
+var customID bool
+
+// appWindows tracks the live windows for Run and Quit.
+var appWindows struct {
+	mu   sync.Mutex
+	live map[*Window]struct{}
+	// done is closed when the last live window is destroyed, or
+	// when Quit is called while no windows are live.
+	done   chan struct{}
+	closed bool
+}
+
 func init() {
 	if extraArgs != "" {
 		args := strings.Split(extraArgs, "|")
 		os.Args = append(os.Args, args...)
 	}
+	customID = ID != ""
 	if ID == "" {
 		ID = filepath.Base(os.Args[0])
 	}
 }
 
+// SetID sets the app id exposed to the platform, overriding the value
+// of ID. The id determines the grouping of windows in the task bar and
+// the association of windows with their desktop entries and icons.
+//
+// On Windows, the id is set as the AppUserModelID of the process,
+// on Wayland it is the toplevel app_id, and on X11 it is the X11
+// XClassHint. On Android, iOS and macOS the id is determined by the
+// app package and SetID only changes ID.
+//
+// SetID must be called before the first call to NewWindow.
+func SetID(id string) {
+	ID = id
+	customID = true
+}
+
 // DataDir returns a path to use for application-specific
 // configuration data.
 // On desktop systems, DataDir use os.UserConfigDir.
@@ -63,3 +98,84 @@ func DataDir() (string, error) {
 func Main() {
 	osMain()
 }
+
+// Run is like Main, except that it returns when the last window
+// has been destroyed or after Quit is called and all windows have
+// been destroyed. If no window has been created when Run is called,
+// Run waits for one to be created.
+//
+// On macOS, Run holds the main thread and runs the Cocoa event loop
+// as required by the system. For Android and iOS Run returns
+// immediately, like Main, because the system controls the lifetime
+// of the application.
+func Run() {
+	osRun(windowsDone())
+}
+
+// Quit requests that every window close. Run returns when all
+// windows have been destroyed.
+func Quit() {
+	appWindows.mu.Lock()
+	live := make([]*Window, 0, len(appWindows.live))
+	for w := range appWindows.live {
+		live = append(live, w)
+	}
+	if len(live) == 0 {
+		closeWindowsDone()
+	}
+	appWindows.mu.Unlock()
+	for _, w := range live {
+		w.Perform(system.ActionClose)
+	}
+}
+
+// windowsDone returns the channel that is closed when no windows
+// are left.
+func windowsDone() chan struct{} {
+	appWindows.mu.Lock()
+	defer appWindows.mu.Unlock()
+	if appWindows.done == nil {
+		appWindows.done = make(chan struct{})
+	}
+	return appWindows.done
+}
+
+// closeWindowsDone closes the done channel. appWindows.mu must be held.
+func closeWindowsDone() {
+	if appWindows.done == nil {
+		appWindows.done = make(chan struct{})
+	}
+	if !appWindows.closed {
+		appWindows.closed = true
+		close(appWindows.done)
+	}
+}
+
+// addWindow registers a live window, and returns the open events
+// received while no window was live.
+func addWindow(w *Window) []event.Event {
+	appWindows.mu.Lock()
+	defer appWindows.mu.Unlock()
+	if appWindows.live == nil {
+		appWindows.live = make(map[*Window]struct{})
+	}
+	if appWindows.closed {
+		// Start over for a new Run.
+		appWindows.done = nil
+		appWindows.closed = false
+	}
+	appWindows.live[w] = struct{}{}
+	opens := pendingOpens
+	pendingOpens = nil
+	return opens
+}
+
+// removeWindow unregisters a destroyed window.
+func removeWindow(w *Window) {
+	appWindows.mu.Lock()
+	defer appWindows.mu.Unlock()
+	delete(appWindows.live, w)
+	if len(appWindows.live) == 0 {
+		closeWindowsDone()
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"unsafe"
 
+	"golang.org/x/sys/windows"
+
 	"gioui.org/gpu"
 	"gioui.org/internal/d3d11"
 )
@@ -15,7 +17,12 @@ type d3d11Context struct {
 	dev *d3d11.Device
 	ctx *d3d11.DeviceContext
 
-	swchain       *d3d11.IDXGISwapChain
+	swchain *d3d11.IDXGISwapChain
+	// comp displays swchain if it is a composition swap chain, for
+	// window materials.
+	comp *d3d11.Composition
+	// noComp is set if composition swap chains are not supported.
+	noComp        bool
 	renderTarget  *d3d11.RenderTargetView
 	width, height int
 }
@@ -26,8 +33,9 @@ func init() {
 	drivers = append(drivers, gpuAPI{
 		priority: 1,
 		initializer: func(w *window) (context, error) {
-			hwnd, _, _ := w.HWND()
-			var flags uint32
+			hwnd, width, height := w.HWND()
+			// DirectComposition requires BGRA support.
+			flags := uint32(d3d11.CREATE_DEVICE_BGRA_SUPPORT)
 			if debug {
 				flags |= d3d11.CREATE_DEVICE_DEBUG
 			}
@@ -38,13 +46,12 @@ func init() {
 			if err != nil {
 				return nil, fmt.Errorf("NewContext: %v", err)
 			}
-			swchain, err := d3d11.CreateSwapChain(dev, hwnd)
-			if err != nil {
-				d3d11.IUnknownRelease(unsafe.Pointer(ctx), ctx.Vtbl.Release)
-				d3d11.IUnknownRelease(unsafe.Pointer(dev), dev.Vtbl.Release)
+			c := &d3d11Context{win: w, dev: dev, ctx: ctx}
+			if err := c.createSwapChain(hwnd, width, height); err != nil {
+				c.Release()
 				return nil, err
 			}
-			return &d3d11Context{win: w, dev: dev, ctx: ctx, swchain: swchain}, nil
+			return c, nil
 		},
 	})
 }
@@ -53,6 +60,19 @@ func (c *d3d11Context) API() gpu.API {
 	return gpu.Direct3D11{Device: unsafe.Pointer(c.dev)}
 }
 
+func (c *d3d11Context) swapchainImages() int {
+	// The opaque swap chain has a single back buffer and the front
+	// buffer, and the composition swap chain has two buffers.
+	return 2
+}
+
+func (c *d3d11Context) presentMode() PresentMode {
+	if c.win.w.PresentMode() == PresentImmediate {
+		return PresentImmediate
+	}
+	return PresentFIFO
+}
+
 func (c *d3d11Context) RenderTarget() (gpu.RenderTarget, error) {
 	return gpu.Direct3D11RenderTarget{
 		RenderTarget: unsafe.Pointer(c.renderTarget),
@@ -60,7 +80,11 @@ func (c *d3d11Context) RenderTarget() (gpu.RenderTarget, error) {
 }
 
 func (c *d3d11Context) Present() error {
-	err := c.swchain.Present(1, 0)
+	syncInterval := 1
+	if c.win.w.PresentMode() == PresentImmediate {
+		syncInterval = 0
+	}
+	err := c.swchain.Present(syncInterval, 0)
 	if err == nil {
 		return nil
 	}
@@ -76,15 +100,50 @@ func (c *d3d11Context) Present() error {
 	return err
 }
 
+// createSwapChain creates a swap chain that blends with the window
+// material if there is one, or an opaque swap chain otherwise.
+func (c *d3d11Context) createSwapChain(hwnd windows.Handle, width, height int) error {
+	if c.win.config.Material != MaterialNone && !c.noComp {
+		swchain, comp, err := d3d11.CreateCompositionSwapChain(c.dev, hwnd, width, height)
+		if err == nil {
+			c.swchain, c.comp = swchain, comp
+			return nil
+		}
+		// Fall back to an opaque swap chain.
+		c.noComp = true
+	}
+	swchain, err := d3d11.CreateSwapChain(c.dev, hwnd)
+	if err != nil {
+		return err
+	}
+	c.swchain = swchain
+	return nil
+}
+
 func (c *d3d11Context) Refresh() error {
-	var width, height int
-	_, width, height = c.win.HWND()
-	if c.renderTarget != nil && width == c.width && height == c.height {
+	hwnd, width, height := c.win.HWND()
+	transparent := c.win.config.Material != MaterialNone && !c.noComp
+	if transparent != (c.comp != nil) {
+		// The window material changed.
+		c.releaseSwapChain()
+	}
+	if c.swchain != nil && c.renderTarget != nil && width == c.width && height == c.height {
 		return nil
 	}
 	c.releaseFBO()
-	if err := c.swchain.ResizeBuffers(0, 0, 0, d3d11.DXGI_FORMAT_UNKNOWN, 0); err != nil {
-		return err
+	if c.swchain == nil {
+		if err := c.createSwapChain(hwnd, width, height); err != nil {
+			return err
+		}
+	} else {
+		// Composition swap chains have no window to take the size from.
+		var w, h int
+		if c.comp != nil {
+			w, h = width, height
+		}
+		if err := c.swchain.ResizeBuffers(0, uint32(w), uint32(h), d3d11.DXGI_FORMAT_UNKNOWN, 0); err != nil {
+			return err
+		}
 	}
 	c.width = width
 	c.height = height
@@ -94,7 +153,13 @@ func (c *d3d11Context) Refresh() error {
 		return err
 	}
 	texture := (*d3d11.Resource)(unsafe.Pointer(backBuffer))
-	renderTarget, err := c.dev.CreateRenderTargetView(texture)
+	var renderTarget *d3d11.RenderTargetView
+	if c.comp != nil {
+		// Render through an sRGB view of the linear buffers.
+		renderTarget, err = c.dev.CreateRenderTargetViewFormat(texture, d3d11.DXGI_FORMAT_R8G8B8A8_UNORM_SRGB)
+	} else {
+		renderTarget, err = c.dev.CreateRenderTargetView(texture)
+	}
 	d3d11.IUnknownRelease(unsafe.Pointer(backBuffer), backBuffer.Vtbl.Release)
 	if err != nil {
 		return err
@@ -111,10 +176,7 @@ func (c *d3d11Context) Lock() error {
 func (c *d3d11Context) Unlock() {}
 
 func (c *d3d11Context) Release() {
-	c.releaseFBO()
-	if c.swchain != nil {
-		d3d11.IUnknownRelease(unsafe.Pointer(c.swchain), c.swchain.Vtbl.Release)
-	}
+	c.releaseSwapChain()
 	if c.ctx != nil {
 		d3d11.IUnknownRelease(unsafe.Pointer(c.ctx), c.ctx.Vtbl.Release)
 	}
@@ -127,6 +189,18 @@ func (c *d3d11Context) Release() {
 	}
 }
 
+func (c *d3d11Context) releaseSwapChain() {
+	c.releaseFBO()
+	if c.comp != nil {
+		c.comp.Release()
+		c.comp = nil
+	}
+	if c.swchain != nil {
+		d3d11.IUnknownRelease(unsafe.Pointer(c.swchain), c.swchain.Vtbl.Release)
+		c.swchain = nil
+	}
+}
+
 func (c *d3d11Context) releaseFBO() {
 	if c.renderTarget != nil {
 		d3d11.IUnknownRelease(unsafe.Pointer(c.renderTarget), c.renderTarget.Vtbl.Release)
@@ -25,7 +25,11 @@ type androidContext struct {
 
 func init() {
 	newAndroidGLESContext = func(w *window) (context, error) {
-		ctx, err := egl.NewContext(nil)
+		major, minor, err := w.callbacks.GLESVersion()
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := egl.NewContextVersion(nil, major, minor)
 		if err != nil {
 			return nil, err
 		}
@@ -34,7 +34,11 @@ type wlContext struct {
 func init() {
 	newWaylandEGLContext = func(w *window) (context, error) {
 		disp := egl.NativeDisplayType(unsafe.Pointer(w.display()))
-		ctx, err := egl.NewContext(disp)
+		major, minor, err := w.w.GLESVersion()
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := egl.NewContextVersion(disp, major, minor)
 		if err != nil {
 			return nil, err
 		}
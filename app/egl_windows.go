@@ -13,6 +13,8 @@ import (
 type glContext struct {
 	win *window
 	*egl.Context
+	// immediate is set if frames are presented without vsync.
+	immediate bool
 }
 
 func init() {
@@ -20,7 +22,11 @@ func init() {
 		priority: 2,
 		initializer: func(w *window) (context, error) {
 			disp := egl.NativeDisplayType(w.HDC())
-			ctx, err := egl.NewContext(disp)
+			major, minor, err := w.w.GLESVersion()
+			if err != nil {
+				return nil, err
+			}
+			ctx, err := egl.NewContextVersion(disp, major, minor)
 			if err != nil {
 				return nil, err
 			}
@@ -50,11 +56,19 @@ func (c *glContext) Refresh() error {
 	if err := c.Context.MakeCurrent(); err != nil {
 		return err
 	}
-	c.Context.EnableVSync(true)
+	immediate := c.win.w.PresentMode() == PresentImmediate
+	c.immediate = c.Context.EnableVSync(!immediate) && immediate
 	c.Context.ReleaseCurrent()
 	return nil
 }
 
+func (c *glContext) presentMode() PresentMode {
+	if c.immediate {
+		return PresentImmediate
+	}
+	return PresentFIFO
+}
+
 func (c *glContext) Lock() error {
 	return c.Context.MakeCurrent()
 }
@@ -16,12 +16,18 @@ import (
 type x11Context struct {
 	win *x11Window
 	*egl.Context
+	// immediate is set if frames are presented without vsync.
+	immediate bool
 }
 
 func init() {
 	newX11EGLContext = func(w *x11Window) (context, error) {
 		disp := egl.NativeDisplayType(unsafe.Pointer(w.display()))
-		ctx, err := egl.NewContext(disp)
+		major, minor, err := w.w.GLESVersion()
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := egl.NewContextVersion(disp, major, minor)
 		if err != nil {
 			return nil, err
 		}
@@ -46,11 +52,19 @@ func (c *x11Context) Refresh() error {
 	if err := c.Context.MakeCurrent(); err != nil {
 		return err
 	}
-	c.Context.EnableVSync(true)
+	immediate := c.win.w.PresentMode() == PresentImmediate
+	c.immediate = c.Context.EnableVSync(!immediate) && immediate
 	c.Context.ReleaseCurrent()
 	return nil
 }
 
+func (c *x11Context) presentMode() PresentMode {
+	if c.immediate {
+		return PresentImmediate
+	}
+	return PresentFIFO
+}
+
 func (c *x11Context) Lock() error {
 	return c.Context.MakeCurrent()
 }
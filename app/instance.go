@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gioui.org/app/internal/instance"
+	"gioui.org/io/event"
+)
+
+// InstanceEvent is sent to the windows of the running instance of a
+// program when another instance is launched. See SingleInstance.
+type InstanceEvent struct {
+	// Args are the command line arguments of the other instance,
+	// excluding the program name.
+	Args []string
+}
+
+// OpenURLEvent is sent when the program is asked to open a URL, such as
+// a link with a URL scheme registered for the program.
+type OpenURLEvent struct {
+	URL *url.URL
+}
+
+// OpenFileEvent is sent when the program is asked to open a file, such
+// as a file of a type associated with the program.
+type OpenFileEvent struct {
+	// Path is the absolute path of the file.
+	Path string
+}
+
+// pendingOpens are the open events received while no window is live,
+// guarded by appWindows.mu. They are sent to the next window created.
+var pendingOpens []event.Event
+
+// SingleInstance ensures that only one instance of the program with the
+// given id runs at a time. If another instance is already running,
+// SingleInstance forwards the command line arguments of the current
+// process to it and exits the current process. Otherwise, the current
+// process becomes the running instance, and its live windows receive an
+// InstanceEvent for every later launch, for example to open the file
+// passed on the command line and raise the window with Window.Raise.
+//
+// The command line arguments of every launch that are URLs or the paths
+// of existing files are also sent as OpenURLEvent and OpenFileEvent,
+// including the arguments of the running instance, which are sent to
+// the first window created. On macOS, URLs and files are sent by the
+// system and OpenURLEvent and OpenFileEvent are sent regardless of
+// SingleInstance. On Android, the URI of the intent that starts the app
+// is sent as OpenURLEvent.
+//
+// SingleInstance should be called early in the main function, before
+// creating windows. The instances communicate through a local socket
+// private to the user. An error is returned if the socket can't be
+// created, in which case the program may continue without the single
+// instance behavior.
+//
+// SingleInstance has no effect on Android, iOS and in browsers, where
+// the system manages the instances of programs.
+func SingleInstance(id string) error {
+	switch runtime.GOOS {
+	case "android", "ios", "js":
+		return nil
+	}
+	path, err := instance.Socket(id)
+	if err != nil {
+		return fmt.Errorf("app: SingleInstance: %w", err)
+	}
+	dir, _ := os.Getwd()
+	l, forwarded, err := instance.Start(path, instance.Message{Args: os.Args[1:], Dir: dir})
+	if err != nil {
+		return fmt.Errorf("app: SingleInstance: %w", err)
+	}
+	if forwarded {
+		os.Exit(0)
+	}
+	go instance.Serve(l, serveInstance)
+	if runtime.GOOS != "darwin" {
+		deliverOpens(openEvents(dir, os.Args[1:]))
+	}
+	return nil
+}
+
+// serveInstance delivers the launch of another instance.
+func serveInstance(m instance.Message) {
+	opens := openEvents(m.Dir, m.Args)
+	appWindows.mu.Lock()
+	defer appWindows.mu.Unlock()
+	if len(appWindows.live) == 0 {
+		pendingOpens = append(pendingOpens, opens...)
+		return
+	}
+	// Send the InstanceEvent and the open events from one goroutine,
+	// so they are processed in order.
+	events := append([]event.Event{InstanceEvent{Args: m.Args}}, opens...)
+	for w := range appWindows.live {
+		go w.sendOpens(events)
+	}
+}
+
+// openEvents returns the open events for the arguments that are URLs or
+// the paths of existing files. Relative paths are relative to dir.
+func openEvents(dir string, args []string) []event.Event {
+	var events []event.Event
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			// Skip flags.
+			continue
+		}
+		if u := argURL(arg); u != nil {
+			events = append(events, openURLEvent(u))
+			continue
+		}
+		path := arg
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			events = append(events, OpenFileEvent{Path: path})
+		}
+	}
+	return events
+}
+
+// opaqueSchemes are the URL schemes recognized in arguments without
+// an authority, such as "mailto:gio@example.com".
+var opaqueSchemes = map[string]bool{
+	"data": true, "file": true, "magnet": true, "mailto": true,
+	"news": true, "sms": true, "tel": true, "urn": true,
+}
+
+// argURL returns the URL in arg, or nil if arg is not a URL. A URL must
+// have an authority, as in "https://gioui.org", or use one of the
+// opaqueSchemes. In particular, "host:8080" and Windows paths such as
+// "C:\file" are not URLs.
+func argURL(arg string) *url.URL {
+	u, err := url.Parse(arg)
+	if err != nil || u.Scheme == "" {
+		return nil
+	}
+	if !strings.HasPrefix(arg[len(u.Scheme)+1:], "//") && !opaqueSchemes[u.Scheme] {
+		return nil
+	}
+	return u
+}
+
+// openURLEvent returns the open event for u, an OpenFileEvent for file
+// URLs and an OpenURLEvent otherwise.
+func openURLEvent(u *url.URL) event.Event {
+	if u.Scheme == "file" {
+		return OpenFileEvent{Path: fileURLPath(u)}
+	}
+	return OpenURLEvent{URL: u}
+}
+
+// fileURLPath returns the local path of a file URL.
+func fileURLPath(u *url.URL) string {
+	p := u.Path
+	if runtime.GOOS == "windows" && len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		// Strip the slash before the drive letter.
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+// fileURLPaths returns the paths of the file URLs in a text/uri-list,
+// for dragging files to file managers.
+func fileURLPaths(list []byte) []string {
+	var paths []string
+	for _, line := range strings.Split(string(list), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			// Skip comments.
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Scheme != "file" {
+			continue
+		}
+		paths = append(paths, fileURLPath(u))
+	}
+	return paths
+}
+
+// deliverOpens sends events to the live windows, or to the next window
+// created if no window is live. It doesn't block.
+func deliverOpens(events []event.Event) {
+	if len(events) == 0 {
+		return
+	}
+	appWindows.mu.Lock()
+	defer appWindows.mu.Unlock()
+	if len(appWindows.live) == 0 {
+		pendingOpens = append(pendingOpens, events...)
+		return
+	}
+	for w := range appWindows.live {
+		go w.sendOpens(events)
+	}
+}
+
+// sendOpens processes events in order.
+func (w *Window) sendOpens(events []event.Event) {
+	for _, e := range events {
+		e := e
+		w.driverDefer(func(d driver) {
+			w.processEvent(d, e)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestArgURL(t *testing.T) {
+	tests := []struct {
+		arg string
+		url bool
+	}{
+		{"https://gioui.org/doc", true},
+		{"myapp://open?id=1", true},
+		{"file:///tmp/file.txt", true},
+		{"mailto:gio@example.com", true},
+		{"host:8080", false},
+		{"localhost:8080/path", false},
+		{`C:\Users\file.txt`, false},
+		{"C:/Users/file.txt", false},
+		{"file.txt", false},
+	}
+	for _, test := range tests {
+		if got := argURL(test.arg) != nil; got != test.url {
+			t.Errorf("argURL(%q) != nil is %v, want %v", test.arg, got, test.url)
+		}
+	}
+}
+
+func TestFileURLPath(t *testing.T) {
+	tests := []struct {
+		url, path string
+	}{
+		{"file:///tmp/file.txt", "/tmp/file.txt"},
+		{"file:///C:/Users/file.txt", "C:/Users/file.txt"},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := test.path
+		if runtime.GOOS != "windows" && strings.Contains(want, ":") {
+			// Only Windows paths have drive letters.
+			want = "/" + want
+		}
+		if got := fileURLPath(u); got != filepath.FromSlash(want) {
+			t.Errorf("fileURLPath(%q) = %q, want %q", test.url, got, filepath.FromSlash(want))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package instance implements the local socket protocol between the
+// instances of a program for app.SingleInstance.
+package instance
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// timeout bounds the exchange of a message, so that a stuck peer doesn't
+// block other instances.
+var timeout = 5 * time.Second
+
+// Message is sent by a launched instance to the running instance.
+type Message struct {
+	// Args are the command line arguments, excluding the program name.
+	Args []string
+	// Dir is the working directory, for resolving relative paths.
+	Dir string
+}
+
+// Socket returns the socket path for id. The socket is in a directory
+// private to the user, which is created if needed.
+func Socket(id string) (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		// The temporary directory may be shared by all users.
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("gio-%d", os.Getuid()))
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", err
+		}
+		if err := checkPrivate(dir); err != nil {
+			return "", err
+		}
+	}
+	// Hash the id to get a valid and short enough file name.
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s-%d", id, os.Getuid())))
+	return filepath.Join(dir, fmt.Sprintf("gio-%x.sock", sum[:8])), nil
+}
+
+// Start forwards m to the instance listening at path, if any, and
+// reports whether it succeeded. Otherwise, Start listens at path and
+// returns the listener for Serve. Concurrent calls are serialized by a
+// lock file next to the socket, so that exactly one of the instances
+// started at the same time listens.
+func Start(path string, m Message) (l net.Listener, forwarded bool, err error) {
+	unlock, err := lock(path + ".lock")
+	if err != nil {
+		return nil, false, err
+	}
+	defer unlock()
+	if Forward(path, m) == nil {
+		return nil, true, nil
+	}
+	// The socket of an instance that exited is stale.
+	os.Remove(path)
+	l, err = net.Listen("unix", path)
+	if err != nil {
+		return nil, false, err
+	}
+	return l, false, nil
+}
+
+// Forward sends m to the instance listening at path.
+func Forward(path string, m Message) error {
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := c.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(c)
+	if err := enc.Encode(m.Args); err != nil {
+		return err
+	}
+	// The directory follows the arguments, for compatibility with
+	// instances that expect only the arguments.
+	return enc.Encode(m.Dir)
+}
+
+// Serve calls handle for every message received by l, until l is
+// closed.
+func Serve(l net.Listener, handle func(m Message)) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		var m Message
+		c.SetDeadline(time.Now().Add(timeout))
+		dec := json.NewDecoder(c)
+		err = dec.Decode(&m.Args)
+		if err == nil {
+			// The directory is missing if sent by an older version.
+			dec.Decode(&m.Dir)
+		}
+		c.Close()
+		if err != nil {
+			continue
+		}
+		handle(m)
+	}
+}
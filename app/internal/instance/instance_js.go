@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package instance
+
+import "errors"
+
+func lock(path string) (unlock func(), err error) {
+	return nil, errors.New("instance: not supported")
+}
+
+func checkPrivate(dir string) error {
+	return errors.New("instance: not supported")
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package instance
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestForward(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	l, forwarded, err := Start(path, Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forwarded {
+		t.Fatal("first instance was forwarded")
+	}
+	defer l.Close()
+	msgs := make(chan Message, 1)
+	go Serve(l, func(m Message) { msgs <- m })
+
+	want := Message{Args: []string{"-flag", "file.txt"}, Dir: "/home/user"}
+	_, forwarded, err = Start(path, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !forwarded {
+		t.Fatal("second instance wasn't forwarded")
+	}
+	if got := <-msgs; !reflect.DeepEqual(got, want) {
+		t.Errorf("got message %+v, want %+v", got, want)
+	}
+}
+
+func TestStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	l, _, err := Start(path, Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Leave the socket file behind, as a crashed instance would.
+	if ul, ok := l.(interface{ SetUnlinkOnClose(bool) }); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	l.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	l, forwarded, err := Start(path, Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if forwarded {
+		t.Error("message was forwarded to an exited instance")
+	}
+}
+
+func TestConcurrentStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	const n = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var listeners int
+	var forwards int
+	received := make(chan Message, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l, forwarded, err := Start(path, Message{Args: []string{"arg"}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if forwarded {
+				forwards++
+				return
+			}
+			listeners++
+			go Serve(l, func(m Message) { received <- m })
+			t.Cleanup(func() { l.Close() })
+		}()
+	}
+	wg.Wait()
+	if listeners != 1 || forwards != n-1 {
+		t.Fatalf("got %d listeners and %d forwards, want 1 and %d", listeners, forwards, n-1)
+	}
+	for i := 0; i < n-1; i++ {
+		<-received
+	}
+}
+
+func TestSilentClient(t *testing.T) {
+	defer func(d time.Duration) { timeout = d }(timeout)
+	timeout = 50 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "test.sock")
+	l, _, err := Start(path, Message{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	msgs := make(chan Message, 1)
+	go Serve(l, func(m Message) { msgs <- m })
+
+	// A client that sends nothing must not block other instances.
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	want := Message{Args: []string{"file.txt"}}
+	if _, forwarded, err := Start(path, want); err != nil || !forwarded {
+		t.Fatalf("message not forwarded: %v", err)
+	}
+	if got := <-msgs; !reflect.DeepEqual(got, want) {
+		t.Errorf("got message %+v, want %+v", got, want)
+	}
+}
+
+func TestSocketPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the temporary directory is private on Windows")
+	}
+	tmp := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tmp)
+	path, err := Socket("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	if filepath.Dir(dir) != tmp {
+		t.Errorf("socket %s is not in a directory of %s", path, tmp)
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o700 {
+		t.Errorf("socket directory has permissions %o, want 700", perm)
+	}
+	// A directory accessible to others is rejected.
+	if err := os.Chmod(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Socket("test"); err == nil {
+		t.Error("Socket accepted a directory accessible to other users")
+	}
+}
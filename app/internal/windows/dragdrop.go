@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build windows
+// +build windows
+
+package windows
+
+import (
+	"fmt"
+	"sync"
+	gosyscall "syscall"
+	"unsafe"
+
+	syscall "golang.org/x/sys/windows"
+)
+
+// DragFormat is the data of a drag in a clipboard format.
+type DragFormat struct {
+	Format uint32
+	Data   []byte
+}
+
+const (
+	CF_HDROP = 15
+
+	DROPEFFECT_NONE = 0
+	DROPEFFECT_COPY = 1
+	DROPEFFECT_MOVE = 2
+
+	S_OK                         = 0
+	DRAGDROP_S_DROP              = 0x00040100
+	DRAGDROP_S_CANCEL            = 0x00040101
+	DRAGDROP_S_USEDEFAULTCURSORS = 0x00040102
+	OLE_E_ADVISENOTSUPPORTED     = 0x80040003
+	DV_E_FORMATETC               = 0x80040064
+	E_NOTIMPL                    = 0x80004001
+	E_NOINTERFACE                = 0x80004002
+	E_OUTOFMEMORY                = 0x8007000E
+
+	DATADIR_GET      = 1
+	TYMED_HGLOBAL    = 1
+	DVASPECT_CONTENT = 1
+
+	MK_LBUTTON = 0x1
+	MK_RBUTTON = 0x2
+	MK_MBUTTON = 0x10
+)
+
+// sizeofDropFiles is the size of the DROPFILES structure, which is
+// followed by the file names.
+const sizeofDropFiles = 20
+
+var (
+	IID_IUnknown    = GUID{0x00000000, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	IID_IDataObject = GUID{0x0000010E, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	IID_IDropSource = GUID{0x00000121, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+)
+
+var (
+	_OleInitialize         = ole32.NewProc("OleInitialize")
+	_OleUninitialize       = ole32.NewProc("OleUninitialize")
+	_DoDragDrop            = ole32.NewProc("DoDragDrop")
+	_SHCreateStdEnumFmtEtc = shell32.NewProc("SHCreateStdEnumFmtEtc")
+)
+
+// formatEtc is the FORMATETC structure.
+type formatEtc struct {
+	cfFormat uint16
+	ptd      uintptr
+	dwAspect uint32
+	lindex   int32
+	tymed    uint32
+}
+
+// stgMedium is the STGMEDIUM structure.
+type stgMedium struct {
+	tymed          uint32
+	handle         uintptr
+	pUnkForRelease uintptr
+}
+
+// dataObject implements IDataObject for the data of a drag.
+type dataObject struct {
+	vtbl    *dataObjectVtbl
+	refs    int32
+	formats []formatEtc
+	data    [][]byte
+}
+
+type dataObjectVtbl struct {
+	QueryInterface        uintptr
+	AddRef                uintptr
+	Release               uintptr
+	GetData               uintptr
+	GetDataHere           uintptr
+	QueryGetData          uintptr
+	GetCanonicalFormatEtc uintptr
+	SetData               uintptr
+	EnumFormatEtc         uintptr
+	DAdvise               uintptr
+	DUnadvise             uintptr
+	EnumDAdvise           uintptr
+}
+
+// dropSource implements IDropSource.
+type dropSource struct {
+	vtbl *dropSourceVtbl
+	refs int32
+}
+
+type dropSourceVtbl struct {
+	QueryInterface    uintptr
+	AddRef            uintptr
+	Release           uintptr
+	QueryContinueDrag uintptr
+	GiveFeedback      uintptr
+}
+
+var (
+	comVtbls struct {
+		once       sync.Once
+		dataObject dataObjectVtbl
+		dropSource dropSourceVtbl
+	}
+	// comObjects keeps the objects referenced by COM alive.
+	comObjects = make(map[uintptr]interface{})
+)
+
+func initVtbls() {
+	comVtbls.once.Do(func() {
+		comVtbls.dataObject = dataObjectVtbl{
+			QueryInterface: gosyscall.NewCallback(func(o *dataObject, riid *GUID, ppv *uintptr) uintptr {
+				return queryInterface(uintptr(unsafe.Pointer(o)), &o.refs, riid, ppv, &IID_IDataObject)
+			}),
+			AddRef: gosyscall.NewCallback(func(o *dataObject) uintptr {
+				o.refs++
+				return uintptr(o.refs)
+			}),
+			Release: gosyscall.NewCallback(func(o *dataObject) uintptr {
+				o.refs--
+				if o.refs == 0 {
+					delete(comObjects, uintptr(unsafe.Pointer(o)))
+				}
+				return uintptr(o.refs)
+			}),
+			GetData: gosyscall.NewCallback(func(o *dataObject, format *formatEtc, medium *stgMedium) uintptr {
+				i := o.find(format)
+				if i == -1 {
+					return DV_E_FORMATETC
+				}
+				data := o.data[i]
+				size := len(data)
+				if size == 0 {
+					// Zero sized memory can't be locked.
+					size = 1
+				}
+				mem, err := GlobalAlloc(size)
+				if err != nil {
+					return E_OUTOFMEMORY
+				}
+				if len(data) > 0 {
+					ptr, err := GlobalLock(mem)
+					if err != nil {
+						GlobalFree(mem)
+						return E_OUTOFMEMORY
+					}
+					copy(unsafe.Slice((*byte)(ptr), len(data)), data)
+					GlobalUnlock(mem)
+				}
+				*medium = stgMedium{tymed: TYMED_HGLOBAL, handle: uintptr(mem)}
+				return S_OK
+			}),
+			GetDataHere: gosyscall.NewCallback(func(this, format, medium uintptr) uintptr {
+				return E_NOTIMPL
+			}),
+			QueryGetData: gosyscall.NewCallback(func(o *dataObject, format *formatEtc) uintptr {
+				if o.find(format) == -1 {
+					return DV_E_FORMATETC
+				}
+				return S_OK
+			}),
+			GetCanonicalFormatEtc: gosyscall.NewCallback(func(this, in, out uintptr) uintptr {
+				return E_NOTIMPL
+			}),
+			SetData: gosyscall.NewCallback(func(this, format, medium, release uintptr) uintptr {
+				return E_NOTIMPL
+			}),
+			EnumFormatEtc: gosyscall.NewCallback(func(o *dataObject, direction, ppenum uintptr) uintptr {
+				if direction != DATADIR_GET {
+					return E_NOTIMPL
+				}
+				r, _, _ := _SHCreateStdEnumFmtEtc.Call(uintptr(len(o.formats)), uintptr(unsafe.Pointer(&o.formats[0])), ppenum)
+				return r
+			}),
+			DAdvise: gosyscall.NewCallback(func(this, format, advf, sink, conn uintptr) uintptr {
+				return OLE_E_ADVISENOTSUPPORTED
+			}),
+			DUnadvise: gosyscall.NewCallback(func(this, conn uintptr) uintptr {
+				return OLE_E_ADVISENOTSUPPORTED
+			}),
+			EnumDAdvise: gosyscall.NewCallback(func(this, ppenum uintptr) uintptr {
+				return OLE_E_ADVISENOTSUPPORTED
+			}),
+		}
+		comVtbls.dropSource = dropSourceVtbl{
+			QueryInterface: gosyscall.NewCallback(func(s *dropSource, riid *GUID, ppv *uintptr) uintptr {
+				return queryInterface(uintptr(unsafe.Pointer(s)), &s.refs, riid, ppv, &IID_IDropSource)
+			}),
+			AddRef: gosyscall.NewCallback(func(s *dropSource) uintptr {
+				s.refs++
+				return uintptr(s.refs)
+			}),
+			Release: gosyscall.NewCallback(func(s *dropSource) uintptr {
+				s.refs--
+				if s.refs == 0 {
+					delete(comObjects, uintptr(unsafe.Pointer(s)))
+				}
+				return uintptr(s.refs)
+			}),
+			QueryContinueDrag: gosyscall.NewCallback(func(this, escape, keyState uintptr) uintptr {
+				switch {
+				case escape != 0:
+					return DRAGDROP_S_CANCEL
+				case keyState&(MK_LBUTTON|MK_RBUTTON|MK_MBUTTON) == 0:
+					return DRAGDROP_S_DROP
+				}
+				return S_OK
+			}),
+			GiveFeedback: gosyscall.NewCallback(func(this, effect uintptr) uintptr {
+				return DRAGDROP_S_USEDEFAULTCURSORS
+			}),
+		}
+	})
+}
+
+// queryInterface implements IUnknown.QueryInterface for the object at
+// this with the reference count refs.
+func queryInterface(this uintptr, refs *int32, riid *GUID, ppv *uintptr, iid *GUID) uintptr {
+	if *riid != IID_IUnknown && *riid != *iid {
+		*ppv = 0
+		return E_NOINTERFACE
+	}
+	*ppv = this
+	*refs++
+	return S_OK
+}
+
+// find returns the index of the format matching f, or -1.
+func (o *dataObject) find(f *formatEtc) int {
+	if f.tymed&TYMED_HGLOBAL == 0 || f.dwAspect != DVASPECT_CONTENT {
+		return -1
+	}
+	for i, of := range o.formats {
+		if of.cfFormat == f.cfFormat {
+			return i
+		}
+	}
+	return -1
+}
+
+// DoDragDrop runs a modal drag and drop operation of the data in
+// formats, and returns the effect chosen by the drop target, or
+// DROPEFFECT_NONE if the drag was canceled. It must be called from the
+// thread of the window that started the drag, while a mouse button is
+// pressed.
+func DoDragDrop(formats []DragFormat, effects uint32) (uint32, error) {
+	if len(formats) == 0 {
+		return DROPEFFECT_NONE, nil
+	}
+	if r, _, _ := _OleInitialize.Call(0); int32(r) < 0 {
+		return DROPEFFECT_NONE, fmt.Errorf("OleInitialize: %#x", r)
+	}
+	defer _OleUninitialize.Call()
+	initVtbls()
+	obj := &dataObject{vtbl: &comVtbls.dataObject, refs: 1}
+	for _, f := range formats {
+		obj.formats = append(obj.formats, formatEtc{
+			cfFormat: uint16(f.Format),
+			dwAspect: DVASPECT_CONTENT,
+			lindex:   -1,
+			tymed:    TYMED_HGLOBAL,
+		})
+		obj.data = append(obj.data, f.Data)
+	}
+	src := &dropSource{vtbl: &comVtbls.dropSource, refs: 1}
+	objPtr, srcPtr := uintptr(unsafe.Pointer(obj)), uintptr(unsafe.Pointer(src))
+	comObjects[objPtr] = obj
+	comObjects[srcPtr] = src
+	var effect uint32
+	r, _, _ := _DoDragDrop.Call(objPtr, srcPtr, uintptr(effects), uintptr(unsafe.Pointer(&effect)))
+	// Release the initial references.
+	gosyscall.SyscallN(obj.vtbl.Release, objPtr)
+	gosyscall.SyscallN(src.vtbl.Release, srcPtr)
+	switch r {
+	case DRAGDROP_S_DROP:
+		return effect, nil
+	case DRAGDROP_S_CANCEL:
+		return DROPEFFECT_NONE, nil
+	default:
+		return DROPEFFECT_NONE, fmt.Errorf("DoDragDrop: %#x", r)
+	}
+}
+
+// DropFiles returns the CF_HDROP data for a list of file paths.
+func DropFiles(paths []string) []byte {
+	var names []uint16
+	for _, p := range paths {
+		names = append(names, syscall.StringToUTF16(p)...)
+	}
+	// The list ends with an empty name.
+	names = append(names, 0)
+	data := make([]byte, sizeofDropFiles+2*len(names))
+	// Set DROPFILES.pFiles to the offset of the names, and
+	// DROPFILES.fWide for UTF-16 names.
+	*(*uint32)(unsafe.Pointer(&data[0])) = sizeofDropFiles
+	*(*uint32)(unsafe.Pointer(&data[16])) = 1
+	copy(unsafe.Slice((*uint16)(unsafe.Pointer(&data[sizeofDropFiles])), len(names)), names)
+	return data
+}
@@ -79,6 +79,44 @@ type WindowPlacement struct {
 	rcDevice         Rect
 }
 
+// accentPolicy is the ACCENT_POLICY structure of
+// SetWindowCompositionAttribute.
+type accentPolicy struct {
+	AccentState   uint32
+	AccentFlags   uint32
+	GradientColor uint32
+	AnimationId   uint32
+}
+
+// windowCompositionAttribData is the WINDOWCOMPOSITIONATTRIBDATA
+// structure of SetWindowCompositionAttribute.
+type windowCompositionAttribData struct {
+	Attrib uint32
+	Data   uintptr
+	Size   uintptr
+}
+
+// Margins is the MARGINS structure of DwmExtendFrameIntoClientArea.
+type Margins struct {
+	CxLeftWidth, CxRightWidth, CyTopHeight, CyBottomHeight int32
+}
+
+// HighContrast is the HIGHCONTRAST structure of SystemParametersInfo.
+type HighContrast struct {
+	size          uint32
+	Flags         uint32
+	DefaultScheme *uint16
+}
+
+// IconInfo is the ICONINFO structure of CreateIconIndirect.
+type IconInfo struct {
+	Icon     int32
+	XHotspot uint32
+	YHotspot uint32
+	Mask     syscall.Handle
+	Color    syscall.Handle
+}
+
 type MonitorInfo struct {
 	cbSize   uint32
 	Monitor  Rect
@@ -89,6 +127,21 @@ type MonitorInfo struct {
 const (
 	TRUE = 1
 
+	DWMWA_NCRENDERING_POLICY  = 2
+	DWMWA_SYSTEMBACKDROP_TYPE = 38
+
+	WCA_ACCENT_POLICY = 19
+
+	ACCENT_DISABLED          = 0
+	ACCENT_ENABLE_BLURBEHIND = 3
+
+	DWMNCRP_USEWINDOWSTYLE = 0
+	DWMNCRP_ENABLED        = 2
+
+	DWMSBT_NONE            = 1
+	DWMSBT_MAINWINDOW      = 2
+	DWMSBT_TRANSIENTWINDOW = 3
+
 	CPS_CANCEL = 0x0004
 
 	CS_HREDRAW     = 0x0002
@@ -99,7 +152,8 @@ const (
 
 	CW_USEDEFAULT = -2147483648
 
-	GWL_STYLE = ^(uintptr(16) - 1) // -16
+	GWL_STYLE   = ^(uintptr(16) - 1) // -16
+	GWL_EXSTYLE = ^(uintptr(20) - 1) // -20
 
 	GCS_COMPSTR       = 0x0008
 	GCS_COMPREADSTR   = 0x0001
@@ -111,6 +165,7 @@ const (
 	CFS_POINT        = 0x0002
 	CFS_CANDIDATEPOS = 0x0040
 
+	HWND_TOP     = 0
 	HWND_TOPMOST = ^(uint32(1) - 1) // -1
 
 	HTCAPTION     = 2
@@ -124,6 +179,8 @@ const (
 	HTBOTTOMLEFT  = 16
 	HTBOTTOMRIGHT = 17
 
+	MA_ACTIVATEANDEAT = 2
+
 	IDC_APPSTARTING = 32650 // Standard arrow and small hourglass
 	IDC_ARROW       = 32512 // Standard arrow
 	IDC_CROSS       = 32515 // Crosshair
@@ -142,6 +199,7 @@ const (
 	INFINITE = 0xFFFFFFFF
 
 	LOGPIXELSX = 88
+	VREFRESH   = 116
 
 	MDT_EFFECTIVE_DPI = 0
 
@@ -155,14 +213,38 @@ const (
 
 	SCS_SETSTR = GCS_COMPREADSTR | GCS_COMPSTR
 
+	SC_SIZE     = 0xF000
+	SC_MOVE     = 0xF010
+	SC_MINIMIZE = 0xF020
+
 	SM_CXSIZEFRAME = 32
+	SM_CXDOUBLECLK = 36
 	SM_CYSIZEFRAME = 33
 
+	SPI_GETHIGHCONTRAST = 0x0042
+	SPI_SETHIGHCONTRAST = 0x0043
+	SPI_SETWORKAREA     = 0x002F
+
+	RGN_OR = 2
+
+	HCF_HIGHCONTRASTON = 0x00000001
+
+	COLOR_WINDOW        = 5
+	COLOR_WINDOWTEXT    = 8
+	COLOR_HIGHLIGHT     = 13
+	COLOR_HIGHLIGHTTEXT = 14
+	COLOR_BTNFACE       = 15
+	COLOR_GRAYTEXT      = 17
+	COLOR_BTNTEXT       = 18
+	COLOR_HOTLIGHT      = 26
+
+	SW_HIDE          = 0
 	SW_SHOWDEFAULT   = 10
 	SW_SHOWMINIMIZED = 2
 	SW_SHOWMAXIMIZED = 3
 	SW_SHOWNORMAL    = 1
 	SW_SHOW          = 5
+	SW_RESTORE       = 9
 
 	SWP_FRAMECHANGED  = 0x0020
 	SWP_NOMOVE        = 0x0002
@@ -228,6 +310,10 @@ const (
 	WM_CREATE               = 0x0001
 	WM_DPICHANGED           = 0x02E0
 	WM_DESTROY              = 0x0002
+	WM_DISPLAYCHANGE        = 0x007E
+	WM_SETTINGCHANGE        = 0x001A
+	WM_SYSCOLORCHANGE       = 0x0015
+	WM_ENDSESSION           = 0x0016
 	WM_ERASEBKGND           = 0x0014
 	WM_GETMINMAXINFO        = 0x0024
 	WM_IME_COMPOSITION      = 0x010F
@@ -241,8 +327,10 @@ const (
 	WM_MBUTTONDOWN          = 0x0207
 	WM_MBUTTONUP            = 0x0208
 	WM_MOUSEMOVE            = 0x0200
+	WM_MOVE                 = 0x0003
 	WM_MOUSEWHEEL           = 0x020A
 	WM_MOUSEHWHEEL          = 0x020E
+	WM_MOUSEACTIVATE        = 0x0021
 	WM_NCACTIVATE           = 0x0086
 	WM_NCHITTEST            = 0x0084
 	WM_PAINT                = 0x000F
@@ -251,15 +339,28 @@ const (
 	WM_SETFOCUS             = 0x0007
 	WM_SHOWWINDOW           = 0x0018
 	WM_SIZE                 = 0x0005
+	WM_SIZING               = 0x0214
 	WM_SYSKEYDOWN           = 0x0104
 	WM_SYSKEYUP             = 0x0105
+	WM_SYSCOMMAND           = 0x0112
 	WM_RBUTTONDOWN          = 0x0204
 	WM_RBUTTONUP            = 0x0205
 	WM_TIMER                = 0x0113
+	WM_ENTERSIZEMOVE        = 0x0231
+	WM_EXITSIZEMOVE         = 0x0232
 	WM_UNICHAR              = 0x0109
 	WM_USER                 = 0x0400
 	WM_WINDOWPOSCHANGED     = 0x0047
 
+	WMSZ_LEFT        = 1
+	WMSZ_RIGHT       = 2
+	WMSZ_TOP         = 3
+	WMSZ_TOPLEFT     = 4
+	WMSZ_TOPRIGHT    = 5
+	WMSZ_BOTTOM      = 6
+	WMSZ_BOTTOMLEFT  = 7
+	WMSZ_BOTTOMRIGHT = 8
+
 	WS_CLIPCHILDREN     = 0x02000000
 	WS_CLIPSIBLINGS     = 0x04000000
 	WS_MAXIMIZE         = 0x01000000
@@ -276,6 +377,7 @@ const (
 
 	WS_EX_APPWINDOW  = 0x00040000
 	WS_EX_WINDOWEDGE = 0x00000100
+	WS_EX_TOOLWINDOW = 0x00000080
 
 	QS_ALLINPUT = 0x04FF
 
@@ -311,66 +413,89 @@ var (
 	_GlobalAlloc      = kernel32.NewProc("GlobalAlloc")
 	_GlobalFree       = kernel32.NewProc("GlobalFree")
 	_GlobalLock       = kernel32.NewProc("GlobalLock")
+	_GlobalSize       = kernel32.NewProc("GlobalSize")
 	_GlobalUnlock     = kernel32.NewProc("GlobalUnlock")
 
-	user32                       = syscall.NewLazySystemDLL("user32.dll")
-	_AdjustWindowRectEx          = user32.NewProc("AdjustWindowRectEx")
-	_CallMsgFilter               = user32.NewProc("CallMsgFilterW")
-	_CloseClipboard              = user32.NewProc("CloseClipboard")
-	_CreateWindowEx              = user32.NewProc("CreateWindowExW")
-	_DefWindowProc               = user32.NewProc("DefWindowProcW")
-	_DestroyWindow               = user32.NewProc("DestroyWindow")
-	_DispatchMessage             = user32.NewProc("DispatchMessageW")
-	_EmptyClipboard              = user32.NewProc("EmptyClipboard")
-	_GetWindowRect               = user32.NewProc("GetWindowRect")
-	_GetClipboardData            = user32.NewProc("GetClipboardData")
-	_GetDC                       = user32.NewProc("GetDC")
-	_GetDpiForWindow             = user32.NewProc("GetDpiForWindow")
-	_GetKeyState                 = user32.NewProc("GetKeyState")
-	_GetMessage                  = user32.NewProc("GetMessageW")
-	_GetMessageTime              = user32.NewProc("GetMessageTime")
-	_GetMonitorInfo              = user32.NewProc("GetMonitorInfoW")
-	_GetSystemMetrics            = user32.NewProc("GetSystemMetrics")
-	_GetWindowLong               = user32.NewProc("GetWindowLongPtrW")
-	_GetWindowLong32             = user32.NewProc("GetWindowLongW")
-	_GetWindowPlacement          = user32.NewProc("GetWindowPlacement")
-	_KillTimer                   = user32.NewProc("KillTimer")
-	_LoadCursor                  = user32.NewProc("LoadCursorW")
-	_LoadImage                   = user32.NewProc("LoadImageW")
-	_MonitorFromPoint            = user32.NewProc("MonitorFromPoint")
-	_MonitorFromWindow           = user32.NewProc("MonitorFromWindow")
-	_MoveWindow                  = user32.NewProc("MoveWindow")
-	_MsgWaitForMultipleObjectsEx = user32.NewProc("MsgWaitForMultipleObjectsEx")
-	_OpenClipboard               = user32.NewProc("OpenClipboard")
-	_PeekMessage                 = user32.NewProc("PeekMessageW")
-	_PostMessage                 = user32.NewProc("PostMessageW")
-	_PostQuitMessage             = user32.NewProc("PostQuitMessage")
-	_ReleaseCapture              = user32.NewProc("ReleaseCapture")
-	_RegisterClassExW            = user32.NewProc("RegisterClassExW")
-	_ReleaseDC                   = user32.NewProc("ReleaseDC")
-	_ScreenToClient              = user32.NewProc("ScreenToClient")
-	_ShowWindow                  = user32.NewProc("ShowWindow")
-	_SetCapture                  = user32.NewProc("SetCapture")
-	_SetCursor                   = user32.NewProc("SetCursor")
-	_SetClipboardData            = user32.NewProc("SetClipboardData")
-	_SetForegroundWindow         = user32.NewProc("SetForegroundWindow")
-	_SetFocus                    = user32.NewProc("SetFocus")
-	_SetProcessDPIAware          = user32.NewProc("SetProcessDPIAware")
-	_SetTimer                    = user32.NewProc("SetTimer")
-	_SetWindowLong               = user32.NewProc("SetWindowLongPtrW")
-	_SetWindowLong32             = user32.NewProc("SetWindowLongW")
-	_SetWindowPlacement          = user32.NewProc("SetWindowPlacement")
-	_SetWindowPos                = user32.NewProc("SetWindowPos")
-	_SetWindowText               = user32.NewProc("SetWindowTextW")
-	_TranslateMessage            = user32.NewProc("TranslateMessage")
-	_UnregisterClass             = user32.NewProc("UnregisterClassW")
-	_UpdateWindow                = user32.NewProc("UpdateWindow")
+	_GetUserDefaultLocaleName = kernel32.NewProc("GetUserDefaultLocaleName")
+
+	user32                         = syscall.NewLazySystemDLL("user32.dll")
+	_AdjustWindowRectEx            = user32.NewProc("AdjustWindowRectEx")
+	_CallMsgFilter                 = user32.NewProc("CallMsgFilterW")
+	_CloseClipboard                = user32.NewProc("CloseClipboard")
+	_CreateWindowEx                = user32.NewProc("CreateWindowExW")
+	_DefWindowProc                 = user32.NewProc("DefWindowProcW")
+	_DestroyWindow                 = user32.NewProc("DestroyWindow")
+	_DispatchMessage               = user32.NewProc("DispatchMessageW")
+	_EmptyClipboard                = user32.NewProc("EmptyClipboard")
+	_EnumClipboardFormats          = user32.NewProc("EnumClipboardFormats")
+	_FillRect                      = user32.NewProc("FillRect")
+	_GetWindowRect                 = user32.NewProc("GetWindowRect")
+	_GetClipboardData              = user32.NewProc("GetClipboardData")
+	_GetClipboardFormatName        = user32.NewProc("GetClipboardFormatNameW")
+	_GetDC                         = user32.NewProc("GetDC")
+	_GetDpiForWindow               = user32.NewProc("GetDpiForWindow")
+	_GetKeyState                   = user32.NewProc("GetKeyState")
+	_GetMessage                    = user32.NewProc("GetMessageW")
+	_GetMessageTime                = user32.NewProc("GetMessageTime")
+	_GetMonitorInfo                = user32.NewProc("GetMonitorInfoW")
+	_GetSystemMetrics              = user32.NewProc("GetSystemMetrics")
+	_GetSysColor                   = user32.NewProc("GetSysColor")
+	_SystemParametersInfo          = user32.NewProc("SystemParametersInfoW")
+	_GetWindowLong                 = user32.NewProc("GetWindowLongPtrW")
+	_GetWindowLong32               = user32.NewProc("GetWindowLongW")
+	_GetWindowPlacement            = user32.NewProc("GetWindowPlacement")
+	_KillTimer                     = user32.NewProc("KillTimer")
+	_CreateIconIndirect            = user32.NewProc("CreateIconIndirect")
+	_DestroyIcon                   = user32.NewProc("DestroyIcon")
+	_LoadCursor                    = user32.NewProc("LoadCursorW")
+	_LoadImage                     = user32.NewProc("LoadImageW")
+	_MonitorFromPoint              = user32.NewProc("MonitorFromPoint")
+	_MonitorFromWindow             = user32.NewProc("MonitorFromWindow")
+	_MoveWindow                    = user32.NewProc("MoveWindow")
+	_MsgWaitForMultipleObjectsEx   = user32.NewProc("MsgWaitForMultipleObjectsEx")
+	_OpenClipboard                 = user32.NewProc("OpenClipboard")
+	_PeekMessage                   = user32.NewProc("PeekMessageW")
+	_PostMessage                   = user32.NewProc("PostMessageW")
+	_PostQuitMessage               = user32.NewProc("PostQuitMessage")
+	_ReleaseCapture                = user32.NewProc("ReleaseCapture")
+	_RegisterClassExW              = user32.NewProc("RegisterClassExW")
+	_RegisterClipboardFormat       = user32.NewProc("RegisterClipboardFormatW")
+	_ReleaseDC                     = user32.NewProc("ReleaseDC")
+	_ScreenToClient                = user32.NewProc("ScreenToClient")
+	_ClientToScreen                = user32.NewProc("ClientToScreen")
+	_ClipCursor                    = user32.NewProc("ClipCursor")
+	_GetCursorPos                  = user32.NewProc("GetCursorPos")
+	_GetDoubleClickTime            = user32.NewProc("GetDoubleClickTime")
+	_SetCursorPos                  = user32.NewProc("SetCursorPos")
+	_ShowWindow                    = user32.NewProc("ShowWindow")
+	_SetCapture                    = user32.NewProc("SetCapture")
+	_SetCursor                     = user32.NewProc("SetCursor")
+	_SetClipboardData              = user32.NewProc("SetClipboardData")
+	_SetForegroundWindow           = user32.NewProc("SetForegroundWindow")
+	_SetFocus                      = user32.NewProc("SetFocus")
+	_SetProcessDPIAware            = user32.NewProc("SetProcessDPIAware")
+	_SetTimer                      = user32.NewProc("SetTimer")
+	_SetWindowLong                 = user32.NewProc("SetWindowLongPtrW")
+	_SetWindowCompositionAttribute = user32.NewProc("SetWindowCompositionAttribute")
+	_SetWindowLong32               = user32.NewProc("SetWindowLongW")
+	_SetWindowPlacement            = user32.NewProc("SetWindowPlacement")
+	_SetWindowPos                  = user32.NewProc("SetWindowPos")
+	_SetWindowRgn                  = user32.NewProc("SetWindowRgn")
+	_SetWindowText                 = user32.NewProc("SetWindowTextW")
+	_TranslateMessage              = user32.NewProc("TranslateMessage")
+	_UnregisterClass               = user32.NewProc("UnregisterClassW")
+	_UpdateWindow                  = user32.NewProc("UpdateWindow")
 
 	shcore            = syscall.NewLazySystemDLL("shcore")
 	_GetDpiForMonitor = shcore.NewProc("GetDpiForMonitor")
 
-	gdi32          = syscall.NewLazySystemDLL("gdi32")
-	_GetDeviceCaps = gdi32.NewProc("GetDeviceCaps")
+	gdi32             = syscall.NewLazySystemDLL("gdi32")
+	_GetDeviceCaps    = gdi32.NewProc("GetDeviceCaps")
+	_CreateSolidBrush = gdi32.NewProc("CreateSolidBrush")
+	_DeleteObject     = gdi32.NewProc("DeleteObject")
+	_CreateBitmap     = gdi32.NewProc("CreateBitmap")
+	_CreateRectRgn    = gdi32.NewProc("CreateRectRgn")
+	_CombineRgn       = gdi32.NewProc("CombineRgn")
 
 	imm32                    = syscall.NewLazySystemDLL("imm32")
 	_ImmGetContext           = imm32.NewProc("ImmGetContext")
@@ -380,9 +505,15 @@ var (
 	_ImmSetCandidateWindow   = imm32.NewProc("ImmSetCandidateWindow")
 	_ImmSetCompositionWindow = imm32.NewProc("ImmSetCompositionWindow")
 
+	dwmapi                        = syscall.NewLazySystemDLL("dwmapi")
+	_DwmExtendFrameIntoClientArea = dwmapi.NewProc("DwmExtendFrameIntoClientArea")
+	_DwmSetWindowAttribute        = dwmapi.NewProc("DwmSetWindowAttribute")
+
 	shell32        = syscall.NewLazySystemDLL("shell32")
 	_DragQueryFile = shell32.NewProc("DragQueryFileW")
 	_DragFinish    = shell32.NewProc("DragFinish")
+
+	_SetCurrentProcessExplicitAppUserModelID = shell32.NewProc("SetCurrentProcessExplicitAppUserModelID")
 )
 
 func AdjustWindowRectEx(r *Rect, dwStyle uint32, bMenu int, dwExStyle uint32) {
@@ -442,6 +573,13 @@ func EmptyClipboard() error {
 	return nil
 }
 
+// EnumClipboardFormats returns the clipboard format following format,
+// or 0 when there are no more formats. Start the enumeration with 0.
+func EnumClipboardFormats(format uint32) uint32 {
+	r, _, _ := _EnumClipboardFormats.Call(uintptr(format))
+	return uint32(r)
+}
+
 func GetWindowRect(hwnd syscall.Handle) Rect {
 	var r Rect
 	_GetWindowRect.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&r)))
@@ -456,6 +594,14 @@ func GetClipboardData(format uint32) (syscall.Handle, error) {
 	return syscall.Handle(r), nil
 }
 
+// GetClipboardFormatName returns the name of a registered clipboard
+// format, or the empty string for predefined formats.
+func GetClipboardFormatName(format uint32) string {
+	var buf [256]uint16
+	r, _, _ := _GetClipboardFormatName.Call(uintptr(format), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return syscall.UTF16ToString(buf[:r])
+}
+
 func GetDC(hwnd syscall.Handle) (syscall.Handle, error) {
 	hdc, _, err := _GetDC.Call(uintptr(hwnd))
 	if hdc == 0 {
@@ -464,6 +610,18 @@ func GetDC(hwnd syscall.Handle) (syscall.Handle, error) {
 	return syscall.Handle(hdc), nil
 }
 
+// GetUserDefaultLocaleName returns the name of the user's locale, such
+// as "en-US", or the empty string if unknown.
+func GetUserDefaultLocaleName() string {
+	const LOCALE_NAME_MAX_LENGTH = 85
+	var buf [LOCALE_NAME_MAX_LENGTH]uint16
+	n, _, _ := _GetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:])
+}
+
 func GetModuleHandle() (syscall.Handle, error) {
 	h, _, err := _GetModuleHandleW.Call(uintptr(0))
 	if h == 0 {
@@ -472,6 +630,17 @@ func GetModuleHandle() (syscall.Handle, error) {
 	return syscall.Handle(h), nil
 }
 
+// GetRefreshRate returns the vertical refresh rate of the display of
+// hdc in Hz, or zero if unknown.
+func GetRefreshRate(hdc syscall.Handle) int {
+	hz := getDeviceCaps(hdc, VREFRESH)
+	if hz <= 1 {
+		// 0 and 1 denote the hardware default.
+		return 0
+	}
+	return hz
+}
+
 func getDeviceCaps(hdc syscall.Handle, index int32) int {
 	c, _, _ := _GetDeviceCaps.Call(uintptr(hdc), uintptr(index))
 	return int(c)
@@ -518,12 +687,34 @@ func GetMessageTime() time.Duration {
 	return time.Duration(r) * time.Millisecond
 }
 
+// GetDoubleClickTime returns the double click time in milliseconds.
+func GetDoubleClickTime() uint32 {
+	r, _, _ := _GetDoubleClickTime.Call()
+	return uint32(r)
+}
+
 func GetSystemMetrics(nIndex int) int {
 	r, _, _ := _GetSystemMetrics.Call(uintptr(nIndex))
 	return int(r)
 }
 
 // GetWindowDPI returns the effective DPI of the window.
+// GetSysColor returns a system color encoded as 0x00bbggrr.
+func GetSysColor(index int) uint32 {
+	c, _, _ := _GetSysColor.Call(uintptr(index))
+	return uint32(c)
+}
+
+// GetHighContrast returns the high contrast mode of the system.
+func GetHighContrast() (HighContrast, error) {
+	hc := HighContrast{size: uint32(unsafe.Sizeof(HighContrast{}))}
+	r, _, err := _SystemParametersInfo.Call(SPI_GETHIGHCONTRAST, uintptr(hc.size), uintptr(unsafe.Pointer(&hc)), 0)
+	if r == 0 {
+		return HighContrast{}, fmt.Errorf("SystemParametersInfoW failed: %v", err)
+	}
+	return hc, nil
+}
+
 func GetWindowDPI(hwnd syscall.Handle) int {
 	// Check for GetDpiForWindow, introduced in Windows 10.
 	if _GetDpiForWindow.Find() == nil {
@@ -541,6 +732,69 @@ func GetWindowPlacement(hwnd syscall.Handle) *WindowPlacement {
 	return &wp
 }
 
+func DwmExtendFrameIntoClientArea(hwnd syscall.Handle, m Margins) error {
+	if err := _DwmExtendFrameIntoClientArea.Find(); err != nil {
+		return err
+	}
+	r, _, _ := _DwmExtendFrameIntoClientArea.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&m)))
+	if r != 0 {
+		return fmt.Errorf("DwmExtendFrameIntoClientArea: %#x", r)
+	}
+	return nil
+}
+
+// SetWindowAccent sets the accent effect of the window, such as
+// ACCENT_ENABLE_BLURBEHIND, with the undocumented
+// SetWindowCompositionAttribute.
+func SetWindowAccent(hwnd syscall.Handle, state uint32) error {
+	if err := _SetWindowCompositionAttribute.Find(); err != nil {
+		return err
+	}
+	policy := accentPolicy{AccentState: state}
+	data := windowCompositionAttribData{
+		Attrib: WCA_ACCENT_POLICY,
+		Data:   uintptr(unsafe.Pointer(&policy)),
+		Size:   unsafe.Sizeof(policy),
+	}
+	r, _, err := _SetWindowCompositionAttribute.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&data)))
+	if r == 0 {
+		return fmt.Errorf("SetWindowCompositionAttribute: %v", err)
+	}
+	return nil
+}
+
+func DwmSetWindowAttribute(hwnd syscall.Handle, attr uint32, value uint32) error {
+	if err := _DwmSetWindowAttribute.Find(); err != nil {
+		return err
+	}
+	r, _, _ := _DwmSetWindowAttribute.Call(uintptr(hwnd), uintptr(attr), uintptr(unsafe.Pointer(&value)), unsafe.Sizeof(value))
+	if r != 0 {
+		return fmt.Errorf("DwmSetWindowAttribute: %#x", r)
+	}
+	return nil
+}
+
+// lParamPointer returns the pointer passed in the lParam of a window
+// message. The memory is owned by the system and valid for the duration
+// of the message.
+func lParamPointer(lParam uintptr) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&lParam))
+}
+
+// SizingRect returns the window rectangle passed with WM_SIZING.
+func SizingRect(lParam uintptr) *Rect {
+	return (*Rect)(lParamPointer(lParam))
+}
+
+// SettingName returns the name of the setting passed with
+// WM_SETTINGCHANGE, or the empty string if there is none.
+func SettingName(lParam uintptr) string {
+	if lParam == 0 {
+		return ""
+	}
+	return syscall.UTF16PtrToString((*uint16)(lParamPointer(lParam)))
+}
+
 func GetMonitorInfo(hwnd syscall.Handle) MonitorInfo {
 	var mi MonitorInfo
 	mi.cbSize = uint32(unsafe.Sizeof(mi))
@@ -651,12 +905,17 @@ func GlobalLock(h syscall.Handle) (unsafe.Pointer, error) {
 	return unsafe.Pointer(r), nil
 }
 
+func GlobalSize(h syscall.Handle) int {
+	r, _, _ := _GlobalSize.Call(uintptr(h))
+	return int(r)
+}
+
 func GlobalUnlock(h syscall.Handle) {
 	_GlobalUnlock.Call(uintptr(h))
 }
 
 func KillTimer(hwnd syscall.Handle, nIDEvent uintptr) error {
-	r, _, err := _SetTimer.Call(uintptr(hwnd), uintptr(nIDEvent), 0, 0)
+	r, _, err := _KillTimer.Call(uintptr(hwnd), uintptr(nIDEvent))
 	if r == 0 {
 		return fmt.Errorf("KillTimer failed: %v", err)
 	}
@@ -739,6 +998,15 @@ func RegisterClassEx(cls *WndClassEx) (uint16, error) {
 	return uint16(a), nil
 }
 
+func RegisterClipboardFormat(name string) (uint32, error) {
+	wname := syscall.StringToUTF16Ptr(name)
+	r, _, err := _RegisterClipboardFormat.Call(uintptr(unsafe.Pointer(wname)))
+	if r == 0 {
+		return 0, fmt.Errorf("RegisterClipboardFormat: %v", err)
+	}
+	return uint32(r), nil
+}
+
 func ReleaseDC(hdc syscall.Handle) {
 	_ReleaseDC.Call(uintptr(hdc))
 }
@@ -784,6 +1052,78 @@ func ScreenToClient(hwnd syscall.Handle, p *Point) {
 	_ScreenToClient.Call(uintptr(hwnd), uintptr(unsafe.Pointer(p)))
 }
 
+func ClientToScreen(hwnd syscall.Handle, p *Point) {
+	_ClientToScreen.Call(uintptr(hwnd), uintptr(unsafe.Pointer(p)))
+}
+
+func ClipCursor(r *Rect) {
+	_ClipCursor.Call(uintptr(unsafe.Pointer(r)))
+}
+
+func GetCursorPos() Point {
+	var p Point
+	_GetCursorPos.Call(uintptr(unsafe.Pointer(&p)))
+	return p
+}
+
+func SetCursorPos(x, y int32) {
+	_SetCursorPos.Call(uintptr(x), uintptr(y))
+}
+
+// CreateSolidBrush creates a brush with the color encoded as 0x00bbggrr.
+func CreateSolidBrush(color uint32) syscall.Handle {
+	h, _, _ := _CreateSolidBrush.Call(uintptr(color))
+	return syscall.Handle(h)
+}
+
+// CreateBitmap creates a bitmap from bits, with rows aligned to 16 bits.
+func CreateBitmap(width, height int32, planes, bitsPerPixel uint32, bits []byte) (syscall.Handle, error) {
+	h, _, err := _CreateBitmap.Call(uintptr(width), uintptr(height), uintptr(planes), uintptr(bitsPerPixel), uintptr(unsafe.Pointer(&bits[0])))
+	if h == 0 {
+		return 0, fmt.Errorf("CreateBitmap failed: %v", err)
+	}
+	return syscall.Handle(h), nil
+}
+
+func CreateIconIndirect(info *IconInfo) (syscall.Handle, error) {
+	h, _, err := _CreateIconIndirect.Call(uintptr(unsafe.Pointer(info)))
+	if h == 0 {
+		return 0, fmt.Errorf("CreateIconIndirect failed: %v", err)
+	}
+	return syscall.Handle(h), nil
+}
+
+func DestroyIcon(h syscall.Handle) {
+	_DestroyIcon.Call(uintptr(h))
+}
+
+func CreateRectRgn(left, top, right, bottom int32) syscall.Handle {
+	h, _, _ := _CreateRectRgn.Call(uintptr(left), uintptr(top), uintptr(right), uintptr(bottom))
+	return syscall.Handle(h)
+}
+
+func CombineRgn(dst, src1, src2 syscall.Handle, mode int32) {
+	_CombineRgn.Call(uintptr(dst), uintptr(src1), uintptr(src2), uintptr(mode))
+}
+
+// SetWindowRgn sets the window region. The system owns rgn after the
+// call. A zero rgn removes the region.
+func SetWindowRgn(hwnd, rgn syscall.Handle, redraw bool) {
+	var paint uintptr
+	if redraw {
+		paint = TRUE
+	}
+	_SetWindowRgn.Call(uintptr(hwnd), uintptr(rgn), paint)
+}
+
+func DeleteObject(obj syscall.Handle) {
+	_DeleteObject.Call(uintptr(obj))
+}
+
+func FillRect(hdc syscall.Handle, r *Rect, brush syscall.Handle) {
+	_FillRect.Call(uintptr(hdc), uintptr(unsafe.Pointer(r)), uintptr(brush))
+}
+
 func ShowWindow(hwnd syscall.Handle, nCmdShow int32) {
 	_ShowWindow.Call(uintptr(hwnd), uintptr(nCmdShow))
 }
@@ -851,3 +1191,11 @@ func DragQueryFile_GetFileCount(hDrop uintptr) (uint, error) {
 func DragFinish(hDrop uintptr) {
 	_DragFinish.Call(hDrop)
 }
+
+func SetCurrentProcessExplicitAppUserModelID(id string) error {
+	r, _, _ := _SetCurrentProcessExplicitAppUserModelID.Call(uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(id))))
+	if r != 0 {
+		return fmt.Errorf("SetCurrentProcessExplicitAppUserModelID failed: %#x", r)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"os"
+	"strings"
+
+	"gioui.org/io/system"
+)
+
+// rtlLanguages are the primary language subtags of the languages written
+// right to left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "ckb": true, "dv": true, "fa": true, "he": true, "iw": true,
+	"ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// systemLocale returns the locale of the system language.
+func systemLocale() system.Locale {
+	return localeFor(systemLanguage())
+}
+
+// localeFor returns the locale of a BCP-47 or POSIX language tag.
+func localeFor(lang string) system.Locale {
+	// Convert POSIX tags such as "ar_EG.UTF-8".
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ReplaceAll(lang, "_", "-")
+	l := system.Locale{Language: lang, Direction: system.LTR}
+	primary := strings.ToLower(strings.SplitN(lang, "-", 2)[0])
+	if rtlLanguages[primary] {
+		l.Direction = system.RTL
+	}
+	return l
+}
+
+// envLanguage returns the language set by the POSIX locale environment
+// variables.
+func envLanguage() string {
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := os.Getenv(v); lang != "" && lang != "C" && lang != "POSIX" {
+			return lang
+		}
+	}
+	return ""
+}
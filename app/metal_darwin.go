@@ -27,13 +27,21 @@ static CFTypeRef createMetalDevice(void) {
 	}
 }
 
-static void setupLayer(CFTypeRef layerRef, CFTypeRef devRef) {
+static void setupLayer(CFTypeRef layerRef, CFTypeRef devRef, MTLPixelFormat format) {
 	@autoreleasepool {
 		CAMetalLayer *layer = (__bridge CAMetalLayer *)layerRef;
 		id<MTLDevice> dev = (__bridge id<MTLDevice>)devRef;
 		layer.device = dev;
-		// Package gpu assumes an sRGB-encoded framebuffer.
-		layer.pixelFormat = MTLPixelFormatBGRA8Unorm_sRGB;
+		layer.pixelFormat = format;
+		if (@available(iOS 13.0, *)) {
+			CGColorSpaceRef space = NULL;
+			if (format == MTLPixelFormatRGBA16Float) {
+				// Let the compositor convert the linear colors.
+				space = CGColorSpaceCreateWithName(kCGColorSpaceExtendedLinearSRGB);
+			}
+			layer.colorspace = space;
+			CGColorSpaceRelease(space);
+		}
 		if (@available(iOS 11.0, *)) {
 			// Never let nextDrawable time out and return nil.
 			layer.allowsNextDrawableTimeout = NO;
@@ -41,6 +49,11 @@ static void setupLayer(CFTypeRef layerRef, CFTypeRef devRef) {
 	}
 }
 
+static NSUInteger maximumDrawableCount(CFTypeRef layerRef) {
+	CAMetalLayer *layer = (__bridge CAMetalLayer *)layerRef;
+	return layer.maximumDrawableCount;
+}
+
 static CFTypeRef nextDrawable(CFTypeRef layerRef) {
 	@autoreleasepool {
 		CAMetalLayer *layer = (__bridge CAMetalLayer *)layerRef;
@@ -81,6 +94,8 @@ type mtlContext struct {
 	queue    C.CFTypeRef
 	drawable C.CFTypeRef
 	texture  C.CFTypeRef
+	// format is the pixel format of the layer.
+	format C.MTLPixelFormat
 }
 
 func newMtlContext(w *window) (*mtlContext, error) {
@@ -100,12 +115,19 @@ func newMtlContext(w *window) (*mtlContext, error) {
 		C.CFRelease(layer)
 		return nil, errors.New("metal: [MTLDevice newCommandQueue] failed")
 	}
-	C.setupLayer(layer, dev)
+	// Package gpu assumes an sRGB-encoded framebuffer unless the
+	// framebuffer holds linear colors.
+	format := C.MTLPixelFormat(C.MTLPixelFormatBGRA8Unorm_sRGB)
+	if w.w.ColorSpace() == ColorSpaceLinear {
+		format = C.MTLPixelFormatRGBA16Float
+	}
+	C.setupLayer(layer, dev, format)
 	c := &mtlContext{
-		dev:   dev,
-		view:  view,
-		layer: layer,
-		queue: queue,
+		dev:    dev,
+		view:   view,
+		layer:  layer,
+		queue:  queue,
+		format: format,
 	}
 	return c, nil
 }
@@ -127,11 +149,26 @@ func (c *mtlContext) RenderTarget() (gpu.RenderTarget, error) {
 	}, nil
 }
 
+func (c *mtlContext) swapchainImages() int {
+	return int(C.maximumDrawableCount(c.layer))
+}
+
+func (c *mtlContext) colorSpace() ColorSpace {
+	if c.format == C.MTLPixelFormatRGBA16Float {
+		return ColorSpaceLinear
+	}
+	return ColorSpaceSRGB
+}
+
+func (c *mtlContext) hdr() bool {
+	return edrHeadroom(c.view) > 1
+}
+
 func (c *mtlContext) API() gpu.API {
 	return gpu.Metal{
 		Device:      uintptr(c.dev),
 		Queue:       uintptr(c.queue),
-		PixelFormat: int(C.MTLPixelFormatBGRA8Unorm_sRGB),
+		PixelFormat: int(c.format),
 	}
 }
 
@@ -36,6 +36,20 @@ static void resizeDrawable(CFTypeRef viewRef, CFTypeRef layerRef) {
 		layer.drawableSize = size;
 	}
 }
+
+static CGFloat edrHeadroom(CFTypeRef viewRef) {
+	@autoreleasepool {
+		UIView *view = (__bridge UIView *)viewRef;
+		UIScreen *screen = view.window.screen;
+		if (screen == nil) {
+			screen = [UIScreen mainScreen];
+		}
+		if (@available(iOS 16.0, *)) {
+			return screen.potentialEDRHeadroom;
+		}
+		return 1.0;
+	}
+}
 */
 import "C"
 
@@ -46,3 +60,9 @@ func getMetalLayer(view C.CFTypeRef) C.CFTypeRef {
 func resizeDrawable(view, layer C.CFTypeRef) {
 	C.resizeDrawable(view, layer)
 }
+
+// edrHeadroom returns the maximum color component value the display of
+// view can show, 1 for displays without extended dynamic range.
+func edrHeadroom(view C.CFTypeRef) float32 {
+	return float32(C.edrHeadroom(view))
+}
@@ -35,6 +35,20 @@ static void resizeDrawable(CFTypeRef viewRef, CFTypeRef layerRef) {
 		layer.drawableSize = size;
 	}
 }
+
+static CGFloat edrHeadroom(CFTypeRef viewRef) {
+	@autoreleasepool {
+		NSView *view = (__bridge NSView *)viewRef;
+		NSScreen *screen = view.window.screen;
+		if (screen == nil) {
+			screen = [NSScreen mainScreen];
+		}
+		if (@available(macOS 10.15, *)) {
+			return screen.maximumPotentialExtendedDynamicRangeColorComponentValue;
+		}
+		return 1.0;
+	}
+}
 */
 import "C"
 
@@ -45,3 +59,9 @@ func getMetalLayer(view C.CFTypeRef) C.CFTypeRef {
 func resizeDrawable(view, layer C.CFTypeRef) {
 	C.resizeDrawable(view, layer)
 }
+
+// edrHeadroom returns the maximum color component value the display of
+// view can show, 1 for displays without extended dynamic range.
+func edrHeadroom(view C.CFTypeRef) float32 {
+	return float32(C.edrHeadroom(view))
+}
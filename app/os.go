@@ -6,9 +6,14 @@ import (
 	"errors"
 	"image"
 	"image/color"
+	"math"
+	"sort"
+	"time"
 
+	"gioui.org/io/clipboard"
 	"gioui.org/io/key"
 
+	"gioui.org/f32"
 	"gioui.org/gpu"
 	"gioui.org/io/pointer"
 	"gioui.org/io/system"
@@ -43,16 +48,441 @@ type Config struct {
 	CustomRenderer bool
 	// Decorated reports whether window decorations are provided automatically.
 	Decorated bool
+	// Hidden reports whether the window is hidden from view.
+	Hidden bool
+	// MinimizeToTray reports whether minimizing the window hides it
+	// instead.
+	MinimizeToTray bool
+	// SkipTaskbar reports whether the window is left out of the
+	// taskbar and window switcher.
+	SkipTaskbar bool
+	// AcceptFirstMouse reports whether the click that activates the
+	// window is delivered as a pointer event.
+	AcceptFirstMouse bool
+	// DocumentEdited reports whether the window has unsaved changes.
+	DocumentEdited bool
+	// PresentMode is the requested present mode. See Caps.PresentMode
+	// for the mode in effect.
+	PresentMode PresentMode
+	// ColorSpace is the requested color space of the window
+	// framebuffer. See Caps.ColorSpace for the color space in use.
+	ColorSpace ColorSpace
+	// Material is the background material behind the window content.
+	Material Material
+	// Shadow reports whether an undecorated window has a native drop
+	// shadow.
+	Shadow bool
+	// SplashColor is the color the window is filled with before its
+	// first frame, or transparent for none.
+	SplashColor color.NRGBA
+	// Resizable reports whether the user can resize the window.
+	Resizable bool
+	// AspectRatio is the width to height ratio of interactive resizes,
+	// or zero for no constraint.
+	AspectRatio image.Point
+	// ResizeIncrements is the step size in pixels of interactive
+	// resizes, or zero for no constraint.
+	ResizeIncrements image.Point
+	// GLVersion is the requested OpenGL version. The zero value
+	// selects the default version.
+	GLVersion GLContextVersion
+	// ForcedMetric is the metric that replaces the metric of the
+	// platform, or the zero value for none.
+	ForcedMetric unit.Metric
+	// serveOnCaller defers the event loop to Window.ServeOn. See
+	// ServeOnCaller.
+	serveOnCaller bool
 	// decoHeight is the height of the fallback decoration for platforms such
 	// as Wayland that may need fallback client-side decorations.
 	decoHeight unit.Dp
 }
 
+// GLContextVersion is an OpenGL or OpenGL ES version.
+type GLContextVersion struct {
+	Major, Minor int
+	// ES selects OpenGL ES instead of desktop OpenGL.
+	ES bool
+}
+
 // ConfigEvent is sent whenever the configuration of a Window changes.
 type ConfigEvent struct {
 	Config Config
 }
 
+// TickEvent is sent periodically at the interval set by
+// Window.SetBackgroundTick, regardless of the window stage.
+type TickEvent struct {
+	// Now is the time of the tick.
+	Now time.Time
+}
+
+// IdleEvent is sent when the window has received no input for the
+// duration set by Window.SetIdleTimeout.
+type IdleEvent struct{}
+
+// ActiveEvent is sent when the window receives input after an
+// IdleEvent.
+type ActiveEvent struct{}
+
+// SaveStateEvent is sent when the platform may terminate the program
+// soon, such as when a mobile app moves to the background where it may
+// be evicted, or when the user session ends. The program should persist
+// its state before receiving the next event, because the process may be
+// killed without further notice. The window delays processing of other
+// events until then.
+//
+// SaveStateEvent is sent on Android and iOS when the app is stopped, on
+// macOS when the app terminates, on Windows when the user session ends,
+// and in browsers when the page is hidden.
+type SaveStateEvent struct{}
+
+// LocaleEvent is sent when the system language changes. LocaleEvent is
+// supported on Windows and in browsers.
+type LocaleEvent struct {
+	// Locale is the new locale of the window. Its direction is the
+	// direction set by Window.SetLayoutDirection, if any.
+	Locale system.Locale
+}
+
+// ForcedColors describes a high contrast mode, where the system restricts
+// programs to a set of colors chosen by the user for accessibility. The
+// colors are named after the corresponding CSS system colors.
+type ForcedColors struct {
+	// Enabled reports whether the mode is in effect. The colors are only
+	// valid when Enabled is true.
+	Enabled bool
+	// Canvas is the background color of content, and CanvasText the
+	// color of text on Canvas.
+	Canvas, CanvasText color.NRGBA
+	// LinkText is the color of links.
+	LinkText color.NRGBA
+	// GrayText is the color of disabled text.
+	GrayText color.NRGBA
+	// Highlight is the background color of selected content, and
+	// HighlightText the color of selected text.
+	Highlight, HighlightText color.NRGBA
+	// ButtonFace is the background color of buttons, and ButtonText
+	// the color of text on ButtonFace.
+	ButtonFace, ButtonText color.NRGBA
+}
+
+// ForcedColorsEvent is sent when the high contrast mode of the system
+// or its colors change. ForcedColorsEvent is supported on Windows and
+// in browsers.
+type ForcedColorsEvent struct {
+	ForcedColors ForcedColors
+}
+
+// Insets describes the parts of the window obscured by the system, in
+// pixels. See Window.Insets.
+type Insets struct {
+	// SystemTop, SystemBottom, SystemLeft and SystemRight are the
+	// widths of the system bars and controls covering the edges of the
+	// window, such as the status and navigation bars of mobile
+	// platforms.
+	SystemTop, SystemBottom, SystemLeft, SystemRight int
+	// Keyboard is the height of the on-screen keyboard covering the
+	// bottom of the window.
+	Keyboard int
+	// SafeArea is the part of the window not obscured by the system
+	// bars, controls or the on-screen keyboard.
+	SafeArea image.Rectangle
+}
+
+// InsetsEvent is sent when the insets of the window change.
+type InsetsEvent struct {
+	Insets Insets
+}
+
+// CloseRequestEvent is sent instead of closing the window when the user
+// closes a window whose document is edited; see DocumentEdited. Close
+// the window with Perform(system.ActionClose) once the document is
+// saved or its changes are discarded.
+type CloseRequestEvent struct{}
+
+// VisibilityEvent is sent when the fraction of the window visible on
+// the screen changes, such as when other windows cover it, for
+// reducing the rendering detail of windows that are mostly hidden.
+//
+// VisibilityEvent is supported on macOS, X11 and in browsers. On macOS,
+// the window is reported either as fully visible or as hidden; X11
+// additionally reports partly covered windows as half visible.
+type VisibilityEvent struct {
+	// Visible is the approximate visible fraction of the window, from
+	// 0 for hidden to 1 for fully visible.
+	Visible float32
+}
+
+// GestureKind is the kind of a GestureEvent.
+type GestureKind uint8
+
+const (
+	// GestureSwipeLeft, GestureSwipeRight, GestureSwipeUp and
+	// GestureSwipeDown are multi-finger swipes, such as for navigating
+	// back and forward.
+	GestureSwipeLeft GestureKind = iota
+	GestureSwipeRight
+	GestureSwipeUp
+	GestureSwipeDown
+	// GestureRotate is a two finger rotation.
+	GestureRotate
+	// GestureSmartZoom is a two finger double tap, for zooming in on
+	// or out of the content under the pointer.
+	GestureSmartZoom
+)
+
+// GestureEvent is a trackpad gesture recognized by the system. Unlike
+// pointer events, gestures are not routed to handlers, and are sent
+// for the window as a whole.
+//
+// GestureEvent is supported on macOS.
+type GestureEvent struct {
+	Kind GestureKind
+	// Position is the pointer position in pixels.
+	Position f32.Point
+	// Angle is the clockwise rotation in radians of a GestureRotate
+	// since the previous event.
+	Angle float32
+	// Time is when the gesture occurred, relative to an undefined
+	// base.
+	Time time.Duration
+}
+
+// EventType identifies a type of event sent by a Window, for
+// Window.SetEventFilter.
+type EventType uint8
+
+const (
+	// EventFrame selects system.FrameEvent.
+	EventFrame EventType = iota
+	// EventConfig selects ConfigEvent.
+	EventConfig
+	// EventView selects ViewEvent.
+	EventView
+	// EventSaveState selects SaveStateEvent.
+	EventSaveState
+	// EventTick selects TickEvent.
+	EventTick
+	// EventInstance selects InstanceEvent.
+	EventInstance
+	// EventLocale selects LocaleEvent.
+	EventLocale
+	// EventForcedColors selects ForcedColorsEvent.
+	EventForcedColors
+	// EventIdle selects IdleEvent and ActiveEvent.
+	EventIdle
+	// EventDragResult selects DragResultEvent.
+	EventDragResult
+	// EventOpen selects OpenURLEvent and OpenFileEvent.
+	EventOpen
+	// EventInsets selects InsetsEvent.
+	EventInsets
+	// EventGesture selects GestureEvent.
+	EventGesture
+	// EventCloseRequest selects CloseRequestEvent.
+	EventCloseRequest
+	// EventVisibility selects VisibilityEvent.
+	EventVisibility
+	// EventClipboardData selects the clipboard.Event replies to
+	// Window.ReadClipboardData.
+	EventClipboardData
+)
+
+// FrameTimings is the breakdown of the time spent rendering a frame.
+type FrameTimings struct {
+	// Timings are the GPU rendering stages.
+	gpu.Timings
+	// Present is the time spent presenting the frame to the screen,
+	// including the time blocked waiting for the vertical blank. A
+	// Present time close to the frame budget means the program waits
+	// for the display, not the GPU.
+	Present time.Duration
+	// Alloc is the number of bytes allocated by the program during the
+	// frame, and GCs the number of garbage collections completed. They
+	// are only measured when enabled by Window.SetAllocProfiling.
+	Alloc uint64
+	GCs   uint32
+}
+
+// Caps describes the GPU context of a Window, as returned by
+// Window.Caps.
+type Caps struct {
+	// SwapchainImages is the number of images in the swap chain,
+	// including the image on screen; 2 for double buffering and 3 for
+	// triple buffering. It is zero if the window has no GPU context or
+	// the number is unknown, such as for OpenGL contexts.
+	SwapchainImages int
+	// HDR reports whether the display of the window has a high dynamic
+	// range. Gio draws in the standard dynamic range, so HDR is for
+	// programs that present HDR content by other means. HDR is only
+	// reported by Metal contexts, and is not updated when the window
+	// moves to another display until the context is refreshed.
+	HDR bool
+	// GLVersion is the OpenGL ES version the context of the window was
+	// created with, or zero for other contexts. See GLVersion.
+	GLVersion GLContextVersion
+	// DrawTo reports whether the GPU of the window supports
+	// Window.DrawTo and layers. It is false for GPUs that use the
+	// compute renderer.
+	DrawTo bool
+	// PresentMode is the present mode in effect, which differs from
+	// the requested mode when the context doesn't support it. It is
+	// zero if the window has no GPU context.
+	PresentMode PresentMode
+	// ColorSpace is the color space of the framebuffer in effect,
+	// which differs from the requested color space when the context
+	// doesn't support it, such as for the OpenGL fallback on macOS.
+	ColorSpace ColorSpace
+}
+
+// RedrawReason is the reason a frame was drawn, as reported by
+// Window.LastRedrawReason.
+type RedrawReason uint8
+
+const (
+	// RedrawSystem is a frame requested by the platform, such as after
+	// a resize or when the window becomes visible.
+	RedrawSystem RedrawReason = iota
+	// RedrawInput is a frame in response to input events handled by the
+	// program, or to focus changes.
+	RedrawInput
+	// RedrawAnimation is a frame requested by an op.InvalidateOp in the
+	// previous frame, or by Window.Animate.
+	RedrawAnimation
+	// RedrawProfiling is a frame requested to deliver profiling
+	// information.
+	RedrawProfiling
+	// RedrawExternal is a frame requested by Window.Invalidate or by
+	// changes to the window options and settings.
+	RedrawExternal
+)
+
+func (r RedrawReason) String() string {
+	switch r {
+	case RedrawSystem:
+		return "system"
+	case RedrawInput:
+		return "input"
+	case RedrawAnimation:
+		return "animation"
+	case RedrawProfiling:
+		return "profiling"
+	case RedrawExternal:
+		return "external"
+	}
+	return ""
+}
+
+// BenchmarkFrame is the timing of a frame drawn in benchmark mode. See
+// Window.SetBenchmark.
+type BenchmarkFrame struct {
+	// ID identifies the frame.
+	ID FrameID
+	// Frame is the duration from the start of the frame until it was
+	// presented, including the time spent by the program on the frame.
+	Frame time.Duration
+	// Draw is the time spent submitting the frame to the GPU.
+	Draw time.Duration
+}
+
+// FrameID identifies a frame presented by a Window. The IDs of
+// successive frames increase by one.
+type FrameID uint64
+
+// FrameHistogram is a list of frame durations, or of input latencies
+// as returned by Window.InputLatency.
+type FrameHistogram struct {
+	// Durations of the frames, oldest first.
+	Durations []time.Duration
+}
+
+// Percentile returns the smallest duration that is at least as long as
+// p percent of the frame durations, or zero if h is empty.
+func (h FrameHistogram) Percentile(p float64) time.Duration {
+	n := len(h.Durations)
+	if n == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, h.Durations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	idx := int(math.Ceil(p/100*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+	return sorted[idx]
+}
+
+// frameHistory is a ring buffer of the most recent frame durations.
+type frameHistory struct {
+	durs [frameHistorySize]time.Duration
+	// next is the index of the next entry to write.
+	next int
+	// n is the number of valid entries.
+	n int
+}
+
+// frameHistorySize is the number of frame durations kept
+// for FrameHistogram.
+const frameHistorySize = 240
+
+func (h *frameHistory) add(d time.Duration) {
+	h.durs[h.next] = d
+	h.next = (h.next + 1) % len(h.durs)
+	if h.n < len(h.durs) {
+		h.n++
+	}
+}
+
+func (h *frameHistory) histogram() FrameHistogram {
+	durs := make([]time.Duration, 0, h.n)
+	start := (h.next - h.n + len(h.durs)) % len(h.durs)
+	for i := 0; i < h.n; i++ {
+		durs = append(durs, h.durs[(start+i)%len(h.durs)])
+	}
+	return FrameHistogram{Durations: durs}
+}
+
+// DragResultEvent is sent when a drag started by Window.StartDrag
+// completes.
+type DragResultEvent struct {
+	Result DragResult
+}
+
+// DragResult is the outcome of a drag operation.
+type DragResult uint8
+
+const (
+	// DragCanceled means the drag was canceled or
+	// couldn't be started.
+	DragCanceled DragResult = iota
+	// DragCopied means the drop target copied the data.
+	DragCopied
+	// DragMoved means the drop target moved the data. The
+	// source is expected to delete its copy.
+	DragMoved
+)
+
+// ResizeEdge identifies the window edge or corner moved by
+// Window.BeginInteractiveResize.
+type ResizeEdge uint8
+
+const (
+	EdgeNorth ResizeEdge = iota
+	EdgeSouth
+	EdgeWest
+	EdgeEast
+	EdgeNorthWest
+	EdgeNorthEast
+	EdgeSouthWest
+	EdgeSouthEast
+)
+
 func (c *Config) apply(m unit.Metric, options []Option) {
 	for _, o := range options {
 		o(m, c)
@@ -131,10 +561,216 @@ func (o Orientation) String() string {
 	return ""
 }
 
+// PresentMode is the way frames are presented to the screen
+// (PresentMode.Option sets it). Modes that are not supported fall back
+// to PresentFIFO, and Caps.PresentMode reports the mode in effect.
+//
+// PresentMailbox is supported by Vulkan. PresentImmediate is supported
+// by Vulkan, Direct3D 11, and OpenGL on X11 and Windows.
+type PresentMode uint8
+
+const (
+	// PresentAuto selects the default mode of the platform.
+	PresentAuto PresentMode = iota
+	// PresentFIFO waits for the vertical blank before presenting
+	// a frame, limiting the frame rate to the display refresh rate.
+	PresentFIFO
+	// PresentMailbox presents at the vertical blank like PresentFIFO,
+	// but replaces waiting frames with newer ones, reducing latency
+	// without tearing.
+	PresentMailbox
+	// PresentImmediate presents frames without waiting, which may
+	// result in tearing.
+	PresentImmediate
+)
+
+// Option returns an Option that requests the present mode m.
+func (m PresentMode) Option() Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.PresentMode = m
+	}
+}
+
+// String returns the lower case name of the present mode.
+func (m PresentMode) String() string {
+	switch m {
+	case PresentAuto:
+		return "auto"
+	case PresentFIFO:
+		return "fifo"
+	case PresentMailbox:
+		return "mailbox"
+	case PresentImmediate:
+		return "immediate"
+	}
+	return ""
+}
+
+// Material is a translucent background effect displayed behind the
+// window content (Material.Option sets it). Where a material is in
+// effect, the window is cleared to transparent instead of white, so
+// the material shows through areas not painted by the program.
+//
+// Materials are supported on macOS and Windows 11; Windows 10 supports
+// only MaterialBlur. Elsewhere the window falls back to MaterialNone,
+// which Config reports.
+type Material uint8
+
+const (
+	// MaterialNone is an opaque background.
+	MaterialNone Material = iota
+	// MaterialBlur is a blurred view of the content behind the
+	// window.
+	MaterialBlur
+	// MaterialAcrylic is a blurred and tinted view of the content
+	// behind the window, for transient surfaces such as menus.
+	MaterialAcrylic
+	// MaterialMica is a tinted background derived from the desktop
+	// wallpaper, for long-lived windows.
+	MaterialMica
+)
+
+func (m Material) Option() Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Material = m
+	}
+}
+
+func (m Material) String() string {
+	switch m {
+	case MaterialNone:
+		return "none"
+	case MaterialBlur:
+		return "blur"
+	case MaterialAcrylic:
+		return "acrylic"
+	case MaterialMica:
+		return "mica"
+	}
+	return ""
+}
+
+// ColorSpace is the color space of the window framebuffer
+// (ColorSpace.Option sets it). Color spaces that are not supported
+// fall back to ColorSpaceSRGB, and Caps.ColorSpace reports the color
+// space in use. Config.ColorSpace is the requested color space.
+//
+// ColorSpaceLinear is supported by Metal on macOS and iOS.
+type ColorSpace uint8
+
+const (
+	// ColorSpaceSRGB is an 8-bit sRGB encoded framebuffer. Colors are
+	// blended in linear space and encoded when written.
+	ColorSpaceSRGB ColorSpace = iota
+	// ColorSpaceLinear is a 16-bit floating point framebuffer holding
+	// linear colors, which the system compositor converts for display.
+	// It avoids the loss of precision of the sRGB encoding, for
+	// example for compositing with other linear content.
+	ColorSpaceLinear
+)
+
+// Option returns an Option that requests the color space c.
+func (c ColorSpace) Option() Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.ColorSpace = c
+	}
+}
+
+func (k GestureKind) String() string {
+	switch k {
+	case GestureSwipeLeft:
+		return "SwipeLeft"
+	case GestureSwipeRight:
+		return "SwipeRight"
+	case GestureSwipeUp:
+		return "SwipeUp"
+	case GestureSwipeDown:
+		return "SwipeDown"
+	case GestureRotate:
+		return "Rotate"
+	case GestureSmartZoom:
+		return "SmartZoom"
+	}
+	return ""
+}
+
+// String returns the lower case name of the color space.
+func (c ColorSpace) String() string {
+	switch c {
+	case ColorSpaceSRGB:
+		return "srgb"
+	case ColorSpaceLinear:
+		return "linear"
+	}
+	return ""
+}
+
+// ProgressState is the state of the progress indicator displayed by
+// Window.SetTaskbarProgress.
+type ProgressState uint8
+
+const (
+	// ProgressNone hides the progress indicator.
+	ProgressNone ProgressState = iota
+	// ProgressNormal is the state of progressing operations.
+	ProgressNormal
+	// ProgressPaused is the state of paused operations.
+	ProgressPaused
+	// ProgressError is the state of failed operations.
+	ProgressError
+)
+
+func (p ProgressState) String() string {
+	switch p {
+	case ProgressNone:
+		return "none"
+	case ProgressNormal:
+		return "normal"
+	case ProgressPaused:
+		return "paused"
+	case ProgressError:
+		return "error"
+	}
+	return ""
+}
+
+// constrainAspect returns the largest size that fits within size and
+// has the aspect ratio, or size if the ratio is zero.
+func constrainAspect(size, ratio image.Point) image.Point {
+	if ratio.X <= 0 || ratio.Y <= 0 {
+		return size
+	}
+	if size.X*ratio.Y > size.Y*ratio.X {
+		size.X = size.Y * ratio.X / ratio.Y
+	} else {
+		size.Y = size.X * ratio.Y / ratio.X
+	}
+	return size
+}
+
+// constrainIncrements returns size rounded down to a multiple of the
+// increments, but no smaller than a single increment.
+func constrainIncrements(size, inc image.Point) image.Point {
+	if inc.X > 1 && size.X > inc.X {
+		size.X -= size.X % inc.X
+	}
+	if inc.Y > 1 && size.Y > inc.Y {
+		size.Y -= size.Y % inc.Y
+	}
+	return size
+}
+
 type frameEvent struct {
 	system.FrameEvent
 
+	// Sync is set when the GPU context must be refreshed before
+	// drawing, because the window surface may have changed, such as
+	// after a resize. A refresh synchronizes the context with the
+	// surface and waits for pending GPU work.
 	Sync bool
+	// Keyboard is the part of the bottom inset covered by the on-screen
+	// keyboard, in pixels.
+	Keyboard int
 }
 
 type context interface {
@@ -147,6 +783,36 @@ type context interface {
 	Unlock()
 }
 
+// swapchainContext is implemented by contexts that know the number of
+// images in their swap chain.
+type swapchainContext interface {
+	swapchainImages() int
+}
+
+// glesContext is implemented by OpenGL ES contexts that know their
+// version.
+type glesContext interface {
+	Version() (major, minor int)
+}
+
+// presentModeContext is implemented by contexts that can present
+// frames in other modes than PresentFIFO.
+type presentModeContext interface {
+	presentMode() PresentMode
+}
+
+// colorSpaceContext is implemented by contexts that support other
+// framebuffer color spaces than ColorSpaceSRGB.
+type colorSpaceContext interface {
+	colorSpace() ColorSpace
+}
+
+// hdrContext is implemented by contexts that know whether the display
+// supports high dynamic range.
+type hdrContext interface {
+	hdr() bool
+}
+
 // Driver is the interface for the platform implementation
 // of a window.
 type driver interface {
@@ -155,16 +821,49 @@ type driver interface {
 	SetAnimating(anim bool)
 	// ShowTextInput updates the virtual keyboard state.
 	ShowTextInput(show bool)
-	SetInputHint(mode key.InputHint)
+	SetInputHint(mode key.InputHint, opts key.InputOptions)
 	NewContext() (context, error)
 	// ReadClipboard requests the clipboard content.
-	ReadClipboard()
+	ReadClipboard(sel clipboard.Selection)
 	// WriteClipboard requests a clipboard write.
-	WriteClipboard(s string)
+	WriteClipboard(sel clipboard.Selection, s string)
+	// ReadClipboardData requests the clipboard content of a MIME type.
+	ReadClipboardData(mime string)
+	// WriteClipboardData requests a clipboard write of data of a MIME type.
+	WriteClipboardData(mime string, data []byte)
+	// StartDrag starts a drag of data offered in the MIME types.
+	StartDrag(data []byte, types []string)
+	// ProcessEvents processes the pending native events, or does
+	// nothing if the platform runs its own event loop.
+	ProcessEvents()
+	// SetProgress updates the taskbar progress indicator.
+	SetProgress(fraction float64, state ProgressState)
+	// StartMove starts an interactive move of the window.
+	StartMove()
+	// StartResize starts an interactive resize of the window edge.
+	StartResize(edge ResizeEdge)
 	// Configure the window.
 	Configure([]Option)
 	// SetCursor updates the current cursor to name.
 	SetCursor(cursor pointer.Cursor)
+	// SetCustomCursor replaces the cursor with img, or restores the
+	// cursor if img is nil. It reports false if images are not
+	// supported as cursors.
+	SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool
+	// SetCursorPos moves the cursor to pos in window coordinates.
+	SetCursorPos(pos image.Point)
+	// SetRelativeMouse enables or disables relative mouse mode.
+	SetRelativeMouse(enable bool)
+	// SetCursorConfined confines the cursor to the window while it
+	// has focus.
+	SetCursorConfined(confine bool)
+	// SetInputShape restricts pointer input to the union of the
+	// rectangles in window coordinates, or the whole window if shape
+	// is nil.
+	SetInputShape(shape []image.Rectangle)
+	// SetLayoutDirection hints the layout direction of the window
+	// content to the platform.
+	SetLayoutDirection(dir system.TextDirection)
 	// Wakeup wakes up the event loop and sends a WakeupEvent.
 	Wakeup()
 	// Perform actions on the window.
@@ -210,8 +909,22 @@ func newWindowRendezvous() *windowRendezvous {
 	return wr
 }
 
-func (wakeupEvent) ImplementsEvent() {}
-func (ConfigEvent) ImplementsEvent() {}
+func (wakeupEvent) ImplementsEvent()       {}
+func (ConfigEvent) ImplementsEvent()       {}
+func (TickEvent) ImplementsEvent()         {}
+func (DragResultEvent) ImplementsEvent()   {}
+func (InstanceEvent) ImplementsEvent()     {}
+func (OpenURLEvent) ImplementsEvent()      {}
+func (OpenFileEvent) ImplementsEvent()     {}
+func (IdleEvent) ImplementsEvent()         {}
+func (ActiveEvent) ImplementsEvent()       {}
+func (LocaleEvent) ImplementsEvent()       {}
+func (SaveStateEvent) ImplementsEvent()    {}
+func (ForcedColorsEvent) ImplementsEvent() {}
+func (InsetsEvent) ImplementsEvent()       {}
+func (GestureEvent) ImplementsEvent()      {}
+func (CloseRequestEvent) ImplementsEvent() {}
+func (VisibilityEvent) ImplementsEvent()   {}
 
 func walkActions(actions system.Action, do func(system.Action)) {
 	for a := system.Action(1); actions != 0; a <<= 1 {
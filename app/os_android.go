@@ -124,6 +124,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -138,6 +139,7 @@ import (
 
 	"gioui.org/f32"
 	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
 	"gioui.org/io/key"
 	"gioui.org/io/pointer"
 	"gioui.org/io/router"
@@ -176,6 +178,7 @@ var gioView struct {
 	once               sync.Once
 	getDensity         C.jmethodID
 	getFontScale       C.jmethodID
+	getRefreshRate     C.jmethodID
 	showTextInput      C.jmethodID
 	hideTextInput      C.jmethodID
 	setInputHint       C.jmethodID
@@ -197,6 +200,8 @@ var gioView struct {
 
 type pixelInsets struct {
 	top, bottom, left, right int
+	// ime is the part of bottom covered by the on-screen keyboard.
+	ime int
 }
 
 // ViewEvent is sent whenever the Window's underlying Android view
@@ -463,6 +468,7 @@ func Java_org_gioui_GioView_onCreateView(env *C.JNIEnv, class C.jclass, view C.j
 		m := &gioView
 		m.getDensity = getMethodID(env, class, "getDensity", "()I")
 		m.getFontScale = getMethodID(env, class, "getFontScale", "()F")
+		m.getRefreshRate = getMethodID(env, class, "getRefreshRate", "()F")
 		m.showTextInput = getMethodID(env, class, "showTextInput", "()V")
 		m.hideTextInput = getMethodID(env, class, "hideTextInput", "()V")
 		m.setInputHint = getMethodID(env, class, "setInputHint", "(I)V")
@@ -498,7 +504,7 @@ func Java_org_gioui_GioView_onCreateView(env *C.JNIEnv, class C.jclass, view C.j
 	w.callbacks.SetDriver(w)
 	w.loadConfig(env, class)
 	w.Configure(wopts.options)
-	w.SetInputHint(key.HintAny)
+	w.SetInputHint(key.HintAny, 0)
 	w.setStage(system.StagePaused)
 	w.callbacks.Event(ViewEvent{View: uintptr(view)})
 	return C.jlong(w.handle)
@@ -514,6 +520,8 @@ func Java_org_gioui_GioView_onDestroyView(env *C.JNIEnv, class C.jclass, handle
 func Java_org_gioui_GioView_onStopView(env *C.JNIEnv, class C.jclass, handle C.jlong) {
 	w := cgo.Handle(handle).Value().(*window)
 	w.started = false
+	// A stopped app may be killed without further notice.
+	w.callbacks.Event(SaveStateEvent{})
 	w.setStage(system.StagePaused)
 }
 
@@ -548,6 +556,15 @@ func Java_org_gioui_GioView_onLowMemory(env *C.JNIEnv, class C.jclass) {
 	debug.FreeOSMemory()
 }
 
+//export Java_org_gioui_GioView_onOpenURI
+func Java_org_gioui_GioView_onOpenURI(env *C.JNIEnv, class C.jclass, uri C.jstring) {
+	u, err := url.Parse(goString(env, uri))
+	if err != nil {
+		return
+	}
+	deliverOpens([]event.Event{openURLEvent(u)})
+}
+
 //export Java_org_gioui_GioView_onConfigurationChanged
 func Java_org_gioui_GioView_onConfigurationChanged(env *C.JNIEnv, class C.jclass, view C.jlong) {
 	w := cgo.Handle(view).Value().(*window)
@@ -588,13 +605,14 @@ func Java_org_gioui_GioView_onFocusChange(env *C.JNIEnv, class C.jclass, view C.
 }
 
 //export Java_org_gioui_GioView_onWindowInsets
-func Java_org_gioui_GioView_onWindowInsets(env *C.JNIEnv, class C.jclass, view C.jlong, top, right, bottom, left C.jint) {
+func Java_org_gioui_GioView_onWindowInsets(env *C.JNIEnv, class C.jclass, view C.jlong, top, right, bottom, left, ime C.jint) {
 	w := cgo.Handle(view).Value().(*window)
 	w.insets = pixelInsets{
 		top:    int(top),
 		bottom: int(bottom),
 		left:   int(left),
 		right:  int(right),
+		ime:    int(ime),
 	}
 	if w.stage >= system.StageInactive {
 		w.draw(env, true)
@@ -803,6 +821,7 @@ func (w *window) nativeWindow() (*C.ANativeWindow, int, int) {
 func (w *window) loadConfig(env *C.JNIEnv, class C.jclass) {
 	dpi := int(C.jni_CallIntMethod(env, w.view, gioView.getDensity))
 	w.fontScale = float32(C.jni_CallFloatMethod(env, w.view, gioView.getFontScale))
+	w.callbacks.SetRefreshRate(float64(C.jni_CallFloatMethod(env, w.view, gioView.getRefreshRate)))
 	switch dpi {
 	case C.ACONFIGURATION_DENSITY_NONE,
 		C.ACONFIGURATION_DENSITY_DEFAULT,
@@ -841,6 +860,12 @@ func (w *window) draw(env *C.JNIEnv, sync bool) {
 		Left:   unit.Dp(w.insets.left) * dppp,
 		Right:  unit.Dp(w.insets.right) * dppp,
 	}
+	// The keyboard covers the view only if it is part of the insets,
+	// not when the view is resized for it.
+	keyboard := w.insets.ime
+	if keyboard > w.insets.bottom {
+		keyboard = w.insets.bottom
+	}
 	w.callbacks.Event(frameEvent{
 		FrameEvent: system.FrameEvent{
 			Now:    time.Now(),
@@ -851,7 +876,8 @@ func (w *window) draw(env *C.JNIEnv, sync bool) {
 				PxPerSp: w.fontScale * ppdp,
 			},
 		},
-		Sync: sync,
+		Sync:     sync,
+		Keyboard: keyboard,
 	})
 	a11yActive, err := callBooleanMethod(env, w.view, gioView.isA11yActive)
 	if err != nil {
@@ -1112,7 +1138,7 @@ func Java_org_gioui_GioView_imeToUTF16(env *C.JNIEnv, class C.jclass, handle C.j
 
 func (w *window) EditorStateChanged(old, new editorState) {
 	runInJVM(javaVM(), func(env *C.JNIEnv) {
-		if old.Snippet != new.Snippet {
+		if old.target != new.target || old.Snippet != new.Snippet {
 			callVoidMethod(env, w.view, gioView.restartInput)
 			return
 		}
@@ -1142,7 +1168,7 @@ func (w *window) ShowTextInput(show bool) {
 	})
 }
 
-func (w *window) SetInputHint(mode key.InputHint) {
+func (w *window) SetInputHint(mode key.InputHint, opts key.InputOptions) {
 	// Constants defined at https://developer.android.com/reference/android/text/InputType.
 	const (
 		TYPE_NULL = 0
@@ -1150,8 +1176,13 @@ func (w *window) SetInputHint(mode key.InputHint) {
 		TYPE_CLASS_TEXT                   = 1
 		TYPE_TEXT_VARIATION_EMAIL_ADDRESS = 32
 		TYPE_TEXT_VARIATION_URI           = 16
+		TYPE_TEXT_FLAG_CAP_CHARACTERS     = 4096
+		TYPE_TEXT_FLAG_CAP_WORDS          = 8192
 		TYPE_TEXT_FLAG_CAP_SENTENCES      = 16384
 		TYPE_TEXT_FLAG_AUTO_CORRECT       = 32768
+		TYPE_TEXT_FLAG_NO_SUGGESTIONS     = 524288
+
+		TYPE_MASK_CLASS = 15
 
 		TYPE_CLASS_NUMBER        = 2
 		TYPE_NUMBER_FLAG_DECIMAL = 8192
@@ -1176,6 +1207,25 @@ func (w *window) SetInputHint(mode key.InputHint) {
 		default:
 			m = TYPE_CLASS_TEXT
 		}
+		if m&TYPE_MASK_CLASS == TYPE_CLASS_TEXT {
+			if opts&key.NoAutocorrect != 0 {
+				m &^= TYPE_TEXT_FLAG_AUTO_CORRECT
+			}
+			if opts&key.NoSpellcheck != 0 {
+				m |= TYPE_TEXT_FLAG_NO_SUGGESTIONS
+			}
+			const capFlags = TYPE_TEXT_FLAG_CAP_CHARACTERS | TYPE_TEXT_FLAG_CAP_WORDS | TYPE_TEXT_FLAG_CAP_SENTENCES
+			switch {
+			case opts&key.NoAutocapitalize != 0:
+				m &^= capFlags
+			case opts&key.AutocapitalizeCharacters != 0:
+				m = m&^capFlags | TYPE_TEXT_FLAG_CAP_CHARACTERS
+			case opts&key.AutocapitalizeWords != 0:
+				m = m&^capFlags | TYPE_TEXT_FLAG_CAP_WORDS
+			case opts&key.AutocapitalizeSentences != 0:
+				m = m&^capFlags | TYPE_TEXT_FLAG_CAP_SENTENCES
+			}
+		}
 
 		callVoidMethod(env, w.view, gioView.setInputHint, m)
 	})
@@ -1286,12 +1336,52 @@ func findClass(env *C.JNIEnv, name string) C.jclass {
 func osMain() {
 }
 
+// systemLanguage returns the language tag of the default Java Locale,
+// which follows the system language.
+func systemLanguage() string {
+	jvm := javaVM()
+	if jvm == nil {
+		return ""
+	}
+	var lang string
+	runInJVM(jvm, func(env *C.JNIEnv) {
+		cls := findClass(env, "java/util/Locale")
+		getDefault := getStaticMethodID(env, cls, "getDefault", "()Ljava/util/Locale;")
+		toLanguageTag := getMethodID(env, cls, "toLanguageTag", "()Ljava/lang/String;")
+		locale, err := callStaticObjectMethod(env, cls, getDefault)
+		if err != nil || locale == 0 {
+			return
+		}
+		tag, err := callObjectMethod(env, locale, toLanguageTag)
+		if err != nil {
+			return
+		}
+		lang = goString(env, C.jstring(tag))
+	})
+	if lang == "und" {
+		// The root locale has no language.
+		return ""
+	}
+	return lang
+}
+
+func systemForcedColors() ForcedColors {
+	return ForcedColors{}
+}
+
+func systemDoubleClick() (time.Duration, unit.Dp) {
+	return defaultDoubleClickTime, defaultDoubleClickDistance
+}
+
+func osRun(done <-chan struct{}) {
+}
+
 func newWindow(window *callbacks, options []Option) error {
 	mainWindow.in <- windowAndConfig{window, options}
 	return <-mainWindow.errs
 }
 
-func (w *window) WriteClipboard(s string) {
+func (w *window) WriteClipboard(_ clipboard.Selection, s string) {
 	runInJVM(javaVM(), func(env *C.JNIEnv) {
 		jstr := javaString(env, s)
 		callStaticVoidMethod(env, android.gioCls, android.mwriteClipboard,
@@ -1299,7 +1389,13 @@ func (w *window) WriteClipboard(s string) {
 	})
 }
 
-func (w *window) ReadClipboard() {
+func (w *window) ReadClipboardData(mime string) {
+	w.callbacks.Event(clipboard.Event{Type: mime})
+}
+
+func (w *window) WriteClipboardData(mime string, data []byte) {}
+
+func (w *window) ReadClipboard(clipboard.Selection) {
 	runInJVM(javaVM(), func(env *C.JNIEnv) {
 		c, err := callStaticObjectMethod(env, android.gioCls, android.mreadClipboard,
 			jvalue(android.appCtx))
@@ -1311,6 +1407,18 @@ func (w *window) ReadClipboard() {
 	})
 }
 
+func (w *window) StartDrag(data []byte, types []string) {
+	w.callbacks.Event(DragResultEvent{Result: DragCanceled})
+}
+
+func (w *window) SetProgress(fraction float64, state ProgressState) {}
+
+func (w *window) ProcessEvents() {}
+
+func (w *window) StartMove() {}
+
+func (w *window) StartResize(edge ResizeEdge) {}
+
 func (w *window) Configure(options []Option) {
 	runInJVM(javaVM(), func(env *C.JNIEnv) {
 		prev := w.config
@@ -1356,6 +1464,20 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	})
 }
 
+func (w *window) SetRelativeMouse(enable bool) {}
+
+func (w *window) SetCursorConfined(confine bool) {}
+
+func (w *window) SetInputShape(shape []image.Rectangle) {}
+
+func (w *window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	return false
+}
+
+func (w *window) SetLayoutDirection(dir system.TextDirection) {}
+
+func (w *window) SetCursorPos(pos image.Point) {}
+
 func (w *window) Wakeup() {
 	runOnMain(func(env *C.JNIEnv) {
 		w.callbacks.Event(wakeupEvent{})
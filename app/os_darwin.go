@@ -15,6 +15,12 @@ __attribute__ ((visibility ("hidden"))) void gio_hideCursor();
 __attribute__ ((visibility ("hidden"))) void gio_showCursor();
 __attribute__ ((visibility ("hidden"))) void gio_setCursor(NSUInteger curID);
 
+static CFTypeRef preferredLanguage(void) {
+	@autoreleasepool {
+		return CFBridgingRetain([NSLocale preferredLanguages].firstObject);
+	}
+}
+
 static bool isMainThread() {
 	return [NSThread isMainThread];
 }
@@ -96,6 +102,19 @@ func gio_dispatchMainFuncs() {
 	}
 }
 
+func systemLanguage() string {
+	lang := C.preferredLanguage()
+	if lang == 0 {
+		return ""
+	}
+	defer C.CFRelease(lang)
+	return nsstringToString(lang)
+}
+
+func systemForcedColors() ForcedColors {
+	return ForcedColors{}
+}
+
 // nsstringToString converts a NSString to a Go string.
 func nsstringToString(str C.CFTypeRef) string {
 	if str == 0 {
@@ -36,6 +36,18 @@ static CFTypeRef readClipboard(void) {
 	}
 }
 
+static double getViewRefreshRate(CFTypeRef viewRef) {
+	UIView *view = (__bridge UIView *)viewRef;
+	UIScreen *screen = view.window.screen;
+	if (screen == nil) {
+		screen = UIScreen.mainScreen;
+	}
+	if (@available(iOS 10.3, tvOS 10.3, *)) {
+		return screen.maximumFramesPerSecond;
+	}
+	return 0;
+}
+
 static void showTextInput(CFTypeRef viewRef) {
 	UIView *view = (__bridge UIView *)viewRef;
 	[view becomeFirstResponder];
@@ -46,6 +58,16 @@ static void hideTextInput(CFTypeRef viewRef) {
 	[view resignFirstResponder];
 }
 
+static void setInputTraits(CFTypeRef viewRef, UITextAutocorrectionType correct, UITextAutocapitalizationType capitalize, UITextSpellCheckingType spell) {
+	UIView<UITextInputTraits> *view = (__bridge UIView<UITextInputTraits> *)viewRef;
+	view.autocorrectionType = correct;
+	view.autocapitalizationType = capitalize;
+	view.spellCheckingType = spell;
+	if (view.isFirstResponder) {
+		[view reloadInputViews];
+	}
+}
+
 static struct drawParams viewDrawParams(CFTypeRef viewRef) {
 	UIView *v = (__bridge UIView *)viewRef;
 	struct drawParams params;
@@ -127,6 +149,7 @@ func onCreate(view, controller C.CFTypeRef) {
 	wopts := <-mainWindow.out
 	w.w = wopts.window
 	w.w.SetDriver(w)
+	w.w.SetRefreshRate(float64(C.getViewRefreshRate(view)))
 	views[view] = w
 	w.Configure(wopts.options)
 	w.w.Event(system.StageEvent{Stage: system.StagePaused})
@@ -178,6 +201,8 @@ func (w *window) draw(sync bool) {
 func onStop(view C.CFTypeRef) {
 	w := views[view]
 	w.visible = false
+	// A background app may be killed without further notice.
+	w.w.Event(SaveStateEvent{})
 	w.w.Event(system.StageEvent{Stage: system.StagePaused})
 }
 
@@ -261,14 +286,14 @@ func onTouch(last C.int, view, touchRef C.CFTypeRef, phase C.NSInteger, x, y C.C
 	})
 }
 
-func (w *window) ReadClipboard() {
+func (w *window) ReadClipboard(clipboard.Selection) {
 	cstr := C.readClipboard()
 	defer C.CFRelease(cstr)
 	content := nsstringToString(cstr)
 	w.w.Event(clipboard.Event{Text: content})
 }
 
-func (w *window) WriteClipboard(s string) {
+func (w *window) WriteClipboard(_ clipboard.Selection, s string) {
 	u16 := utf16.Encode([]rune(s))
 	var chars *C.unichar
 	if len(u16) > 0 {
@@ -277,12 +302,33 @@ func (w *window) WriteClipboard(s string) {
 	C.writeClipboard(chars, C.NSUInteger(len(u16)))
 }
 
-func (w *window) Configure([]Option) {
+func (w *window) ReadClipboardData(mime string) {
+	w.w.Event(clipboard.Event{Type: mime})
+}
+
+func (w *window) WriteClipboardData(mime string, data []byte) {}
+
+func (w *window) Configure(options []Option) {
+	var cnf Config
+	cnf.apply(unit.Metric{}, options)
+	w.config.ColorSpace = cnf.ColorSpace
 	// Decorations are never disabled.
 	w.config.Decorated = true
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
+func (w *window) StartDrag(data []byte, types []string) {
+	w.w.Event(DragResultEvent{Result: DragCanceled})
+}
+
+func (w *window) SetProgress(fraction float64, state ProgressState) {}
+
+func (w *window) ProcessEvents() {}
+
+func (w *window) StartMove() {}
+
+func (w *window) StartResize(edge ResizeEdge) {}
+
 func (w *window) EditorStateChanged(old, new editorState) {}
 
 func (w *window) Perform(system.Action) {}
@@ -303,6 +349,24 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	w.cursor = windowSetCursor(w.cursor, cursor)
 }
 
+func (w *window) SetRelativeMouse(enable bool) {}
+
+func (w *window) SetCursorConfined(confine bool) {}
+
+func systemDoubleClick() (time.Duration, unit.Dp) {
+	return defaultDoubleClickTime, defaultDoubleClickDistance
+}
+
+func (w *window) SetInputShape(shape []image.Rectangle) {}
+
+func (w *window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	return false
+}
+
+func (w *window) SetLayoutDirection(dir system.TextDirection) {}
+
+func (w *window) SetCursorPos(pos image.Point) {}
+
 func (w *window) onKeyCommand(name string) {
 	w.w.Event(key.Event{
 		Name: name,
@@ -341,7 +405,26 @@ func (w *window) ShowTextInput(show bool) {
 	}
 }
 
-func (w *window) SetInputHint(_ key.InputHint) {}
+func (w *window) SetInputHint(_ key.InputHint, opts key.InputOptions) {
+	correct := C.UITextAutocorrectionType(C.UITextAutocorrectionTypeDefault)
+	if opts&key.NoAutocorrect != 0 {
+		correct = C.UITextAutocorrectionTypeNo
+	}
+	spell := C.UITextSpellCheckingType(C.UITextSpellCheckingTypeDefault)
+	if opts&key.NoSpellcheck != 0 {
+		spell = C.UITextSpellCheckingTypeNo
+	}
+	capitalize := C.UITextAutocapitalizationType(C.UITextAutocapitalizationTypeSentences)
+	switch {
+	case opts&key.NoAutocapitalize != 0:
+		capitalize = C.UITextAutocapitalizationTypeNone
+	case opts&key.AutocapitalizeCharacters != 0:
+		capitalize = C.UITextAutocapitalizationTypeAllCharacters
+	case opts&key.AutocapitalizeWords != 0:
+		capitalize = C.UITextAutocapitalizationTypeWords
+	}
+	C.setInputTraits(w.view, correct, capitalize, spell)
+}
 
 func newWindow(win *callbacks, options []Option) error {
 	mainWindow.in <- windowAndConfig{win, options}
@@ -351,6 +434,9 @@ func newWindow(win *callbacks, options []Option) error {
 func osMain() {
 }
 
+func osRun(done <-chan struct{}) {
+}
+
 //export gio_runMain
 func gio_runMain() {
 	runMain()
@@ -3,9 +3,12 @@
 package app
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"image"
 	"image/color"
+	"image/png"
 	"strings"
 	"syscall/js"
 	"time"
@@ -63,6 +66,16 @@ type window struct {
 	animRequested bool
 	wakeups       chan struct{}
 
+	// pointerPos is the most recent pointer position.
+	pointerPos f32.Point
+	// relMouse is set while the pointer is locked, and relAnchor is the
+	// pointer position when the lock was requested.
+	relMouse  bool
+	relAnchor f32.Point
+	// splash is set while the canvas background shows the splash
+	// color.
+	splash bool
+
 	contextStatus contextStatus
 }
 
@@ -109,6 +122,13 @@ func newWindow(win *callbacks, options []Option) error {
 	go func() {
 		defer w.cleanup()
 		w.w.SetDriver(w)
+		var cnf Config
+		cnf.apply(unit.Metric{}, options)
+		if c := cnf.SplashColor; c.A != 0 {
+			// The canvas is transparent until the first frame.
+			w.cnv.Get("style").Set("background-color", fmt.Sprintf("rgb(%d, %d, %d)", c.R, c.G, c.B))
+			w.splash = true
+		}
 		w.Configure(options)
 		w.blur()
 		w.w.Event(system.StageEvent{Stage: system.StageRunning})
@@ -193,6 +213,16 @@ func (w *window) addEventListeners() {
 		w.requestRedraw()
 		return nil
 	})
+	w.addEventListener(w.window, "languagechange", func(this js.Value, args []js.Value) interface{} {
+		w.w.Event(LocaleEvent{})
+		return nil
+	})
+	if mql := forcedColorsQuery(); mql.Truthy() {
+		w.addEventListener(mql, "change", func(this js.Value, args []js.Value) interface{} {
+			w.w.Event(ForcedColorsEvent{})
+			return nil
+		})
+	}
 	w.addEventListener(w.window, "contextmenu", func(this js.Value, args []js.Value) interface{} {
 		args[0].Call("preventDefault")
 		return nil
@@ -208,12 +238,38 @@ func (w *window) addEventListeners() {
 		switch w.document.Get("visibilityState").String() {
 		case "hidden", "prerender", "unloaded":
 			ev.Stage = system.StagePaused
+			// A hidden page may be discarded without further notice.
+			w.w.Event(SaveStateEvent{})
 		default:
 			ev.Stage = system.StageRunning
 		}
 		w.w.Event(ev)
 		return nil
 	})
+	if observer := js.Global().Get("IntersectionObserver"); observer.Truthy() {
+		// Report the visible fraction of the canvas in coarse steps.
+		thresholds := []interface{}{0, .25, .5, .75, 1}
+		cb := w.funcOf(func(this js.Value, args []js.Value) interface{} {
+			entries := args[0]
+			n := entries.Length()
+			if n == 0 {
+				return nil
+			}
+			ratio := entries.Index(n - 1).Get("intersectionRatio").Float()
+			w.w.Event(VisibilityEvent{Visible: float32(ratio)})
+			return nil
+		})
+		obs := observer.New(cb, map[string]interface{}{"threshold": thresholds})
+		obs.Call("observe", w.cnv)
+		w.cleanfuncs = append(w.cleanfuncs, func() {
+			obs.Call("disconnect")
+		})
+	}
+	w.addEventListener(w.document, "pointerlockchange", func(this js.Value, args []js.Value) interface{} {
+		// The user may exit the lock, for example with the Escape key.
+		w.relMouse = w.document.Get("pointerLockElement").Equal(w.cnv)
+		return nil
+	})
 	w.addEventListener(w.cnv, "mousemove", func(this js.Value, args []js.Value) interface{} {
 		w.pointerEvent(pointer.Move, 0, 0, args[0])
 		return nil
@@ -454,6 +510,18 @@ func (w *window) pointerEvent(typ pointer.Type, dx, dy float32, e js.Value) {
 		X: dx * scale,
 		Y: dy * scale,
 	}
+	var delta f32.Point
+	if w.relMouse {
+		pos = w.relAnchor
+		if typ == pointer.Move {
+			delta = f32.Point{
+				X: float32(e.Get("movementX").Float()) * scale,
+				Y: float32(e.Get("movementY").Float()) * scale,
+			}
+		}
+	} else {
+		w.pointerPos = pos
+	}
 	t := time.Duration(e.Get("timeStamp").Int()) * time.Millisecond
 	jbtns := e.Get("buttons").Int()
 	var btns pointer.Buttons
@@ -472,6 +540,7 @@ func (w *window) pointerEvent(typ pointer.Type, dx, dy float32, e js.Value) {
 		Buttons:   btns,
 		Position:  pos,
 		Scroll:    scroll,
+		Delta:     delta,
 		Time:      t,
 		Modifiers: modifiersFor(e),
 	})
@@ -514,7 +583,7 @@ func (w *window) SetAnimating(anim bool) {
 	}
 }
 
-func (w *window) ReadClipboard() {
+func (w *window) ReadClipboard(clipboard.Selection) {
 	if w.clipboard.IsUndefined() {
 		return
 	}
@@ -524,7 +593,7 @@ func (w *window) ReadClipboard() {
 	w.clipboard.Call("readText", w.clipboard).Call("then", w.clipboardCallback)
 }
 
-func (w *window) WriteClipboard(s string) {
+func (w *window) WriteClipboard(_ clipboard.Selection, s string) {
 	if w.clipboard.IsUndefined() {
 		return
 	}
@@ -534,6 +603,12 @@ func (w *window) WriteClipboard(s string) {
 	w.clipboard.Call("writeText", s)
 }
 
+func (w *window) ReadClipboardData(mime string) {
+	w.w.Event(clipboard.Event{Type: mime})
+}
+
+func (w *window) WriteClipboardData(mime string, data []byte) {}
+
 func (w *window) Configure(options []Option) {
 	prev := w.config
 	cnf := w.config
@@ -562,6 +637,18 @@ func (w *window) Configure(options []Option) {
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
+func (w *window) StartDrag(data []byte, types []string) {
+	w.w.Event(DragResultEvent{Result: DragCanceled})
+}
+
+func (w *window) SetProgress(fraction float64, state ProgressState) {}
+
+func (w *window) ProcessEvents() {}
+
+func (w *window) StartMove() {}
+
+func (w *window) StartResize(edge ResizeEdge) {}
+
 func (w *window) Perform(system.Action) {}
 
 var webCursor = [...]string{
@@ -598,6 +685,47 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	style.Set("cursor", webCursor[cursor])
 }
 
+func (w *window) SetRelativeMouse(enable bool) {
+	if enable {
+		w.relAnchor = w.pointerPos
+		// The browser may refuse the lock outside of a user
+		// gesture.
+		w.cnv.Call("requestPointerLock")
+		return
+	}
+	if w.relMouse {
+		w.document.Call("exitPointerLock")
+	}
+}
+
+func (w *window) SetCursorConfined(confine bool) {}
+
+func (w *window) SetInputShape(shape []image.Rectangle) {}
+
+func (w *window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	if img == nil {
+		return true
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return false
+	}
+	url := base64.StdEncoding.EncodeToString(buf.Bytes())
+	style := w.cnv.Get("style")
+	style.Set("cursor", fmt.Sprintf("url(data:image/png;base64,%s) %d %d, auto", url, hotspot.X, hotspot.Y))
+	return true
+}
+
+func (w *window) SetLayoutDirection(dir system.TextDirection) {
+	d := "ltr"
+	if dir == system.RTL {
+		d = "rtl"
+	}
+	w.cnv.Set("dir", d)
+}
+
+func (w *window) SetCursorPos(pos image.Point) {}
+
 func (w *window) Wakeup() {
 	select {
 	case w.wakeups <- struct{}{}:
@@ -617,8 +745,26 @@ func (w *window) ShowTextInput(show bool) {
 	}()
 }
 
-func (w *window) SetInputHint(mode key.InputHint) {
+func (w *window) SetInputHint(mode key.InputHint, opts key.InputOptions) {
 	w.keyboard(mode)
+	correct := "on"
+	if opts&key.NoAutocorrect != 0 {
+		correct = "off"
+	}
+	w.tarea.Call("setAttribute", "autocorrect", correct)
+	w.tarea.Set("spellcheck", opts&key.NoSpellcheck == 0)
+	capitalize := ""
+	switch {
+	case opts&key.NoAutocapitalize != 0:
+		capitalize = "none"
+	case opts&key.AutocapitalizeCharacters != 0:
+		capitalize = "characters"
+	case opts&key.AutocapitalizeWords != 0:
+		capitalize = "words"
+	case opts&key.AutocapitalizeSentences != 0:
+		capitalize = "sentences"
+	}
+	w.tarea.Set("autocapitalize", capitalize)
 }
 
 func (w *window) resize() {
@@ -634,6 +780,20 @@ func (w *window) resize() {
 		w.w.Event(ConfigEvent{Config: w.config})
 	}
 
+	if screen := w.window.Get("screen"); screen.Truthy() {
+		// availLeft and availTop are not standard.
+		var x, y float64
+		if v := screen.Get("availLeft"); !v.IsUndefined() {
+			x = v.Float()
+		}
+		if v := screen.Get("availTop"); !v.IsUndefined() {
+			y = v.Float()
+		}
+		width, height := screen.Get("availWidth").Float(), screen.Get("availHeight").Float()
+		s := float64(w.scale)
+		w.w.SetWorkArea(image.Rect(int(x*s), int(y*s), int((x+width)*s), int((y+height)*s)))
+	}
+
 	if vx, vy := w.visualViewport.Get("width"), w.visualViewport.Get("height"); !vx.IsUndefined() && !vy.IsUndefined() {
 		w.inset.X = float32(w.config.Size.X) - float32(vx.Float())*w.scale
 		w.inset.Y = float32(w.config.Size.Y) - float32(vy.Float())*w.scale
@@ -664,7 +824,14 @@ func (w *window) draw(sync bool) {
 			Metric: metric,
 		},
 		Sync: sync,
+		// The visual viewport shrinks for the on-screen keyboard.
+		Keyboard: int(w.inset.Y),
 	})
+	if w.splash {
+		// Remove the splash behind the (possibly transparent) frame.
+		w.splash = false
+		w.cnv.Get("style").Set("background-color", "")
+	}
 }
 
 func (w *window) getConfig() (image.Point, system.Insets, unit.Metric) {
@@ -737,6 +904,70 @@ func osMain() {
 	select {}
 }
 
+func systemLanguage() string {
+	lang := js.Global().Get("navigator").Get("language")
+	if !lang.Truthy() {
+		return ""
+	}
+	return lang.String()
+}
+
+// forcedColorsQuery returns the media query list of the forced colors
+// mode, or undefined if not supported.
+func forcedColorsQuery() js.Value {
+	matchMedia := js.Global().Get("matchMedia")
+	if !matchMedia.Truthy() {
+		return js.Undefined()
+	}
+	return js.Global().Call("matchMedia", "(forced-colors: active)")
+}
+
+func systemDoubleClick() (time.Duration, unit.Dp) {
+	return defaultDoubleClickTime, defaultDoubleClickDistance
+}
+
+func systemForcedColors() ForcedColors {
+	mql := forcedColorsQuery()
+	if !mql.Truthy() || !mql.Get("matches").Bool() {
+		return ForcedColors{}
+	}
+	doc := js.Global().Get("document")
+	body := doc.Get("body")
+	if !body.Truthy() {
+		return ForcedColors{Enabled: true}
+	}
+	// Resolve the system colors through the computed style of an
+	// element.
+	elem := doc.Call("createElement", "div")
+	body.Call("appendChild", elem)
+	defer body.Call("removeChild", elem)
+	style := elem.Get("style")
+	sysColor := func(name string) color.NRGBA {
+		style.Set("color", name)
+		col := js.Global().Call("getComputedStyle", elem).Get("color").String()
+		var r, g, b uint8
+		if _, err := fmt.Sscanf(col, "rgb(%d, %d, %d)", &r, &g, &b); err != nil {
+			return color.NRGBA{}
+		}
+		return color.NRGBA{R: r, G: g, B: b, A: 0xff}
+	}
+	return ForcedColors{
+		Enabled:       true,
+		Canvas:        sysColor("Canvas"),
+		CanvasText:    sysColor("CanvasText"),
+		LinkText:      sysColor("LinkText"),
+		GrayText:      sysColor("GrayText"),
+		Highlight:     sysColor("Highlight"),
+		HighlightText: sysColor("HighlightText"),
+		ButtonFace:    sysColor("ButtonFace"),
+		ButtonText:    sysColor("ButtonText"),
+	}
+}
+
+func osRun(done <-chan struct{}) {
+	<-done
+}
+
 func translateKey(k string) (string, bool) {
 	var n string
 
@@ -8,15 +8,21 @@ package app
 import (
 	"errors"
 	"image"
+	"math"
+	"net/url"
 	"runtime"
 	"time"
 	"unicode"
 	"unicode/utf8"
+	"unsafe"
 
 	"gioui.org/internal/f32"
 	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
 	"gioui.org/io/key"
 	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/semantic"
 	"gioui.org/io/system"
 	"gioui.org/unit"
 
@@ -25,17 +31,36 @@ import (
 
 /*
 #cgo CFLAGS: -Werror -Wno-deprecated-declarations -fobjc-arc -x objective-c
-#cgo LDFLAGS: -framework AppKit -framework QuartzCore
+#cgo LDFLAGS: -framework AppKit -framework QuartzCore -framework CoreServices
 
+#include <string.h>
 #include <AppKit/AppKit.h>
+#include <CoreServices/CoreServices.h>
 
 #define MOUSE_MOVE 1
 #define MOUSE_UP 2
 #define MOUSE_DOWN 3
 #define MOUSE_SCROLL 4
 
+#define GESTURE_SWIPE 1
+#define GESTURE_ROTATE 2
+#define GESTURE_SMART_ZOOM 3
+
+#define ROLE_GROUP 0
+#define ROLE_STATIC_TEXT 1
+#define ROLE_BUTTON 2
+#define ROLE_CHECK_BOX 3
+#define ROLE_RADIO_BUTTON 4
+#define ROLE_TEXT_FIELD 5
+
+#define SEMANTIC_SELECTED 1
+#define SEMANTIC_DISABLED 2
+
 __attribute__ ((visibility ("hidden"))) void gio_main(void);
+__attribute__ ((visibility ("hidden"))) void gio_stop(void);
 __attribute__ ((visibility ("hidden"))) CFTypeRef gio_createView(void);
+__attribute__ ((visibility ("hidden"))) int gio_startDrag(CFTypeRef viewRef, CFTypeRef *writers, int n);
+__attribute__ ((visibility ("hidden"))) void gio_setInputShaped(CFTypeRef viewRef, int shaped);
 __attribute__ ((visibility ("hidden"))) CFTypeRef gio_createWindow(CFTypeRef viewRef, CGFloat width, CGFloat height, CGFloat minWidth, CGFloat minHeight, CGFloat maxWidth, CGFloat maxHeight);
 
 static void writeClipboard(CFTypeRef str) {
@@ -47,6 +72,58 @@ static void writeClipboard(CFTypeRef str) {
 	}
 }
 
+// pasteboardTypeForMIME returns the pasteboard type of a MIME type.
+static NSPasteboardType pasteboardTypeForMIME(NSString *mime) {
+	if ([mime isEqualToString:@"text/plain"] || [mime hasPrefix:@"text/plain;"]) {
+		return NSPasteboardTypeString;
+	}
+	CFStringRef uti = UTTypeCreatePreferredIdentifierForTag(kUTTagClassMIMEType, (__bridge CFStringRef)mime, NULL);
+	return (__bridge_transfer NSString *)uti;
+}
+
+static void writeClipboardData(CFTypeRef mimeRef, const void *data, NSUInteger length) {
+	@autoreleasepool {
+		NSPasteboardType t = pasteboardTypeForMIME((__bridge NSString *)mimeRef);
+		if (t == nil) {
+			return;
+		}
+		NSPasteboard *p = NSPasteboard.generalPasteboard;
+		[p declareTypes:@[t] owner:nil];
+		[p setData:[NSData dataWithBytes:data length:length] forType:t];
+	}
+}
+
+static CFTypeRef readClipboardData(CFTypeRef mimeRef) {
+	@autoreleasepool {
+		NSPasteboardType t = pasteboardTypeForMIME((__bridge NSString *)mimeRef);
+		if (t == nil) {
+			return nil;
+		}
+		NSData *data = [NSPasteboard.generalPasteboard dataForType:t];
+		return (__bridge_retained CFTypeRef)data;
+	}
+}
+
+static CFTypeRef newPasteboardItem(void) {
+	@autoreleasepool {
+		return CFBridgingRetain([[NSPasteboardItem alloc] init]);
+	}
+}
+
+static void setPasteboardItemData(CFTypeRef itemRef, CFTypeRef mimeRef, const void *data, NSUInteger length) {
+	@autoreleasepool {
+		NSPasteboardItem *item = (__bridge NSPasteboardItem *)itemRef;
+		NSString *mime = (__bridge NSString *)mimeRef;
+		[item setData:[NSData dataWithBytes:data length:length] forType:pasteboardTypeForMIME(mime)];
+	}
+}
+
+static CFTypeRef newFileURL(CFTypeRef pathRef) {
+	@autoreleasepool {
+		return CFBridgingRetain([NSURL fileURLWithPath:(__bridge NSString *)pathRef]);
+	}
+}
+
 static CFTypeRef readClipboard(void) {
 	@autoreleasepool {
 		NSPasteboard *p = NSPasteboard.generalPasteboard;
@@ -69,6 +146,49 @@ static CGFloat getScreenBackingScale(void) {
 	return [NSScreen.mainScreen backingScaleFactor];
 }
 
+static double getDoubleClickInterval(void) {
+	return [NSEvent doubleClickInterval];
+}
+
+static double getViewRefreshRate(CFTypeRef viewRef) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSScreen *screen = view.window.screen;
+	if (screen == nil) {
+		screen = NSScreen.mainScreen;
+	}
+	CGDirectDisplayID did = [[screen deviceDescription][@"NSScreenNumber"] unsignedIntValue];
+	CGDisplayModeRef mode = CGDisplayCopyDisplayMode(did);
+	if (mode == NULL) {
+		return 0;
+	}
+	double hz = CGDisplayModeGetRefreshRate(mode);
+	CGDisplayModeRelease(mode);
+	return hz;
+}
+
+static int isVoiceOverEnabled(void) {
+	return [NSWorkspace sharedWorkspace].voiceOverEnabled;
+}
+
+static void semanticsChanged(CFTypeRef viewRef) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSAccessibilityPostNotification(view, NSAccessibilityLayoutChangedNotification);
+}
+
+// getViewWorkArea returns the visible frame of the screen of the view,
+// with the origin at the top left corner of the main screen.
+static NSRect getViewWorkArea(CFTypeRef viewRef) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSScreen *screen = view.window.screen;
+	if (screen == nil) {
+		screen = NSScreen.mainScreen;
+	}
+	NSRect r = screen.visibleFrame;
+	CGFloat height = NSScreen.screens[0].frame.size.height;
+	r.origin.y = height - (r.origin.y + r.size.height);
+	return r;
+}
+
 static CGFloat getViewBackingScale(CFTypeRef viewRef) {
 	NSView *view = (__bridge NSView *)viewRef;
 	return [view.window backingScaleFactor];
@@ -104,6 +224,56 @@ static void setWindowStyleMask(CFTypeRef windowRef, NSWindowStyleMask mask) {
 	window.styleMask = mask;
 }
 
+static CFTypeRef createCursor(const void *pixels, int width, int height, int hotX, int hotY, CGFloat scale) {
+	@autoreleasepool {
+		NSBitmapImageRep *rep = [[NSBitmapImageRep alloc] initWithBitmapDataPlanes:NULL
+																		pixelsWide:width
+																		pixelsHigh:height
+																	 bitsPerSample:8
+																   samplesPerPixel:4
+																		  hasAlpha:YES
+																		  isPlanar:NO
+																	colorSpaceName:NSDeviceRGBColorSpace
+																	  bitmapFormat:NSBitmapFormatAlphaNonpremultiplied
+																	   bytesPerRow:width*4
+																	  bitsPerPixel:32];
+		if (rep == nil) {
+			return nil;
+		}
+		memcpy([rep bitmapData], pixels, width*height*4);
+		NSImage *img = [[NSImage alloc] initWithSize:NSMakeSize(width/scale, height/scale)];
+		[img addRepresentation:rep];
+		NSCursor *cursor = [[NSCursor alloc] initWithImage:img hotSpot:NSMakePoint(hotX/scale, hotY/scale)];
+		return CFBridgingRetain(cursor);
+	}
+}
+
+// setCursor sets the cursor, or the arrow cursor if cursorRef is nil.
+static void setCursor(CFTypeRef cursorRef) {
+	@autoreleasepool {
+		NSCursor *cursor = NSCursor.arrowCursor;
+		if (cursorRef != nil) {
+			cursor = (__bridge NSCursor *)cursorRef;
+		}
+		[cursor set];
+	}
+}
+
+static void setLayoutDirection(CFTypeRef viewRef, int rtl) {
+	NSView *view = (__bridge NSView *)viewRef;
+	view.userInterfaceLayoutDirection = rtl ? NSUserInterfaceLayoutDirectionRightToLeft : NSUserInterfaceLayoutDirectionLeftToRight;
+}
+
+static void setWindowResizable(CFTypeRef windowRef, int resizable) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	if (resizable) {
+		window.styleMask |= NSWindowStyleMaskResizable;
+	} else {
+		window.styleMask &= ~NSWindowStyleMaskResizable;
+	}
+	[window standardWindowButton:NSWindowZoomButton].enabled = (BOOL)resizable;
+}
+
 static void setWindowTitleVisibility(CFTypeRef windowRef, NSWindowTitleVisibility state) {
 	NSWindow *window = (__bridge NSWindow *)windowRef;
 	window.titleVisibility = state;
@@ -124,6 +294,152 @@ static void performWindowDragWithEvent(CFTypeRef windowRef, CFTypeRef evt) {
 	[window performWindowDragWithEvent:(__bridge NSEvent*)evt];
 }
 
+static void performWindowDrag(CFTypeRef windowRef) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	NSEvent *evt = [NSApp currentEvent];
+	// Window drags must be started from a mouse down.
+	if (evt.type == NSEventTypeLeftMouseDown) {
+		[window performWindowDragWithEvent:evt];
+	}
+}
+
+// performWindowResize resizes the window edges while the mouse
+// button of the current mouse down is held. Cocoa has no API for
+// starting a resize, so the mouse is tracked as for a live resize.
+static void performWindowResize(CFTypeRef windowRef, int north, int south, int west, int east) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	NSEvent *evt = [NSApp currentEvent];
+	if (evt.type != NSEventTypeLeftMouseDown && evt.type != NSEventTypeLeftMouseDragged) {
+		return;
+	}
+	NSRect start = window.frame;
+	NSPoint origin = [NSEvent mouseLocation];
+	NSSize minSize = window.minSize, maxSize = window.maxSize;
+	for (;;) {
+		evt = [window nextEventMatchingMask:NSEventMaskLeftMouseDragged|NSEventMaskLeftMouseUp];
+		if (evt.type == NSEventTypeLeftMouseUp) {
+			// Deliver the release to the view.
+			[window sendEvent:evt];
+			break;
+		}
+		NSPoint p = [NSEvent mouseLocation];
+		// Screen coordinates grow upwards.
+		CGFloat dx = p.x - origin.x, dy = p.y - origin.y;
+		NSRect r = start;
+		if (east) {
+			r.size.width += dx;
+		}
+		if (west) {
+			r.size.width -= dx;
+		}
+		if (north) {
+			r.size.height += dy;
+		}
+		if (south) {
+			r.size.height -= dy;
+		}
+		r.size.width = MIN(MAX(r.size.width, minSize.width), maxSize.width);
+		r.size.height = MIN(MAX(r.size.height, minSize.height), maxSize.height);
+		// Keep the opposite edges in place.
+		if (west) {
+			r.origin.x = NSMaxX(start) - r.size.width;
+		}
+		if (south) {
+			r.origin.y = NSMaxY(start) - r.size.height;
+		}
+		[window setFrame:r display:YES];
+	}
+}
+
+static void getMouseLocation(CFTypeRef viewRef, CGFloat *x, CGFloat *y) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSPoint p = [view.window mouseLocationOutsideOfEventStream];
+	p = [view convertPoint:p fromView:nil];
+	*x = p.x;
+	*y = view.bounds.size.height - p.y;
+}
+
+static void setMouseDetached(bool detached) {
+	CGAssociateMouseAndMouseCursorPosition(!detached);
+	if (detached) {
+		[NSCursor hide];
+	} else {
+		[NSCursor unhide];
+	}
+}
+
+static void setDockProgress(int percent) {
+	NSString *label = nil;
+	if (percent >= 0) {
+		label = [NSString stringWithFormat:@"%d%%", percent];
+	}
+	[[NSApp dockTile] setBadgeLabel:label];
+}
+
+static void warpCursor(CFTypeRef viewRef, CGFloat x, CGFloat y) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSPoint p = NSMakePoint(x, view.bounds.size.height - y);
+	p = [view convertPoint:p toView:nil];
+	p = [view.window convertPointToScreen:p];
+	// Convert to the global display space, which has its origin in the
+	// upper left corner of the main screen.
+	CGFloat height = NSScreen.screens[0].frame.size.height;
+	CGWarpMouseCursorPosition(CGPointMake(p.x, height - p.y));
+	// Avoid the brief freeze of the cursor after warping.
+	CGAssociateMouseAndMouseCursorPosition(true);
+}
+
+static void setSplashColor(CFTypeRef windowRef, CGFloat r, CGFloat g, CGFloat b) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	window.backgroundColor = [NSColor colorWithSRGBRed:r green:g blue:b alpha:1.0];
+}
+
+static void setWindowMaterial(CFTypeRef windowRef, CFTypeRef viewRef, int enable, NSVisualEffectMaterial material) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	NSView *view = (__bridge NSView *)viewRef;
+	NSView *parent = view.superview;
+	for (NSView *v in [parent.subviews copy]) {
+		if ([v.identifier isEqualToString:@"gio.material"]) {
+			[v removeFromSuperview];
+		}
+	}
+	window.opaque = !enable;
+	view.layer.opaque = !enable;
+	if (!enable) {
+		window.backgroundColor = [NSColor windowBackgroundColor];
+		return;
+	}
+	window.backgroundColor = [NSColor clearColor];
+	NSVisualEffectView *effect = [[NSVisualEffectView alloc] initWithFrame:view.frame];
+	effect.identifier = @"gio.material";
+	effect.autoresizingMask = NSViewWidthSizable | NSViewHeightSizable;
+	effect.blendingMode = NSVisualEffectBlendingModeBehindWindow;
+	effect.state = NSVisualEffectStateActive;
+	effect.material = material;
+	[parent addSubview:effect positioned:NSWindowBelow relativeTo:view];
+}
+
+static void setResizeIncrements(CFTypeRef windowRef, CGFloat width, CGFloat height) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	// Setting the increments clears the aspect ratio.
+	window.contentResizeIncrements = NSMakeSize(MAX(width, 1), MAX(height, 1));
+}
+
+static void setAspectRatio(CFTypeRef windowRef, CGFloat width, CGFloat height) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	if (width > 0 && height > 0) {
+		window.contentAspectRatio = NSMakeSize(width, height);
+	} else {
+		// Setting the increments clears the aspect ratio.
+		window.contentResizeIncrements = NSMakeSize(1, 1);
+	}
+}
+
+static void setDocumentEdited(CFTypeRef windowRef, int edited) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	window.documentEdited = (BOOL)edited;
+}
+
 static void closeWindow(CFTypeRef windowRef) {
 	NSWindow* window = (__bridge NSWindow *)windowRef;
 	[window performClose:nil];
@@ -151,6 +467,19 @@ static void setScreenFrame(CFTypeRef windowRef, CGFloat x, CGFloat y, CGFloat w,
 	[window setFrame:r display:YES];
 }
 
+static void setSkipTaskbar(CFTypeRef windowRef, int skip, int accessory) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	NSWindowCollectionBehavior behavior = window.collectionBehavior;
+	behavior &= ~(NSWindowCollectionBehaviorIgnoresCycle | NSWindowCollectionBehaviorParticipatesInCycle);
+	behavior |= skip ? NSWindowCollectionBehaviorIgnoresCycle : NSWindowCollectionBehaviorParticipatesInCycle;
+	window.collectionBehavior = behavior;
+	window.excludedFromWindowsMenu = skip ? YES : NO;
+	NSApplicationActivationPolicy policy = accessory ? NSApplicationActivationPolicyAccessory : NSApplicationActivationPolicyRegular;
+	if (NSApp.activationPolicy != policy) {
+		[NSApp setActivationPolicy:policy];
+	}
+}
+
 static void hideWindow(CFTypeRef windowRef) {
 	NSWindow* window = (__bridge NSWindow *)windowRef;
 	[window miniaturize:window];
@@ -191,8 +520,19 @@ static CFTypeRef windowForView(CFTypeRef viewRef) {
 	return (__bridge CFTypeRef)view.window;
 }
 
+static void orderOutWindow(CFTypeRef windowRef) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	[window orderOut:nil];
+}
+
 static void raiseWindow(CFTypeRef windowRef) {
 	NSWindow* window = (__bridge NSWindow *)windowRef;
+	if (window.miniaturized) {
+		[window deminiaturize:nil];
+	}
+	// Bring the application to the front as well, for example when
+	// another instance asks to show the window.
+	[NSApp activateIgnoringOtherApps:YES];
 	[window makeKeyAndOrderFront:nil];
 }
 
@@ -253,6 +593,19 @@ type window struct {
 
 	scale  float32
 	config Config
+	// relMouse is set in relative mouse mode, and relAnchor is the
+	// pointer position when the mode was enabled.
+	relMouse  bool
+	relAnchor f32.Point
+
+	// semanticDiffs is scratch space for semantic changes.
+	semanticDiffs []router.SemanticID
+	// customCursor is the NSCursor created by SetCustomCursor.
+	customCursor C.CFTypeRef
+	// inputShape is the shape set by SetInputShape.
+	inputShape []image.Rectangle
+	// closing is set when the program closes the window.
+	closing bool
 }
 
 // viewMap is the mapping from Cocoa NSViews to Go windows.
@@ -295,19 +648,41 @@ func (w *window) contextView() C.CFTypeRef {
 	return w.view
 }
 
-func (w *window) ReadClipboard() {
+func (w *window) ReadClipboard(clipboard.Selection) {
 	cstr := C.readClipboard()
 	defer C.CFRelease(cstr)
 	content := nsstringToString(cstr)
 	w.w.Event(clipboard.Event{Text: content})
 }
 
-func (w *window) WriteClipboard(s string) {
+func (w *window) WriteClipboard(_ clipboard.Selection, s string) {
 	cstr := stringToNSString(s)
 	defer C.CFRelease(cstr)
 	C.writeClipboard(cstr)
 }
 
+func (w *window) ReadClipboardData(mime string) {
+	cmime := stringToNSString(mime)
+	defer C.CFRelease(cmime)
+	e := clipboard.Event{Type: mime}
+	if data := C.readClipboardData(cmime); data != 0 {
+		defer C.CFRelease(data)
+		n := C.CFDataGetLength(C.CFDataRef(data))
+		e.Data = C.GoBytes(unsafe.Pointer(C.CFDataGetBytePtr(C.CFDataRef(data))), C.int(n))
+	}
+	w.w.Event(e)
+}
+
+func (w *window) WriteClipboardData(mime string, data []byte) {
+	cmime := stringToNSString(mime)
+	defer C.CFRelease(cmime)
+	var ptr unsafe.Pointer
+	if len(data) > 0 {
+		ptr = unsafe.Pointer(&data[0])
+	}
+	C.writeClipboardData(cmime, ptr, C.NSUInteger(len(data)))
+}
+
 func (w *window) updateWindowMode() {
 	style := int(C.getWindowStyleMask(C.windowForView(w.view)))
 	if style&C.NSWindowStyleMaskFullScreen != 0 {
@@ -318,6 +693,97 @@ func (w *window) updateWindowMode() {
 	w.config.Decorated = style&C.NSWindowStyleMaskFullSizeContentView == 0
 }
 
+func (w *window) StartDrag(data []byte, types []string) {
+	if len(types) == 0 {
+		w.w.Event(DragResultEvent{Result: DragCanceled})
+		return
+	}
+	var ptr unsafe.Pointer
+	if len(data) > 0 {
+		ptr = unsafe.Pointer(&data[0])
+	}
+	item := C.newPasteboardItem()
+	writers := []C.CFTypeRef{item}
+	for _, t := range types {
+		mime := stringToNSString(t)
+		C.setPasteboardItemData(item, mime, ptr, C.NSUInteger(len(data)))
+		C.CFRelease(mime)
+		if t == "text/uri-list" {
+			// File managers such as the Finder accept file URLs
+			// as separate items.
+			for _, p := range fileURLPaths(data) {
+				path := stringToNSString(p)
+				writers = append(writers, C.newFileURL(path))
+				C.CFRelease(path)
+			}
+		}
+	}
+	ok := C.gio_startDrag(w.view, &writers[0], C.int(len(writers)))
+	for _, wr := range writers {
+		C.CFRelease(wr)
+	}
+	if ok == 0 {
+		w.w.Event(DragResultEvent{Result: DragCanceled})
+	}
+}
+
+//export gio_onDragEnd
+func gio_onDragEnd(view C.CFTypeRef, op C.NSUInteger) {
+	w := mustView(view)
+	// The drag session consumed the release of the button.
+	w.w.Event(pointer.Event{Type: pointer.Cancel, Source: pointer.Mouse})
+	res := DragCopied
+	switch {
+	case op == C.NSDragOperationNone:
+		res = DragCanceled
+	case op&C.NSDragOperationMove != 0:
+		res = DragMoved
+	}
+	w.w.Event(DragResultEvent{Result: res})
+}
+
+func (w *window) SetProgress(fraction float64, state ProgressState) {
+	percent := -1
+	if state != ProgressNone {
+		percent = int(fraction*100 + .5)
+	}
+	C.setDockProgress(C.int(percent))
+}
+
+func (w *window) ProcessEvents() {}
+
+func (w *window) StartMove() {
+	if w.config.Mode != Fullscreen {
+		C.performWindowDrag(C.windowForView(w.view))
+	}
+}
+
+func (w *window) StartResize(edge ResizeEdge) {
+	if w.config.Mode != Windowed {
+		return
+	}
+	var n, s, west, e C.int
+	switch edge {
+	case EdgeNorth:
+		n = 1
+	case EdgeSouth:
+		s = 1
+	case EdgeWest:
+		west = 1
+	case EdgeEast:
+		e = 1
+	case EdgeNorthWest:
+		n, west = 1, 1
+	case EdgeNorthEast:
+		n, e = 1, 1
+	case EdgeSouthWest:
+		s, west = 1, 1
+	case EdgeSouthEast:
+		s, e = 1, 1
+	}
+	C.performWindowResize(C.windowForView(w.view), n, s, west, e)
+}
+
 func (w *window) Configure(options []Option) {
 	screenScale := float32(C.getScreenBackingScale())
 	cfg := configFor(screenScale)
@@ -325,6 +791,15 @@ func (w *window) Configure(options []Option) {
 	w.updateWindowMode()
 	cnf := w.config
 	cnf.apply(cfg, options)
+	if cnf.Mode == Minimized && cnf.MinimizeToTray {
+		// Hide the window instead of minimizing it.
+		cnf.Mode = prev.Mode
+		cnf.Hidden = true
+	}
+	w.config.MinimizeToTray = cnf.MinimizeToTray
+	// Windows have shadows regardless of decorations.
+	w.config.Shadow = true
+	w.config.ColorSpace = cnf.ColorSpace
 	window := C.windowForView(w.view)
 
 	switch cnf.Mode {
@@ -386,6 +861,17 @@ func (w *window) Configure(options []Option) {
 			cnf.MaxSize = cnf.MaxSize.Div(int(screenScale))
 			C.setMaxSize(window, C.CGFloat(cnf.MaxSize.X), C.CGFloat(cnf.MaxSize.Y))
 		}
+		if prev.AspectRatio != cnf.AspectRatio || prev.ResizeIncrements != cnf.ResizeIncrements {
+			w.config.AspectRatio = cnf.AspectRatio
+			w.config.ResizeIncrements = cnf.ResizeIncrements
+			C.setAspectRatio(window, C.CGFloat(cnf.AspectRatio.X), C.CGFloat(cnf.AspectRatio.Y))
+			// The aspect ratio and increments are exclusive, and
+			// the increments take precedence.
+			if inc := cnf.ResizeIncrements; inc != (image.Point{}) {
+				scale := C.CGFloat(screenScale)
+				C.setResizeIncrements(window, C.CGFloat(inc.X)/scale, C.CGFloat(inc.Y)/scale)
+			}
+		}
 	}
 	if cnf.Decorated != prev.Decorated {
 		w.config.Decorated = cnf.Decorated
@@ -407,6 +893,66 @@ func (w *window) Configure(options []Option) {
 		C.setWindowStandardButtonHidden(window, C.NSWindowMiniaturizeButton, barTrans)
 		C.setWindowStandardButtonHidden(window, C.NSWindowZoomButton, barTrans)
 	}
+	if cnf.Resizable != prev.Resizable {
+		w.config.Resizable = cnf.Resizable
+		resizable := C.int(C.NO)
+		if cnf.Resizable {
+			resizable = C.YES
+		}
+		C.setWindowResizable(window, resizable)
+	}
+	if cnf.Material != prev.Material {
+		w.config.Material = cnf.Material
+		enable := C.int(C.YES)
+		var material C.NSVisualEffectMaterial
+		switch cnf.Material {
+		case MaterialNone:
+			enable = C.NO
+		case MaterialBlur:
+			material = C.NSVisualEffectMaterialUnderWindowBackground
+		case MaterialAcrylic:
+			material = C.NSVisualEffectMaterialHUDWindow
+		case MaterialMica:
+			material = C.NSVisualEffectMaterialWindowBackground
+		}
+		C.setWindowMaterial(window, w.view, enable, material)
+	}
+	w.config.AcceptFirstMouse = cnf.AcceptFirstMouse
+	if cnf.DocumentEdited != prev.DocumentEdited {
+		w.config.DocumentEdited = cnf.DocumentEdited
+		edited := C.int(C.NO)
+		if cnf.DocumentEdited {
+			edited = C.YES
+		}
+		C.setDocumentEdited(window, edited)
+	}
+	if cnf.SkipTaskbar != prev.SkipTaskbar {
+		w.config.SkipTaskbar = cnf.SkipTaskbar
+		// The Dock icon is hidden while every window skips the
+		// taskbar.
+		accessory := cnf.SkipTaskbar
+		for _, w := range viewMap {
+			accessory = accessory && w.config.SkipTaskbar
+		}
+		skip, policy := C.int(C.NO), C.int(C.NO)
+		if cnf.SkipTaskbar {
+			skip = C.YES
+		}
+		if accessory {
+			policy = C.YES
+		}
+		C.setSkipTaskbar(window, skip, policy)
+	}
+	if cnf.Hidden != prev.Hidden {
+		w.config.Hidden = cnf.Hidden
+		if cnf.Hidden {
+			C.orderOutWindow(window)
+			w.setStage(system.StagePaused)
+		} else {
+			C.raiseWindow(window)
+			w.setStage(system.StageRunning)
+		}
+	}
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
@@ -434,14 +980,97 @@ func (w *window) Perform(acts system.Action) {
 		}
 	})
 	if acts&system.ActionClose != 0 {
+		w.closing = true
 		C.closeWindow(window)
 	}
 }
 
 func (w *window) SetCursor(cursor pointer.Cursor) {
+	if w.customCursor != 0 {
+		C.setCursor(w.customCursor)
+		return
+	}
 	w.cursor = windowSetCursor(w.cursor, cursor)
 }
 
+func (w *window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	if w.customCursor != 0 {
+		C.setCursor(0)
+		C.CFRelease(w.customCursor)
+		w.customCursor = 0
+	}
+	if img == nil {
+		return true
+	}
+	size := img.Bounds().Size()
+	pix := make([]byte, size.X*size.Y*4)
+	for y := 0; y < size.Y; y++ {
+		copy(pix[y*size.X*4:], img.Pix[y*img.Stride:y*img.Stride+size.X*4])
+	}
+	c := C.createCursor(unsafe.Pointer(&pix[0]), C.int(size.X), C.int(size.Y), C.int(hotspot.X), C.int(hotspot.Y), C.CGFloat(w.scale))
+	if c == 0 {
+		return false
+	}
+	w.customCursor = c
+	w.cursor = windowSetCursor(w.cursor, pointer.CursorDefault)
+	C.setCursor(c)
+	return true
+}
+
+func (w *window) SetRelativeMouse(enable bool) {
+	if enable == w.relMouse {
+		return
+	}
+	w.relMouse = enable
+	if enable {
+		var x, y C.CGFloat
+		C.getMouseLocation(w.view, &x, &y)
+		w.relAnchor = f32.Point{X: float32(x) * w.scale, Y: float32(y) * w.scale}
+	}
+	// The cursor doesn't move while detached, so it is
+	// restored to the anchor position automatically.
+	C.setMouseDetached(C.bool(enable))
+}
+
+func (w *window) SetCursorConfined(confine bool) {}
+
+func (w *window) SetInputShape(shape []image.Rectangle) {
+	w.inputShape = shape
+	shaped := C.int(0)
+	if shape != nil {
+		shaped = 1
+	}
+	C.gio_setInputShaped(w.view, shaped)
+}
+
+//export gio_hitTest
+func gio_hitTest(view C.CFTypeRef, x, y C.CGFloat) C.int {
+	w := mustView(view)
+	if w.inputShape == nil {
+		return 1
+	}
+	p := image.Pt(int(float32(x)*w.scale), int(float32(y)*w.scale))
+	for _, r := range w.inputShape {
+		if p.In(r) {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (w *window) SetLayoutDirection(dir system.TextDirection) {
+	rtl := C.int(C.NO)
+	if dir == system.RTL {
+		rtl = C.YES
+	}
+	C.setLayoutDirection(w.view, rtl)
+}
+
+func (w *window) SetCursorPos(pos image.Point) {
+	scale := 1 / C.CGFloat(w.scale)
+	C.warpCursor(w.view, C.CGFloat(pos.X)*scale, C.CGFloat(pos.Y)*scale)
+}
+
 func (w *window) EditorStateChanged(old, new editorState) {
 	if old.Selection.Range != new.Selection.Range || old.Snippet != new.Snippet {
 		C.discardMarkedText(w.view)
@@ -454,7 +1083,7 @@ func (w *window) EditorStateChanged(old, new editorState) {
 
 func (w *window) ShowTextInput(show bool) {}
 
-func (w *window) SetInputHint(_ key.InputHint) {}
+func (w *window) SetInputHint(_ key.InputHint, _ key.InputOptions) {}
 
 func (w *window) SetAnimating(anim bool) {
 	if anim {
@@ -509,6 +1138,62 @@ func gio_onText(view, cstr C.CFTypeRef) {
 	w.w.EditorInsert(str)
 }
 
+func systemDoubleClick() (time.Duration, unit.Dp) {
+	t := time.Duration(float64(C.getDoubleClickInterval()) * float64(time.Second))
+	// There is no distance setting.
+	return t, defaultDoubleClickDistance
+}
+
+//export gio_onVisibility
+func gio_onVisibility(view C.CFTypeRef, visible C.int) {
+	w := mustView(view)
+	var e VisibilityEvent
+	if visible != 0 {
+		e.Visible = 1
+	}
+	w.w.Event(e)
+}
+
+//export gio_acceptsFirstMouse
+func gio_acceptsFirstMouse(view C.CFTypeRef) C.int {
+	if mustView(view).config.AcceptFirstMouse {
+		return C.YES
+	}
+	return C.NO
+}
+
+//export gio_onGesture
+func gio_onGesture(view C.CFTypeRef, kind C.int, x, y, dx, dy, rotation C.CGFloat, ti C.double) {
+	w := mustView(view)
+	e := GestureEvent{
+		Position: f32.Point{X: float32(x) * w.scale, Y: float32(y) * w.scale},
+		Time:     time.Duration(float64(ti)*float64(time.Second) + .5),
+	}
+	switch kind {
+	case C.GESTURE_SWIPE:
+		// The deltas are positive for swipes to the left and up.
+		switch {
+		case dx > 0:
+			e.Kind = GestureSwipeLeft
+		case dx < 0:
+			e.Kind = GestureSwipeRight
+		case dy > 0:
+			e.Kind = GestureSwipeUp
+		case dy < 0:
+			e.Kind = GestureSwipeDown
+		default:
+			return
+		}
+	case C.GESTURE_ROTATE:
+		// The rotation is counterclockwise in degrees.
+		e.Kind = GestureRotate
+		e.Angle = -float32(rotation) * math.Pi / 180
+	case C.GESTURE_SMART_ZOOM:
+		e.Kind = GestureSmartZoom
+	}
+	w.w.Event(e)
+}
+
 //export gio_onMouse
 func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx, dy C.CGFloat, ti C.double, mods C.NSUInteger) {
 	w := mustView(view)
@@ -516,6 +1201,10 @@ func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx,
 	xf, yf := float32(x)*w.scale, float32(y)*w.scale
 	dxf, dyf := float32(dx)*w.scale, float32(dy)*w.scale
 	pos := f32.Point{X: xf, Y: yf}
+	if w.relMouse {
+		pos = w.relAnchor
+	}
+	var scroll, delta f32.Point
 	var btn pointer.Buttons
 	switch cbtn {
 	case 0:
@@ -527,6 +1216,9 @@ func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx,
 	switch cdir {
 	case C.MOUSE_MOVE:
 		typ = pointer.Move
+		if w.relMouse {
+			delta = f32.Point{X: dxf, Y: dyf}
+		}
 	case C.MOUSE_UP:
 		typ = pointer.Release
 		w.pointerBtns &^= btn
@@ -543,6 +1235,7 @@ func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx,
 		}
 	case C.MOUSE_SCROLL:
 		typ = pointer.Scroll
+		scroll = f32.Point{X: dxf, Y: dyf}
 	default:
 		panic("invalid direction")
 	}
@@ -552,7 +1245,8 @@ func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx,
 		Time:      t,
 		Buttons:   w.pointerBtns,
 		Position:  pos,
-		Scroll:    f32.Point{X: dxf, Y: dyf},
+		Scroll:    scroll,
+		Delta:     delta,
 		Modifiers: convertMods(mods),
 	})
 }
@@ -563,6 +1257,134 @@ func gio_onDraw(view C.CFTypeRef) {
 	w.draw()
 }
 
+func (w *window) lookupSemantic(id C.uint64_t) (router.SemanticNode, bool) {
+	return w.w.LookupSemantic(router.SemanticID(id))
+}
+
+//export gio_semanticRoot
+func gio_semanticRoot(view C.CFTypeRef) C.uint64_t {
+	w := mustView(view)
+	return C.uint64_t(w.w.SemanticRoot())
+}
+
+//export gio_semanticParent
+func gio_semanticParent(view C.CFTypeRef, id C.uint64_t) C.uint64_t {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	return C.uint64_t(n.ParentID)
+}
+
+//export gio_semanticChildCount
+func gio_semanticChildCount(view C.CFTypeRef, id C.uint64_t) C.int {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	return C.int(len(n.Children))
+}
+
+//export gio_semanticChild
+func gio_semanticChild(view C.CFTypeRef, id C.uint64_t, idx C.int) C.uint64_t {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	return C.uint64_t(n.Children[idx].ID)
+}
+
+// gio_semanticRole returns the role of a semantic node, or -1 if the
+// node no longer exists.
+//
+//export gio_semanticRole
+func gio_semanticRole(view C.CFTypeRef, id C.uint64_t) C.int {
+	w := mustView(view)
+	n, found := w.lookupSemantic(id)
+	if !found {
+		return -1
+	}
+	switch n.Desc.Class {
+	case semantic.Button:
+		return C.ROLE_BUTTON
+	case semantic.CheckBox, semantic.Switch:
+		return C.ROLE_CHECK_BOX
+	case semantic.RadioButton:
+		return C.ROLE_RADIO_BUTTON
+	case semantic.Editor:
+		return C.ROLE_TEXT_FIELD
+	}
+	if n.Desc.Label != "" {
+		return C.ROLE_STATIC_TEXT
+	}
+	return C.ROLE_GROUP
+}
+
+//export gio_semanticLabel
+func gio_semanticLabel(view C.CFTypeRef, id C.uint64_t) C.CFTypeRef {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	return stringToNSString(n.Desc.Label)
+}
+
+//export gio_semanticDescription
+func gio_semanticDescription(view C.CFTypeRef, id C.uint64_t) C.CFTypeRef {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	return stringToNSString(n.Desc.Description)
+}
+
+//export gio_semanticState
+func gio_semanticState(view C.CFTypeRef, id C.uint64_t) C.int {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	var state C.int
+	if n.Desc.Selected {
+		state |= C.SEMANTIC_SELECTED
+	}
+	if n.Desc.Disabled {
+		state |= C.SEMANTIC_DISABLED
+	}
+	return state
+}
+
+// gio_semanticBounds returns the bounds of a semantic node in view
+// coordinates.
+//
+//export gio_semanticBounds
+func gio_semanticBounds(view C.CFTypeRef, id C.uint64_t) C.NSRect {
+	w := mustView(view)
+	n, _ := w.lookupSemantic(id)
+	b := n.Desc.Bounds
+	scale := 1 / C.CGFloat(w.scale)
+	height := C.viewHeight(w.view)
+	return C.NSMakeRect(
+		C.CGFloat(b.Min.X)*scale, height-C.CGFloat(b.Max.Y)*scale,
+		C.CGFloat(b.Dx())*scale, C.CGFloat(b.Dy())*scale,
+	)
+}
+
+// gio_semanticPress clicks a semantic node and reports whether the node
+// supports clicks.
+//
+//export gio_semanticPress
+func gio_semanticPress(view C.CFTypeRef, id C.uint64_t) C.int {
+	w := mustView(view)
+	n, found := w.lookupSemantic(id)
+	if !found || n.Desc.Gestures&router.ClickGesture == 0 {
+		return 0
+	}
+	b := n.Desc.Bounds
+	pos := f32.Pt(float32(b.Min.X+b.Max.X)/2, float32(b.Min.Y+b.Max.Y)/2)
+	for _, typ := range []pointer.Type{pointer.Press, pointer.Release} {
+		btns := pointer.ButtonPrimary
+		if typ == pointer.Release {
+			btns = 0
+		}
+		w.w.Event(pointer.Event{
+			Type:     typ,
+			Source:   pointer.Mouse,
+			Buttons:  btns,
+			Position: pos,
+		})
+	}
+	return 1
+}
+
 //export gio_onFocus
 func gio_onFocus(view C.CFTypeRef, focus C.int) {
 	w := mustView(view)
@@ -577,10 +1399,20 @@ func gio_onFocus(view C.CFTypeRef, focus C.int) {
 	w.SetCursor(w.cursor)
 }
 
+func (w *window) updateWorkArea() {
+	r := C.getViewWorkArea(w.view)
+	scale := float64(C.getViewBackingScale(w.view))
+	x, y := float64(r.origin.x)*scale, float64(r.origin.y)*scale
+	width, height := float64(r.size.width)*scale, float64(r.size.height)*scale
+	w.w.SetWorkArea(image.Rect(int(x), int(y), int(x+width), int(y+height)))
+}
+
 //export gio_onChangeScreen
 func gio_onChangeScreen(view C.CFTypeRef, did uint64) {
 	w := mustView(view)
 	w.displayLink.SetDisplayID(did)
+	w.w.SetRefreshRate(float64(C.getViewRefreshRate(w.view)))
+	w.updateWorkArea()
 	C.setNeedsDisplay(w.view)
 }
 
@@ -775,6 +1607,12 @@ func (w *window) draw() {
 		},
 		Sync: true,
 	})
+	if C.isVoiceOverEnabled() != 0 {
+		w.semanticDiffs = w.w.AppendSemanticDiffs(w.semanticDiffs[:0])
+		if len(w.semanticDiffs) > 0 {
+			C.semanticsChanged(w.view)
+		}
+	}
 }
 
 func configFor(scale float32) unit.Metric {
@@ -784,6 +1622,17 @@ func configFor(scale float32) unit.Metric {
 	}
 }
 
+//export gio_shouldClose
+func gio_shouldClose(view C.CFTypeRef) C.int {
+	w := mustView(view)
+	if !w.config.DocumentEdited || w.closing || !w.w.WantsCloseRequest() {
+		return C.YES
+	}
+	// Let the program confirm the close.
+	w.w.Event(CloseRequestEvent{})
+	return C.NO
+}
+
 //export gio_onClose
 func gio_onClose(view C.CFTypeRef) {
 	w := mustView(view)
@@ -805,7 +1654,9 @@ func gio_onHide(view C.CFTypeRef) {
 //export gio_onShow
 func gio_onShow(view C.CFTypeRef) {
 	w := mustView(view)
-	w.setStage(system.StageRunning)
+	if !w.config.Hidden {
+		w.setStage(system.StageRunning)
+	}
 }
 
 //export gio_onFullscreen
@@ -829,10 +1680,28 @@ func gio_onAppHide() {
 	}
 }
 
+//export gio_onTerminate
+func gio_onTerminate() {
+	for _, w := range viewMap {
+		w.w.Event(SaveStateEvent{})
+	}
+}
+
+//export gio_onOpenURL
+func gio_onOpenURL(str C.CFTypeRef) {
+	u, err := url.Parse(nsstringToString(str))
+	if err != nil {
+		return
+	}
+	deliverOpens([]event.Event{openURLEvent(u)})
+}
+
 //export gio_onAppShow
 func gio_onAppShow() {
 	for _, w := range viewMap {
-		w.setStage(system.StageRunning)
+		if !w.config.Hidden {
+			w.setStage(system.StageRunning)
+		}
 	}
 }
 
@@ -855,15 +1724,25 @@ func newWindow(win *callbacks, options []Option) error {
 		window := C.gio_createWindow(w.view, 0, 0, 0, 0, 0, 0)
 		w.updateWindowMode()
 		win.SetDriver(w)
+		w.w.SetRefreshRate(float64(C.getViewRefreshRate(w.view)))
+		w.updateWorkArea()
 		w.Configure(options)
+		var cnf Config
+		cnf.apply(unit.Metric{}, options)
+		if c := cnf.SplashColor; c.A != 0 && w.config.Material == MaterialNone {
+			// The window background shows until the view has content.
+			C.setSplashColor(window, C.CGFloat(c.R)/255, C.CGFloat(c.G)/255, C.CGFloat(c.B)/255)
+		}
 		if nextTopLeft.x == 0 && nextTopLeft.y == 0 {
 			// cascadeTopLeftFromPoint treats (0, 0) as a no-op,
 			// and just returns the offset we need for the first window.
 			nextTopLeft = C.cascadeTopLeftFromPoint(window, nextTopLeft)
 		}
 		nextTopLeft = C.cascadeTopLeftFromPoint(window, nextTopLeft)
-		// makeKeyAndOrderFront assumes ownership of our window reference.
-		C.makeKeyAndOrderFront(window)
+		if !w.config.Hidden {
+			// makeKeyAndOrderFront assumes ownership of our window reference.
+			C.makeKeyAndOrderFront(window)
+		}
 		layer := C.layerForView(w.view)
 		w.w.Event(ViewEvent{View: uintptr(w.view), Layer: uintptr(layer)})
 	})
@@ -904,6 +1783,16 @@ func osMain() {
 	C.gio_main()
 }
 
+func osRun(done <-chan struct{}) {
+	go func() {
+		<-done
+		runOnMain(func() {
+			C.gio_stop()
+		})
+	}()
+	C.gio_main()
+}
+
 func convertKey(k rune) (string, bool) {
 	var n string
 	switch k {
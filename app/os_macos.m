@@ -31,12 +31,20 @@ __attribute__ ((visibility ("hidden"))) CALayer *gio_layerFactory(void);
 	NSWindow *window = (NSWindow *)[notification object];
 	gio_onWindowed((__bridge CFTypeRef)window.contentView);
 }
+- (BOOL)windowShouldClose:(NSWindow *)window {
+	return gio_shouldClose((__bridge CFTypeRef)window.contentView) ? YES : NO;
+}
 - (void)windowDidChangeScreen:(NSNotification *)notification {
 	NSWindow *window = (NSWindow *)[notification object];
 	CGDirectDisplayID dispID = [[[window screen] deviceDescription][@"NSScreenNumber"] unsignedIntValue];
 	CFTypeRef view = (__bridge CFTypeRef)window.contentView;
 	gio_onChangeScreen(view, dispID);
 }
+- (void)windowDidChangeOcclusionState:(NSNotification *)notification {
+	NSWindow *window = (NSWindow *)[notification object];
+	int visible = (window.occlusionState & NSWindowOcclusionStateVisible) != 0;
+	gio_onVisibility((__bridge CFTypeRef)window.contentView, visible);
+}
 - (void)windowDidBecomeKey:(NSNotification *)notification {
 	NSWindow *window = (NSWindow *)[notification object];
 	gio_onFocus((__bridge CFTypeRef)window.contentView, 1);
@@ -59,10 +67,198 @@ static void handleMouse(NSView *view, NSEvent *event, int typ, CGFloat dx, CGFlo
 	gio_onMouse((__bridge CFTypeRef)view, (__bridge CFTypeRef)event, typ, event.buttonNumber, p.x, height - p.y, dx, dy, [event timestamp], [event modifierFlags]);
 }
 
-@interface GioView : NSView <CALayerDelegate,NSTextInputClient>
+static void handleGesture(NSView *view, NSEvent *event, int kind) {
+	NSPoint p = [view convertPoint:[event locationInWindow] fromView:nil];
+	CGFloat height = view.bounds.size.height;
+	CGFloat dx = 0, dy = 0, rotation = 0;
+	switch (kind) {
+	case GESTURE_SWIPE:
+		dx = event.deltaX;
+		dy = event.deltaY;
+		break;
+	case GESTURE_ROTATE:
+		rotation = event.rotation;
+		break;
+	}
+	gio_onGesture((__bridge CFTypeRef)view, kind, p.x, height - p.y, dx, dy, rotation, [event timestamp]);
+}
+
+@interface GioAccessibilityElement : NSAccessibilityElement
+@property (nonatomic, weak) NSView *gioView;
+@property (nonatomic) uint64_t semID;
+@end
+
+@interface GioView : NSView <CALayerDelegate,NSTextInputClient,NSDraggingSource>
+- (id)accessibilityElementFor:(uint64_t)semID;
+- (NSArray *)accessibilityChildrenOf:(uint64_t)semID;
+@end
+
+@implementation GioAccessibilityElement
+- (CFTypeRef)viewRef {
+	return (__bridge CFTypeRef)self.gioView;
+}
+- (id)accessibilityParent {
+	uint64_t parent = gio_semanticParent([self viewRef], self.semID);
+	return [(GioView *)self.gioView accessibilityElementFor:parent];
+}
+- (NSArray *)accessibilityChildren {
+	return [(GioView *)self.gioView accessibilityChildrenOf:self.semID];
+}
+- (BOOL)isAccessibilityElement {
+	return gio_semanticRole([self viewRef], self.semID) > ROLE_GROUP;
+}
+- (NSAccessibilityRole)accessibilityRole {
+	switch (gio_semanticRole([self viewRef], self.semID)) {
+	case ROLE_STATIC_TEXT:
+		return NSAccessibilityStaticTextRole;
+	case ROLE_BUTTON:
+		return NSAccessibilityButtonRole;
+	case ROLE_CHECK_BOX:
+		return NSAccessibilityCheckBoxRole;
+	case ROLE_RADIO_BUTTON:
+		return NSAccessibilityRadioButtonRole;
+	case ROLE_TEXT_FIELD:
+		return NSAccessibilityTextFieldRole;
+	default:
+		return NSAccessibilityGroupRole;
+	}
+}
+- (NSString *)accessibilityLabel {
+	if (gio_semanticRole([self viewRef], self.semID) == ROLE_TEXT_FIELD) {
+		// The label of an editor is its content.
+		return CFBridgingRelease(gio_semanticDescription([self viewRef], self.semID));
+	}
+	return CFBridgingRelease(gio_semanticLabel([self viewRef], self.semID));
+}
+- (NSString *)accessibilityHelp {
+	if (gio_semanticRole([self viewRef], self.semID) == ROLE_TEXT_FIELD) {
+		return nil;
+	}
+	return CFBridgingRelease(gio_semanticDescription([self viewRef], self.semID));
+}
+- (id)accessibilityValue {
+	switch (gio_semanticRole([self viewRef], self.semID)) {
+	case ROLE_TEXT_FIELD:
+		return CFBridgingRelease(gio_semanticLabel([self viewRef], self.semID));
+	case ROLE_CHECK_BOX:
+	case ROLE_RADIO_BUTTON:
+		return @((gio_semanticState([self viewRef], self.semID) & SEMANTIC_SELECTED) != 0);
+	default:
+		return nil;
+	}
+}
+- (BOOL)isAccessibilityEnabled {
+	return (gio_semanticState([self viewRef], self.semID) & SEMANTIC_DISABLED) == 0;
+}
+- (NSRect)accessibilityFrame {
+	NSRect r = gio_semanticBounds([self viewRef], self.semID);
+	return NSAccessibilityFrameInView(self.gioView, r);
+}
+- (BOOL)accessibilityPerformPress {
+	return gio_semanticPress([self viewRef], self.semID) != 0;
+}
 @end
 
-@implementation GioView
+@implementation GioView {
+	// a11yElements maps semantic IDs to their accessibility elements.
+	NSMutableDictionary<NSNumber *, GioAccessibilityElement *> *a11yElements;
+	// dragEvent is the most recent mouse event that can start a drag.
+	NSEvent *dragEvent;
+	// shapeMonitors are the event monitors that track the mouse while
+	// the window has an input shape.
+	NSArray *shapeMonitors;
+}
+// setInputShaped starts or stops letting mouse events outside the
+// input shape pass through the window. A window that ignores mouse
+// events doesn't receive them, so the mouse is tracked by monitors
+// of the events of every application.
+- (void)setInputShaped:(BOOL)shaped {
+	for (id m in shapeMonitors) {
+		[NSEvent removeMonitor:m];
+	}
+	shapeMonitors = nil;
+	self.window.ignoresMouseEvents = NO;
+	if (!shaped) {
+		return;
+	}
+	NSEventMask mask = NSEventMaskMouseMoved|NSEventMaskLeftMouseDragged|NSEventMaskRightMouseDragged|NSEventMaskOtherMouseDragged;
+	__weak GioView *view = self;
+	id global = [NSEvent addGlobalMonitorForEventsMatchingMask:mask handler:^(NSEvent *event) {
+		[view updateIgnoresMouseEvents];
+	}];
+	id local = [NSEvent addLocalMonitorForEventsMatchingMask:mask handler:^NSEvent *(NSEvent *event) {
+		[view updateIgnoresMouseEvents];
+		return event;
+	}];
+	shapeMonitors = @[global, local];
+	[self updateIgnoresMouseEvents];
+}
+- (void)updateIgnoresMouseEvents {
+	NSWindow *window = self.window;
+	if (window == nil) {
+		return;
+	}
+	NSPoint p = [window convertPointFromScreen:[NSEvent mouseLocation]];
+	p = [self convertPoint:p fromView:nil];
+	window.ignoresMouseEvents = gio_hitTest((__bridge CFTypeRef)self, p.x, self.bounds.size.height - p.y) ? NO : YES;
+}
+- (BOOL)startDragWithWriters:(NSArray *)writers {
+	if (dragEvent == nil) {
+		return NO;
+	}
+	NSPoint p = [self convertPoint:[dragEvent locationInWindow] fromView:nil];
+	NSMutableArray *items = [NSMutableArray arrayWithCapacity:writers.count];
+	for (id<NSPasteboardWriting> writer in writers) {
+		NSDraggingItem *item = [[NSDraggingItem alloc] initWithPasteboardWriter:writer];
+		[item setDraggingFrame:NSMakeRect(p.x, p.y, 1, 1) contents:nil];
+		[items addObject:item];
+	}
+	[self beginDraggingSessionWithItems:items event:dragEvent source:self];
+	dragEvent = nil;
+	return YES;
+}
+- (NSDragOperation)draggingSession:(NSDraggingSession *)session sourceOperationMaskForDraggingContext:(NSDraggingContext)context {
+	return NSDragOperationCopy | NSDragOperationMove;
+}
+- (void)draggingSession:(NSDraggingSession *)session endedAtPoint:(NSPoint)screenPoint operation:(NSDragOperation)operation {
+	gio_onDragEnd((__bridge CFTypeRef)self, operation);
+}
+- (id)accessibilityElementFor:(uint64_t)semID {
+	CFTypeRef viewRef = (__bridge CFTypeRef)self;
+	if (semID == 0 || semID == gio_semanticRoot(viewRef)) {
+		return self;
+	}
+	if (a11yElements == nil) {
+		a11yElements = [NSMutableDictionary dictionary];
+	}
+	NSNumber *key = @(semID);
+	GioAccessibilityElement *e = a11yElements[key];
+	if (e == nil) {
+		e = [[GioAccessibilityElement alloc] init];
+		e.gioView = self;
+		e.semID = semID;
+		a11yElements[key] = e;
+	}
+	return e;
+}
+- (NSArray *)accessibilityChildrenOf:(uint64_t)semID {
+	CFTypeRef viewRef = (__bridge CFTypeRef)self;
+	// Drop elements of removed nodes.
+	for (NSNumber *key in [a11yElements allKeys]) {
+		if (gio_semanticRole(viewRef, key.unsignedLongLongValue) < 0) {
+			[a11yElements removeObjectForKey:key];
+		}
+	}
+	int n = gio_semanticChildCount(viewRef, semID);
+	NSMutableArray *children = [NSMutableArray arrayWithCapacity:n];
+	for (int i = 0; i < n; i++) {
+		[children addObject:[self accessibilityElementFor:gio_semanticChild(viewRef, semID, i)]];
+	}
+	return children;
+}
+- (NSArray *)accessibilityChildren {
+	return [self accessibilityChildrenOf:gio_semanticRoot((__bridge CFTypeRef)self)];
+}
 - (void)setFrameSize:(NSSize)newSize {
 	[super setFrameSize:newSize];
 	[self setNeedsDisplay:YES];
@@ -83,13 +279,26 @@ static void handleMouse(NSView *view, NSEvent *event, int typ, CGFloat dx, CGFlo
 }
 - (void)viewDidMoveToWindow {
 	if (self.window == nil) {
+		[self setInputShaped:NO];
 		gio_onClose((__bridge CFTypeRef)self);
 	}
 }
+- (BOOL)acceptsFirstMouse:(NSEvent *)event {
+	return gio_acceptsFirstMouse((__bridge CFTypeRef)self) ? YES : NO;
+}
+- (NSView *)hitTest:(NSPoint)point {
+	NSPoint p = [self convertPoint:point fromView:self.superview];
+	if (!gio_hitTest((__bridge CFTypeRef)self, p.x, self.bounds.size.height - p.y)) {
+		return nil;
+	}
+	return [super hitTest:point];
+}
 - (void)mouseDown:(NSEvent *)event {
+	dragEvent = event;
 	handleMouse(self, event, MOUSE_DOWN, 0, 0);
 }
 - (void)mouseUp:(NSEvent *)event {
+	dragEvent = nil;
 	handleMouse(self, event, MOUSE_UP, 0, 0);
 }
 - (void)middleMouseDown:(NSEvent *)event {
@@ -105,16 +314,26 @@ static void handleMouse(NSView *view, NSEvent *event, int typ, CGFloat dx, CGFlo
 	handleMouse(self, event, MOUSE_UP, 0, 0);
 }
 - (void)mouseMoved:(NSEvent *)event {
-	handleMouse(self, event, MOUSE_MOVE, 0, 0);
+	handleMouse(self, event, MOUSE_MOVE, event.deltaX, event.deltaY);
 }
 - (void)mouseDragged:(NSEvent *)event {
-	handleMouse(self, event, MOUSE_MOVE, 0, 0);
+	dragEvent = event;
+	handleMouse(self, event, MOUSE_MOVE, event.deltaX, event.deltaY);
 }
 - (void)scrollWheel:(NSEvent *)event {
 	CGFloat dx = -event.scrollingDeltaX;
 	CGFloat dy = -event.scrollingDeltaY;
 	handleMouse(self, event, MOUSE_SCROLL, dx, dy);
 }
+- (void)swipeWithEvent:(NSEvent *)event {
+	handleGesture(self, event, GESTURE_SWIPE);
+}
+- (void)rotateWithEvent:(NSEvent *)event {
+	handleGesture(self, event, GESTURE_ROTATE);
+}
+- (void)smartMagnifyWithEvent:(NSEvent *)event {
+	handleGesture(self, event, GESTURE_SMART_ZOOM);
+}
 - (void)keyDown:(NSEvent *)event {
 	[self interpretKeyEvents:[NSArray arrayWithObject:event]];
 	NSString *keys = [event charactersIgnoringModifiers];
@@ -362,6 +581,24 @@ CFTypeRef gio_createWindow(CFTypeRef viewRef, CGFloat width, CGFloat height, CGF
 	}
 }
 
+int gio_startDrag(CFTypeRef viewRef, CFTypeRef *writers, int n) {
+	@autoreleasepool {
+		GioView *view = (__bridge GioView *)viewRef;
+		NSMutableArray *objs = [NSMutableArray arrayWithCapacity:n];
+		for (int i = 0; i < n; i++) {
+			[objs addObject:(__bridge id)writers[i]];
+		}
+		return [view startDragWithWriters:objs] ? 1 : 0;
+	}
+}
+
+void gio_setInputShaped(CFTypeRef viewRef, int shaped) {
+	@autoreleasepool {
+		GioView *view = (__bridge GioView *)viewRef;
+		[view setInputShaped:shaped ? YES : NO];
+	}
+}
+
 CFTypeRef gio_createView(void) {
 	@autoreleasepool {
 		NSRect frame = NSMakeRect(0, 0, 0, 0);
@@ -384,6 +621,14 @@ CFTypeRef gio_createView(void) {
 - (void)applicationWillUnhide:(NSNotification *)notification {
 	gio_onAppShow();
 }
+- (void)applicationWillTerminate:(NSNotification *)notification {
+	gio_onTerminate();
+}
+- (void)application:(NSApplication *)application openURLs:(NSArray<NSURL *> *)urls {
+	for (NSURL *url in urls) {
+		gio_onOpenURL((__bridge CFTypeRef)url.absoluteString);
+	}
+}
 @end
 
 void gio_main() {
@@ -413,3 +658,18 @@ void gio_main() {
 		[NSApp run];
 	}
 }
+
+void gio_stop() {
+	[NSApp stop:nil];
+	// Post an event to make [NSApp run] notice the stop request.
+	NSEvent *ev = [NSEvent otherEventWithType:NSEventTypeApplicationDefined
+									 location:NSZeroPoint
+								modifierFlags:0
+									timestamp:0
+								 windowNumber:0
+									  context:nil
+									  subtype:0
+										data1:0
+										data2:0];
+	[NSApp postEvent:ev atStart:YES];
+}
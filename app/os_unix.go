@@ -7,9 +7,11 @@ package app
 
 import (
 	"errors"
+	"time"
 	"unsafe"
 
 	"gioui.org/io/pointer"
+	"gioui.org/unit"
 )
 
 // ViewEvent provides handles to the underlying window objects for the
@@ -43,6 +45,22 @@ func osMain() {
 	select {}
 }
 
+func systemLanguage() string {
+	return envLanguage()
+}
+
+func systemForcedColors() ForcedColors {
+	return ForcedColors{}
+}
+
+func systemDoubleClick() (time.Duration, unit.Dp) {
+	return defaultDoubleClickTime, defaultDoubleClickDistance
+}
+
+func osRun(done <-chan struct{}) {
+	<-done
+}
+
 type windowDriver func(*callbacks, []Option) error
 
 // Instead of creating files with build tags for each combination of wayland +/- x11
@@ -124,13 +124,24 @@ type wlSeat struct {
 	offers map[*C.struct_wl_data_offer][]string
 	// clipboard is the wl_data_offer for the clipboard.
 	clipboard *C.struct_wl_data_offer
-	// mimeType is the chosen mime type of clipboard.
+	// mimeType is the chosen text mime type of clipboard, or
+	// empty if the clipboard contains no text.
 	mimeType string
 	// source represents the clipboard content of the most recent
 	// clipboard write, if any.
 	source *C.struct_wl_data_source
 	// content is the data belonging to source.
 	content []byte
+
+	// drag tracks the drag operation started by StartDrag, if any.
+	drag struct {
+		source *C.struct_wl_data_source
+		data   []byte
+		window *window
+		// action is the most recent action selected by the
+		// compositor.
+		action C.uint32_t
+	}
 }
 
 type repeatState struct {
@@ -228,7 +239,9 @@ type wlOutput struct {
 	physHeight int
 	transform  C.int32_t
 	scale      int
-	windows    []*window
+	// refresh is the refresh rate in mHz.
+	refresh int
+	windows []*window
 }
 
 // callbackMap maps Wayland native handles to corresponding Go
@@ -261,7 +274,7 @@ func newWLWindow(callbacks *callbacks, options []Option) error {
 		return err
 	}
 	w.w = callbacks
-	go func() {
+	callbacks.Spawn(func() {
 		defer d.destroy()
 		defer w.destroy()
 
@@ -279,11 +292,17 @@ func newWLWindow(callbacks *callbacks, options []Option) error {
 		err := w.loop()
 		w.w.Event(WaylandViewEvent{})
 		w.w.Event(system.DestroyEvent{Err: err})
-	}()
+	})
 	return nil
 }
 
 func (d *wlDisplay) writeClipboard(content []byte) error {
+	return d.writeClipboardData(clipboardMimeTypes, content)
+}
+
+// writeClipboardData offers content to the clipboard as data of the
+// mime types.
+func (d *wlDisplay) writeClipboardData(mimeTypes []string, content []byte) error {
 	s := d.seat
 	if s == nil {
 		return nil
@@ -300,7 +319,7 @@ func (d *wlDisplay) writeClipboard(content []byte) error {
 	s.content = content
 	s.source = C.wl_data_device_manager_create_data_source(d.dataDeviceManager)
 	C.wl_data_source_add_listener(s.source, &C.gio_data_source_listener, unsafe.Pointer(s.seat))
-	for _, mime := range clipboardMimeTypes {
+	for _, mime := range mimeTypes {
 		C.wl_data_source_offer(s.source, C.CString(mime))
 	}
 	C.wl_data_device_set_selection(s.dataDev, s.source, s.serial)
@@ -312,7 +331,24 @@ func (d *wlDisplay) readClipboard() (io.ReadCloser, error) {
 	if s == nil {
 		return nil, nil
 	}
-	if s.clipboard == nil {
+	return d.readClipboardData(s.mimeType)
+}
+
+// readClipboardData returns a reader of the clipboard content of a
+// mime type, or nil if the clipboard has no content of the type.
+func (d *wlDisplay) readClipboardData(mime string) (io.ReadCloser, error) {
+	s := d.seat
+	if s == nil || s.clipboard == nil || mime == "" {
+		return nil, nil
+	}
+	offered := false
+	for _, t := range s.offers[s.clipboard] {
+		if t == mime {
+			offered = true
+			break
+		}
+	}
+	if !offered {
 		return nil, nil
 	}
 	r, w, err := os.Pipe()
@@ -322,7 +358,7 @@ func (d *wlDisplay) readClipboard() (io.ReadCloser, error) {
 	// wl_data_offer_receive performs and implicit dup(2) of the write end
 	// of the pipe. Close our version.
 	defer w.Close()
-	cmimeType := C.CString(s.mimeType)
+	cmimeType := C.CString(mime)
 	defer C.free(unsafe.Pointer(cmimeType))
 	C.wl_data_offer_receive(s.clipboard, cmimeType, C.int(w.Fd()))
 	return r, nil
@@ -482,7 +518,22 @@ func (s *wlSeat) flushOffers() {
 	}
 }
 
+// finishDrag ends the current drag operation, if any, and
+// reports its result.
+func (s *wlSeat) finishDrag(res DragResult) {
+	if s.drag.source == nil {
+		return
+	}
+	C.wl_data_source_destroy(s.drag.source)
+	w := s.drag.window
+	s.drag.source = nil
+	s.drag.data = nil
+	s.drag.window = nil
+	w.w.Event(DragResultEvent{Result: res})
+}
+
 func (s *wlSeat) destroy() {
+	s.finishDrag(DragCanceled)
 	if s.source != nil {
 		C.wl_data_source_destroy(s.source)
 		s.source = nil
@@ -558,6 +609,11 @@ func gio_onXdgSurfaceConfigure(data unsafe.Pointer, wmSurf *C.struct_xdg_surface
 //export gio_onToplevelClose
 func gio_onToplevelClose(data unsafe.Pointer, topLvl *C.struct_xdg_toplevel) {
 	w := callbackLoad(data).(*window)
+	if w.config.DocumentEdited && w.w.WantsCloseRequest() {
+		// Let the program confirm the close.
+		w.w.Event(CloseRequestEvent{})
+		return
+	}
 	w.dead = true
 }
 
@@ -565,7 +621,17 @@ func gio_onToplevelClose(data unsafe.Pointer, topLvl *C.struct_xdg_toplevel) {
 func gio_onToplevelConfigure(data unsafe.Pointer, topLvl *C.struct_xdg_toplevel, width, height C.int32_t, states *C.struct_wl_array) {
 	w := callbackLoad(data).(*window)
 	if width != 0 && height != 0 {
-		w.size = image.Pt(int(width), int(height))
+		// There is no protocol for aspect ratios or resize
+		// increments, so snap the size instead.
+		deco := w.decoHeight()
+		size := image.Pt(int(width), int(height)-deco)
+		size = constrainAspect(size, w.config.AspectRatio)
+		if inc := w.config.ResizeIncrements; inc != (image.Point{}) {
+			// Increments are in pixels.
+			size = constrainIncrements(size.Mul(w.scale), inc).Div(w.scale)
+		}
+		size.Y += deco
+		w.size = size
 		w.updateOpaqueRegion()
 	}
 }
@@ -601,6 +667,7 @@ func gio_onOutputMode(data unsafe.Pointer, output *C.struct_wl_output, flags C.u
 	c := d.outputConfig[output]
 	c.width = int(width)
 	c.height = int(height)
+	c.refresh = int(refresh)
 }
 
 //export gio_onOutputGeometry
@@ -753,14 +820,15 @@ func gio_onDataDeviceDrop(data unsafe.Pointer, dataDev *C.struct_wl_data_device)
 func gio_onDataDeviceSelection(data unsafe.Pointer, dataDev *C.struct_wl_data_device, id *C.struct_wl_data_offer) {
 	s := callbackLoad(data).(*wlSeat)
 	defer s.flushOffers()
-	s.clipboard = nil
+	// Keep the offer for reads of any type.
+	s.clipboard = id
+	s.mimeType = ""
 loop:
 	for _, want := range clipboardMimeTypes {
 		for _, got := range s.offers[id] {
 			if want != got {
 				continue
 			}
-			s.clipboard = id
 			s.mimeType = got
 			break loop
 		}
@@ -1008,7 +1076,7 @@ func gio_onPointerAxisDiscrete(data unsafe.Pointer, p *C.struct_wl_pointer, axis
 	}
 }
 
-func (w *window) ReadClipboard() {
+func (w *window) ReadClipboard(clipboard.Selection) {
 	r, err := w.disp.readClipboard()
 	// Send empty responses on unavailable clipboards or errors.
 	if r == nil || err != nil {
@@ -1024,16 +1092,65 @@ func (w *window) ReadClipboard() {
 	}()
 }
 
-func (w *window) WriteClipboard(s string) {
+func (w *window) WriteClipboard(_ clipboard.Selection, s string) {
 	w.disp.writeClipboard([]byte(s))
 }
 
+func (w *window) ReadClipboardData(mime string) {
+	var types []string
+	if s := w.disp.seat; s != nil && s.clipboard != nil {
+		types = append(types, s.offers[s.clipboard]...)
+	}
+	r, err := w.disp.readClipboardData(mime)
+	if r == nil || err != nil {
+		w.w.Event(clipboard.Event{Type: mime, Types: types})
+		return
+	}
+	go func() {
+		defer r.Close()
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			data = nil
+		}
+		w.clipReads <- clipboard.Event{Type: mime, Data: data, Types: types}
+		w.Wakeup()
+	}()
+}
+
+func (w *window) WriteClipboardData(mime string, data []byte) {
+	w.disp.writeClipboardData([]string{mime}, data)
+}
+
+func (w *window) StartDrag(data []byte, types []string) {
+	d := w.disp
+	s := d.seat
+	if s == nil || s.dataDev == nil || d.dataDeviceManager == nil || len(types) == 0 {
+		w.w.Event(DragResultEvent{Result: DragCanceled})
+		return
+	}
+	s.finishDrag(DragCanceled)
+	src := C.wl_data_device_manager_create_data_source(d.dataDeviceManager)
+	C.wl_data_source_add_listener(src, &C.gio_data_source_listener, unsafe.Pointer(s.seat))
+	for _, mime := range types {
+		cmime := C.CString(mime)
+		C.wl_data_source_offer(src, cmime)
+		C.free(unsafe.Pointer(cmime))
+	}
+	C.wl_data_source_set_actions(src, C.WL_DATA_DEVICE_MANAGER_DND_ACTION_COPY|C.WL_DATA_DEVICE_MANAGER_DND_ACTION_MOVE)
+	s.drag.source = src
+	s.drag.data = data
+	s.drag.window = w
+	s.drag.action = 0
+	C.wl_data_device_start_drag(s.dataDev, src, w.surf, nil, s.serial)
+}
+
 func (w *window) Configure(options []Option) {
 	_, cfg := w.getConfig()
 	prev := w.config
 	cnf := w.config
 	cnf.apply(cfg, options)
 	w.config.decoHeight = cnf.decoHeight
+	w.config.DocumentEdited = cnf.DocumentEdited
 
 	switch cnf.Mode {
 	case Fullscreen:
@@ -1081,6 +1198,14 @@ func (w *window) Configure(options []Option) {
 		}
 		w.config.MinSize = cnf.MinSize
 		w.config.MaxSize = cnf.MaxSize
+		w.config.AspectRatio = cnf.AspectRatio
+		w.config.ResizeIncrements = cnf.ResizeIncrements
+		if prev.Resizable != cnf.Resizable {
+			// Clear the constraints of the previous state.
+			C.xdg_toplevel_set_min_size(w.topLvl, 0, 0)
+			C.xdg_toplevel_set_max_size(w.topLvl, 0, 0)
+		}
+		w.config.Resizable = cnf.Resizable
 		w.setWindowConstraints()
 	}
 	w.w.Event(ConfigEvent{Config: w.config})
@@ -1089,6 +1214,14 @@ func (w *window) Configure(options []Option) {
 
 func (w *window) setWindowConstraints() {
 	decoHeight := w.decoHeight()
+	if !w.config.Resizable {
+		// Compositors don't resize windows whose minimum and maximum
+		// sizes are equal.
+		size := w.config.Size.Div(w.scale)
+		C.xdg_toplevel_set_min_size(w.topLvl, C.int32_t(size.X), C.int32_t(size.Y))
+		C.xdg_toplevel_set_max_size(w.topLvl, C.int32_t(size.X), C.int32_t(size.Y))
+		return
+	}
 	if scaled := w.config.MinSize.Div(w.scale); scaled != (image.Point{}) {
 		C.xdg_toplevel_set_min_size(w.topLvl, C.int32_t(scaled.X), C.int32_t(scaled.Y+decoHeight))
 	}
@@ -1143,11 +1276,82 @@ func (w *window) resize(serial, edge C.uint32_t) {
 	C.xdg_toplevel_resize(w.topLvl, s.seat, serial, edge)
 }
 
+func (w *window) SetProgress(fraction float64, state ProgressState) {}
+
+func (w *window) StartMove() {
+	if s := w.seat; s != nil {
+		w.move(s.serial)
+	}
+}
+
+func (w *window) StartResize(edge ResizeEdge) {
+	s := w.seat
+	if s == nil {
+		return
+	}
+	var e C.uint32_t
+	switch edge {
+	case EdgeNorth:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_TOP
+	case EdgeSouth:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_BOTTOM
+	case EdgeWest:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_LEFT
+	case EdgeEast:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_RIGHT
+	case EdgeNorthWest:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_TOP_LEFT
+	case EdgeNorthEast:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_TOP_RIGHT
+	case EdgeSouthWest:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_BOTTOM_LEFT
+	case EdgeSouthEast:
+		e = C.XDG_TOPLEVEL_RESIZE_EDGE_BOTTOM_RIGHT
+	}
+	w.resize(s.serial, e)
+}
+
 func (w *window) SetCursor(cursor pointer.Cursor) {
 	w.cursor.cursor = w.loadCursor(cursor)
 	w.updateCursor()
 }
 
+func (w *window) SetRelativeMouse(enable bool) {
+	// Relative mode requires the relative-pointer and
+	// pointer-constraints protocols, which are not implemented.
+}
+
+func (w *window) SetCursorConfined(confine bool) {}
+
+func (w *window) SetInputShape(shape []image.Rectangle) {
+	if shape == nil {
+		// A nil region covers the whole surface.
+		C.wl_surface_set_input_region(w.surf, nil)
+	} else {
+		reg := C.wl_compositor_create_region(w.disp.compositor)
+		for _, r := range shape {
+			// Round outwards to surface coordinates.
+			min := r.Min.Div(w.scale)
+			max := r.Max.Add(image.Pt(w.scale-1, w.scale-1)).Div(w.scale)
+			C.wl_region_add(reg, C.int32_t(min.X), C.int32_t(min.Y), C.int32_t(max.X-min.X), C.int32_t(max.Y-min.Y))
+		}
+		C.wl_surface_set_input_region(w.surf, reg)
+		C.wl_region_destroy(reg)
+	}
+	// The region takes effect when the surface is committed.
+	w.redraw = true
+}
+
+func (w *window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	return false
+}
+
+func (w *window) SetLayoutDirection(dir system.TextDirection) {}
+
+func (w *window) SetCursorPos(pos image.Point) {
+	// Wayland clients can't move the cursor.
+}
+
 func (w *window) updateCursor() {
 	ptr := w.disp.seat.pointer
 	if ptr == nil {
@@ -1363,6 +1567,12 @@ func (w *window) loop() error {
 	return nil
 }
 
+func (w *window) ProcessEvents() {
+	// A roundtrip dispatches every event sent before the
+	// server received it. Redraws are left to the event loop.
+	C.wl_display_roundtrip(w.display())
+}
+
 // bindDataDevice initializes the dataDev field if and only if both
 // the seat and dataDeviceManager fields are initialized.
 func (d *wlDisplay) bindDataDevice() {
@@ -1515,6 +1725,9 @@ func gio_onDataSourceTarget(data unsafe.Pointer, source *C.struct_wl_data_source
 func gio_onDataSourceSend(data unsafe.Pointer, source *C.struct_wl_data_source, mime *C.char, fd C.int32_t) {
 	s := callbackLoad(data).(*wlSeat)
 	content := s.content
+	if source == s.drag.source {
+		content = s.drag.data
+	}
 	go func() {
 		defer syscall.Close(int(fd))
 		syscall.Write(int(fd), content)
@@ -1524,6 +1737,10 @@ func gio_onDataSourceSend(data unsafe.Pointer, source *C.struct_wl_data_source,
 //export gio_onDataSourceCancelled
 func gio_onDataSourceCancelled(data unsafe.Pointer, source *C.struct_wl_data_source) {
 	s := callbackLoad(data).(*wlSeat)
+	if source == s.drag.source {
+		s.finishDrag(DragCanceled)
+		return
+	}
 	if s.source == source {
 		s.content = nil
 		s.source = nil
@@ -1537,10 +1754,23 @@ func gio_onDataSourceDNDDropPerformed(data unsafe.Pointer, source *C.struct_wl_d
 
 //export gio_onDataSourceDNDFinished
 func gio_onDataSourceDNDFinished(data unsafe.Pointer, source *C.struct_wl_data_source) {
+	s := callbackLoad(data).(*wlSeat)
+	if source != s.drag.source {
+		return
+	}
+	res := DragCopied
+	if s.drag.action == C.WL_DATA_DEVICE_MANAGER_DND_ACTION_MOVE {
+		res = DragMoved
+	}
+	s.finishDrag(res)
 }
 
 //export gio_onDataSourceAction
 func gio_onDataSourceAction(data unsafe.Pointer, source *C.struct_wl_data_source, act C.uint32_t) {
+	s := callbackLoad(data).(*wlSeat)
+	if source == s.drag.source {
+		s.drag.action = act
+	}
 }
 
 func (w *window) flushScroll() {
@@ -1647,6 +1877,7 @@ func (w *window) updateOpaqueRegion() {
 
 func (w *window) updateOutputs() {
 	scale := 1
+	refresh := 0
 	var found bool
 	for _, conf := range w.disp.outputConfig {
 		for _, w2 := range conf.windows {
@@ -1655,9 +1886,13 @@ func (w *window) updateOutputs() {
 				if conf.scale > scale {
 					scale = conf.scale
 				}
+				if conf.refresh > refresh {
+					refresh = conf.refresh
+				}
 			}
 		}
 	}
+	w.w.SetRefreshRate(float64(refresh) / 1000)
 	if found && scale != w.scale {
 		w.scale = scale
 		C.wl_surface_set_buffer_scale(w.surf, C.int32_t(w.scale))
@@ -1731,7 +1966,7 @@ func (w *window) surface() (*C.struct_wl_surface, int, int) {
 
 func (w *window) ShowTextInput(show bool) {}
 
-func (w *window) SetInputHint(_ key.InputHint) {}
+func (w *window) SetInputHint(_ key.InputHint, _ key.InputOptions) {}
 
 func (w *window) EditorStateChanged(old, new editorState) {}
 
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
 	"io"
 	"log"
 	"os"
@@ -53,20 +54,53 @@ type window struct {
 	// to the most recent WM_SETCURSOR.
 	cursorIn bool
 	cursor   syscall.Handle
+	// customCursor is the cursor created by SetCustomCursor.
+	customCursor syscall.Handle
+
+	// taskbar is the taskbar list for SetProgress, if created.
+	taskbar *windows.TaskbarList3
+
+	// relMouse is set in relative mouse mode, and relAnchor is the
+	// cursor position when the mode was enabled.
+	relMouse  bool
+	relAnchor windows.Point
+	// confined is set by SetCursorConfined.
+	confined bool
+	// inputShape is the shape set by SetInputShape.
+	inputShape []image.Rectangle
+	// closing is set when the program closes the window.
+	closing bool
+	// painted is set after the first WM_PAINT. Until then, the client
+	// area is filled with the splash color.
+	painted bool
 
 	// placement saves the previous window position when in full screen mode.
 	placement *windows.WindowPlacement
 
 	animating bool
 	focused   bool
+	// sizeMove is set while the window is in the modal move or
+	// resize loop, during which loop doesn't run.
+	sizeMove bool
 
 	deltas     winDeltas
 	borderSize image.Point
 	config     Config
+
+	// ended is set when the window was destroyed because the user
+	// session ended.
+	ended bool
 }
 
 const _WM_WAKEUP = windows.WM_USER + iota
 
+// animTimer is the ID of the timer that drives animations while
+// the window is in the modal move or resize loop.
+const animTimer = 1
+
+// animInterval is the interval of animTimer, in milliseconds.
+const animInterval = 1000 / 60
+
 type gpuAPI struct {
 	priority    int
 	initializer func(w *window) (context, error)
@@ -95,9 +129,48 @@ func osMain() {
 	select {}
 }
 
+func systemLanguage() string {
+	return windows.GetUserDefaultLocaleName()
+}
+
+func systemDoubleClick() (time.Duration, unit.Dp) {
+	t := time.Duration(windows.GetDoubleClickTime()) * time.Millisecond
+	// The metric is the width in pixels of the rectangle around the
+	// first click.
+	px := windows.GetSystemMetrics(windows.SM_CXDOUBLECLK) / 2
+	dpi := windows.GetSystemDPI()
+	return t, unit.Dp(float32(px) * 96 / float32(dpi))
+}
+
+func systemForcedColors() ForcedColors {
+	hc, err := windows.GetHighContrast()
+	if err != nil || hc.Flags&windows.HCF_HIGHCONTRASTON == 0 {
+		return ForcedColors{}
+	}
+	sysColor := func(index int) color.NRGBA {
+		c := windows.GetSysColor(index)
+		return color.NRGBA{R: uint8(c), G: uint8(c >> 8), B: uint8(c >> 16), A: 0xff}
+	}
+	return ForcedColors{
+		Enabled:       true,
+		Canvas:        sysColor(windows.COLOR_WINDOW),
+		CanvasText:    sysColor(windows.COLOR_WINDOWTEXT),
+		LinkText:      sysColor(windows.COLOR_HOTLIGHT),
+		GrayText:      sysColor(windows.COLOR_GRAYTEXT),
+		Highlight:     sysColor(windows.COLOR_HIGHLIGHT),
+		HighlightText: sysColor(windows.COLOR_HIGHLIGHTTEXT),
+		ButtonFace:    sysColor(windows.COLOR_BTNFACE),
+		ButtonText:    sysColor(windows.COLOR_BTNTEXT),
+	}
+}
+
+func osRun(done <-chan struct{}) {
+	<-done
+}
+
 func newWindow(window *callbacks, options []Option) error {
 	cerr := make(chan error)
-	go func() {
+	window.Spawn(func() {
 		// GetMessage and PeekMessage can filter on a window HWND, but
 		// then thread-specific messages such as WM_QUIT are ignored.
 		// Instead lock the thread so window messages arrive through
@@ -113,23 +186,35 @@ func newWindow(window *callbacks, options []Option) error {
 		defer winMap.Delete(w.hwnd)
 		w.w = window
 		w.w.SetDriver(w)
+		w.updateRefreshRate()
+		w.updateWorkArea()
 		w.w.Event(ViewEvent{HWND: uintptr(w.hwnd)})
 		w.Configure(options)
-		windows.SetForegroundWindow(w.hwnd)
-		windows.SetFocus(w.hwnd)
+		if !w.config.Hidden {
+			windows.SetForegroundWindow(w.hwnd)
+			windows.SetFocus(w.hwnd)
+		}
 		// Since the window class for the cursor is null,
 		// set it here to show the cursor.
 		w.SetCursor(pointer.CursorDefault)
 		if err := w.loop(); err != nil {
 			panic(err)
 		}
-	}()
+	})
 	return <-cerr
 }
 
 // initResources initializes the resources global.
 func initResources() error {
 	windows.SetProcessDPIAware()
+	if customID {
+		// The AppUserModelID determines the task bar grouping and
+		// notification identity. Leave the system default unless
+		// an id was set.
+		if err := windows.SetCurrentProcessExplicitAppUserModelID(ID); err != nil {
+			return err
+		}
+	}
 	hInst, err := windows.GetModuleHandle()
 	if err != nil {
 		return err
@@ -194,6 +279,15 @@ func createNativeWindow() (*window, error) {
 	return w, nil
 }
 
+func (w *window) updateRefreshRate() {
+	w.w.SetRefreshRate(float64(windows.GetRefreshRate(w.hdc)))
+}
+
+func (w *window) updateWorkArea() {
+	r := windows.GetMonitorInfo(w.hwnd).WorkArea
+	w.w.SetWorkArea(image.Rect(int(r.Left), int(r.Top), int(r.Right), int(r.Bottom)))
+}
+
 // update() handles changes done by the user, and updates the configuration.
 // It reads the window style and size/position and updates w.config.
 // If anything has changed it emits a ConfigEvent to notify the application.
@@ -245,8 +339,27 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 		// The message is processed.
 		return windows.TRUE
 	case windows.WM_DPICHANGED:
+		// The window may have moved to another display.
+		w.updateRefreshRate()
+		w.updateWorkArea()
 		// Let Windows know we're prepared for runtime DPI changes.
 		return windows.TRUE
+	case windows.WM_DISPLAYCHANGE:
+		w.updateRefreshRate()
+		w.updateWorkArea()
+	case windows.WM_SETTINGCHANGE:
+		if wParam == windows.SPI_SETWORKAREA {
+			w.updateWorkArea()
+		}
+		// The "intl" setting covers the user's locale.
+		if windows.SettingName(lParam) == "intl" {
+			w.w.Event(LocaleEvent{})
+		}
+		if wParam == windows.SPI_SETHIGHCONTRAST {
+			w.w.Event(ForcedColorsEvent{})
+		}
+	case windows.WM_SYSCOLORCHANGE:
+		w.w.Event(ForcedColorsEvent{})
 	case windows.WM_ERASEBKGND:
 		// Avoid flickering between GPU content and background color.
 		return windows.TRUE
@@ -287,10 +400,22 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 		})
 	case windows.WM_SETFOCUS:
 		w.focused = true
+		if w.relMouse || w.confined {
+			// The cursor clip is reset when the focus changes.
+			w.lockCursor()
+		}
 		w.w.Event(key.FocusEvent{Focus: true})
 	case windows.WM_KILLFOCUS:
 		w.focused = false
+		if w.confined {
+			windows.ClipCursor(nil)
+		}
 		w.w.Event(key.FocusEvent{Focus: false})
+	case windows.WM_WINDOWPOSCHANGED:
+		if w.confined && w.focused {
+			// Follow the client area.
+			w.lockCursor()
+		}
 	case windows.WM_NCACTIVATE:
 		if w.stage >= system.StageInactive {
 			if wParam == windows.TRUE {
@@ -299,6 +424,17 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 				w.setStage(system.StageInactive)
 			}
 		}
+	case windows.WM_MOUSEACTIVATE:
+		// Discard the activating click in the client area, but not
+		// in the frame, which is needed to move the window.
+		if !w.config.AcceptFirstMouse && lParam&0xffff == windows.HTCLIENT {
+			return windows.MA_ACTIVATEANDEAT
+		}
+	case windows.WM_SYSCOMMAND:
+		if wParam&0xfff0 == windows.SC_MINIMIZE && w.config.MinimizeToTray {
+			w.hideToTray()
+			return 0
+		}
 	case windows.WM_NCHITTEST:
 		if w.config.Decorated {
 			// Let the system handle it.
@@ -311,6 +447,24 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 	case windows.WM_MOUSEMOVE:
 		x, y := coordsFromlParam(lParam)
 		p := f32.Point{X: float32(x), Y: float32(y)}
+		if w.relMouse {
+			c := w.config.Size.Div(2)
+			d := f32.Point{X: float32(x - c.X), Y: float32(y - c.Y)}
+			if d == (f32.Point{}) {
+				// Ignore the move caused by the warp below.
+				break
+			}
+			w.SetCursorPos(c)
+			w.w.Event(pointer.Event{
+				Type:     pointer.Move,
+				Source:   pointer.Mouse,
+				Position: w.relPosition(),
+				Delta:    d,
+				Buttons:  w.pointerBtns,
+				Time:     windows.GetMessageTime(),
+			})
+			break
+		}
 		w.w.Event(pointer.Event{
 			Type:     pointer.Move,
 			Source:   pointer.Mouse,
@@ -322,32 +476,77 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 		w.scrollEvent(wParam, lParam, false)
 	case windows.WM_MOUSEHWHEEL:
 		w.scrollEvent(wParam, lParam, true)
+	case windows.WM_ENDSESSION:
+		if wParam != 0 && !w.ended {
+			// The process may exit as soon as WM_ENDSESSION returns.
+			w.ended = true
+			w.w.Event(SaveStateEvent{})
+			w.w.Event(ViewEvent{})
+			w.w.Event(system.DestroyEvent{Reason: system.DestroySystemShutdown})
+		}
+		return 0
+	case windows.WM_CLOSE:
+		if w.config.DocumentEdited && !w.closing && w.w.WantsCloseRequest() {
+			// Let the program confirm the close.
+			w.w.Event(CloseRequestEvent{})
+			return 0
+		}
 	case windows.WM_DESTROY:
-		w.w.Event(ViewEvent{})
-		w.w.Event(system.DestroyEvent{})
+		if !w.ended {
+			w.w.Event(ViewEvent{})
+			w.w.Event(system.DestroyEvent{})
+		}
 		if w.hdc != 0 {
 			windows.ReleaseDC(w.hdc)
 			w.hdc = 0
 		}
+		if w.taskbar != nil {
+			w.taskbar.Release()
+			w.taskbar = nil
+		}
+		if w.customCursor != 0 {
+			windows.DestroyIcon(w.customCursor)
+			w.customCursor = 0
+		}
 		// The system destroys the HWND for us.
 		w.hwnd = 0
 		windows.PostQuitMessage(0)
 	case windows.WM_PAINT:
+		if !w.painted {
+			w.painted = true
+			w.fillSplash()
+		}
 		w.draw(true)
+	case windows.WM_MOVE:
+		// The window may have moved to another display.
+		w.updateWorkArea()
 	case windows.WM_SIZE:
 		w.update()
+		if w.inputShape != nil {
+			// The offset of the client area may have changed.
+			w.updateInputShape()
+		}
 		switch wParam {
 		case windows.SIZE_MINIMIZED:
 			w.config.Mode = Minimized
 			w.setStage(system.StagePaused)
 		case windows.SIZE_MAXIMIZED:
 			w.config.Mode = Maximized
-			w.setStage(system.StageRunning)
+			if !w.config.Hidden {
+				w.setStage(system.StageRunning)
+			}
 		case windows.SIZE_RESTORED:
 			if w.config.Mode != Fullscreen {
 				w.config.Mode = Windowed
 			}
-			w.setStage(system.StageRunning)
+			if !w.config.Hidden {
+				w.setStage(system.StageRunning)
+			}
+		}
+	case windows.WM_SIZING:
+		if w.config.AspectRatio != (image.Point{}) || w.config.ResizeIncrements != (image.Point{}) {
+			w.constrainSizing(windows.SizingRect(lParam), wParam)
+			return windows.TRUE
 		}
 	case windows.WM_GETMINMAXINFO:
 		mm := (*windows.MinMaxInfo)(unsafe.Pointer(uintptr(lParam)))
@@ -365,12 +564,27 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 		}
 	case windows.WM_SETCURSOR:
 		w.cursorIn = (lParam & 0xffff) == windows.HTCLIENT
+		if w.relMouse {
+			windows.SetCursor(0)
+			return windows.TRUE
+		}
 		if w.cursorIn {
 			windows.SetCursor(w.cursor)
 			return windows.TRUE
 		}
 	case _WM_WAKEUP:
 		w.w.Event(wakeupEvent{})
+	case windows.WM_ENTERSIZEMOVE:
+		w.sizeMove = true
+		w.updateAnimTimer()
+	case windows.WM_EXITSIZEMOVE:
+		w.sizeMove = false
+		w.updateAnimTimer()
+	case windows.WM_TIMER:
+		if wParam == animTimer && w.animating {
+			w.draw(false)
+			return 0
+		}
 	case windows.WM_IME_STARTCOMPOSITION:
 		imc := windows.ImmGetContext(w.hwnd)
 		if imc == 0 {
@@ -495,8 +709,8 @@ func (w *window) hitTest(x, y int) uintptr {
 	if a, ok := w.w.ActionAt(p); ok && a == system.ActionMove {
 		return windows.HTCAPTION
 	}
-	if w.config.Mode != Windowed {
-		// Only windowed mode should allow resizing.
+	if w.config.Mode != Windowed || !w.config.Resizable {
+		// Only resizable windowed mode should allow resizing.
 		return windows.HTCLIENT
 	}
 	top := y <= w.borderSize.Y
@@ -548,6 +762,9 @@ func (w *window) pointerButton(btn pointer.Buttons, press bool, lParam uintptr,
 	}
 	x, y := coordsFromlParam(lParam)
 	p := f32.Point{X: float32(x), Y: float32(y)}
+	if w.relMouse {
+		p = w.relPosition()
+	}
 	w.w.Event(pointer.Event{
 		Type:      typ,
 		Source:    pointer.Mouse,
@@ -571,6 +788,9 @@ func (w *window) scrollEvent(wParam, lParam uintptr, horizontal bool) {
 	np := windows.Point{X: int32(x), Y: int32(y)}
 	windows.ScreenToClient(w.hwnd, &np)
 	p := f32.Point{X: float32(np.X), Y: float32(np.Y)}
+	if w.relMouse {
+		p = w.relPosition()
+	}
 	dist := float32(int16(wParam >> 16))
 	var sp f32.Point
 	if horizontal {
@@ -611,19 +831,46 @@ loop:
 	return nil
 }
 
+func (w *window) ProcessEvents() {
+	msg := new(windows.Msg)
+	for windows.PeekMessage(msg, 0, 0, 0, windows.PM_REMOVE) {
+		if msg.Message == windows.WM_QUIT {
+			// Leave WM_QUIT to the event loop.
+			windows.PostQuitMessage(msg.WParam)
+			return
+		}
+		windows.TranslateMessage(msg)
+		windows.DispatchMessage(msg)
+	}
+}
+
 func (w *window) EditorStateChanged(old, new editorState) {
 	imc := windows.ImmGetContext(w.hwnd)
 	if imc == 0 {
 		return
 	}
 	defer windows.ImmReleaseContext(w.hwnd, imc)
-	if old.Selection.Range != new.Selection.Range || old.Snippet != new.Snippet {
+	if old.target != new.target || old.Selection.Range != new.Selection.Range || old.Snippet != new.Snippet {
 		windows.ImmNotifyIME(imc, windows.NI_COMPOSITIONSTR, windows.CPS_CANCEL, 0)
 	}
 }
 
 func (w *window) SetAnimating(anim bool) {
 	w.animating = anim
+	if w.sizeMove {
+		w.updateAnimTimer()
+	}
+}
+
+// updateAnimTimer starts or stops the timer that keeps animations
+// running while the window is moved or resized. The move and resize
+// loops are modal and block loop, but they do dispatch timer messages.
+func (w *window) updateAnimTimer() {
+	if w.sizeMove && w.animating {
+		windows.SetTimer(w.hwnd, animTimer, animInterval, 0)
+	} else {
+		windows.KillTimer(w.hwnd, animTimer)
+	}
 }
 
 func (w *window) Wakeup() {
@@ -639,6 +886,14 @@ func (w *window) setStage(s system.Stage) {
 	}
 }
 
+// hideToTray hides the window in response to a minimize request.
+func (w *window) hideToTray() {
+	w.config.Hidden = true
+	windows.ShowWindow(w.hwnd, windows.SW_HIDE)
+	w.setStage(system.StagePaused)
+	w.w.Event(ConfigEvent{Config: w.config})
+}
+
 func (w *window) draw(sync bool) {
 	if w.config.Size.X == 0 || w.config.Size.Y == 0 {
 		return
@@ -673,7 +928,7 @@ func (w *window) NewContext() (context, error) {
 	return nil, errors.New("NewContext: no available GPU drivers")
 }
 
-func (w *window) ReadClipboard() {
+func (w *window) ReadClipboard(clipboard.Selection) {
 	w.readClipboard()
 }
 
@@ -699,7 +954,18 @@ func (w *window) readClipboard() error {
 func (w *window) Configure(options []Option) {
 	dpi := windows.GetSystemDPI()
 	metric := configForDPI(dpi)
+	prev := w.config
 	w.config.apply(metric, options)
+	// Only sRGB framebuffers are supported.
+	w.config.ColorSpace = ColorSpaceSRGB
+	if w.config.Mode == Minimized && w.config.MinimizeToTray {
+		// Hide the window instead of minimizing it.
+		w.config.Mode = prev.Mode
+		if w.config.Mode == Minimized {
+			w.config.Mode = Windowed
+		}
+		w.config.Hidden = true
+	}
 	windows.SetWindowText(w.hwnd, w.config.Title)
 
 	style := windows.GetWindowLong(w.hwnd, windows.GWL_STYLE)
@@ -711,6 +977,9 @@ func (w *window) Configure(options []Option) {
 	if !w.config.Decorated {
 		winStyle = 0
 	}
+	if !w.config.Resizable {
+		winStyle &^= windows.WS_THICKFRAME | windows.WS_MAXIMIZEBOX
+	}
 	switch w.config.Mode {
 	case Minimized:
 		style |= winStyle
@@ -753,17 +1022,331 @@ func (w *window) Configure(options []Option) {
 		height = mi.Monitor.Bottom - mi.Monitor.Top
 		showMode = windows.SW_SHOW
 	}
+	if w.config.Hidden {
+		showMode = windows.SW_HIDE
+	}
+	if w.config.SkipTaskbar != prev.SkipTaskbar {
+		exStyle := windows.GetWindowLong(w.hwnd, windows.GWL_EXSTYLE)
+		exStyle &^= windows.WS_EX_APPWINDOW | windows.WS_EX_TOOLWINDOW
+		if w.config.SkipTaskbar {
+			exStyle |= windows.WS_EX_TOOLWINDOW
+		} else {
+			exStyle |= windows.WS_EX_APPWINDOW
+		}
+		// The taskbar notices the change when the window is shown
+		// again below.
+		windows.ShowWindow(w.hwnd, windows.SW_HIDE)
+		windows.SetWindowLong(w.hwnd, windows.GWL_EXSTYLE, exStyle)
+	}
 	windows.SetWindowLong(w.hwnd, windows.GWL_STYLE, style)
 	windows.SetWindowPos(w.hwnd, 0, x, y, width, height, swpStyle)
 	windows.ShowWindow(w.hwnd, showMode)
+	switch {
+	case w.config.Hidden:
+		w.setStage(system.StagePaused)
+	case w.config.Mode != Minimized && w.stage == system.StagePaused:
+		// Showing a hidden window doesn't necessarily resize it.
+		w.setStage(system.StageRunning)
+	}
+	if w.config.Material != prev.Material {
+		if err := w.setMaterial(w.config.Material); err != nil {
+			w.setMaterial(MaterialNone)
+			w.config.Material = MaterialNone
+		}
+	}
+	if w.config.Shadow != prev.Shadow || w.config.Material != prev.Material {
+		if err := w.setShadow(w.config.Shadow); err != nil {
+			w.config.Shadow = false
+		}
+	}
 
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
-func (w *window) WriteClipboard(s string) {
+// constrainSizing adjusts the window rectangle of an interactive resize
+// from the edge to match the aspect ratio and resize increments of the
+// client area.
+func (w *window) constrainSizing(r *windows.Rect, edge uintptr) {
+	width := r.Right - r.Left - w.deltas.width
+	height := r.Bottom - r.Top - w.deltas.height
+	if ratio := w.config.AspectRatio; ratio != (image.Point{}) {
+		switch edge {
+		case windows.WMSZ_TOP, windows.WMSZ_BOTTOM:
+			// Follow the height.
+			width = height * int32(ratio.X) / int32(ratio.Y)
+		default:
+			height = width * int32(ratio.Y) / int32(ratio.X)
+		}
+	}
+	size := constrainIncrements(image.Pt(int(width), int(height)), w.config.ResizeIncrements)
+	width, height = int32(size.X), int32(size.Y)
+	width += w.deltas.width
+	height += w.deltas.height
+	switch edge {
+	case windows.WMSZ_LEFT, windows.WMSZ_TOPLEFT, windows.WMSZ_BOTTOMLEFT:
+		r.Left = r.Right - width
+	default:
+		r.Right = r.Left + width
+	}
+	switch edge {
+	case windows.WMSZ_TOP, windows.WMSZ_TOPLEFT, windows.WMSZ_TOPRIGHT:
+		r.Top = r.Bottom - height
+	default:
+		r.Bottom = r.Top + height
+	}
+}
+
+// setShadow enables the DWM frame, and thereby the drop shadow, of
+// windows without a system frame.
+func (w *window) setShadow(enable bool) error {
+	policy := uint32(windows.DWMNCRP_USEWINDOWSTYLE)
+	if enable {
+		policy = windows.DWMNCRP_ENABLED
+	}
+	if err := windows.DwmSetWindowAttribute(w.hwnd, windows.DWMWA_NCRENDERING_POLICY, policy); err != nil {
+		return err
+	}
+	if w.config.Material != MaterialNone {
+		// The frame is already extended by setMaterial.
+		return nil
+	}
+	var margins windows.Margins
+	if enable {
+		// The shadow is only drawn for windows with a
+		// non-empty frame.
+		margins = windows.Margins{CyTopHeight: 1}
+	}
+	return windows.DwmExtendFrameIntoClientArea(w.hwnd, margins)
+}
+
+// setMaterial requests the system backdrop for a material. Acrylic and
+// Mica backdrops are only supported on Windows 11. The plain blur uses
+// the blur behind accent instead.
+func (w *window) setMaterial(m Material) error {
+	backdrop := uint32(windows.DWMSBT_NONE)
+	accent := uint32(windows.ACCENT_DISABLED)
+	var margins windows.Margins
+	switch m {
+	case MaterialBlur:
+		accent = windows.ACCENT_ENABLE_BLURBEHIND
+	case MaterialAcrylic:
+		// The transient window backdrop is Desktop Acrylic.
+		backdrop = windows.DWMSBT_TRANSIENTWINDOW
+	case MaterialMica:
+		backdrop = windows.DWMSBT_MAINWINDOW
+	}
+	if err := windows.SetWindowAccent(w.hwnd, accent); err != nil && m == MaterialBlur {
+		return err
+	}
+	if m != MaterialNone {
+		// Extend the frame over the whole client area to let the
+		// backdrop show through.
+		margins = windows.Margins{
+			CxLeftWidth: -1, CxRightWidth: -1, CyTopHeight: -1, CyBottomHeight: -1,
+		}
+	}
+	// Windows before 11 doesn't know the attribute, which only matters
+	// when a backdrop is requested.
+	if err := windows.DwmSetWindowAttribute(w.hwnd, windows.DWMWA_SYSTEMBACKDROP_TYPE, backdrop); err != nil && backdrop != windows.DWMSBT_NONE {
+		return err
+	}
+	return windows.DwmExtendFrameIntoClientArea(w.hwnd, margins)
+}
+
+func (w *window) WriteClipboard(_ clipboard.Selection, s string) {
 	w.writeClipboard(s)
 }
 
+func (w *window) ReadClipboardData(mime string) {
+	e := clipboard.Event{Type: mime}
+	// Deliver the event even if the type is not available.
+	w.readClipboardData(&e)
+	w.w.Event(e)
+}
+
+func (w *window) readClipboardData(e *clipboard.Event) error {
+	if err := windows.OpenClipboard(w.hwnd); err != nil {
+		return err
+	}
+	defer windows.CloseClipboard()
+	for f := windows.EnumClipboardFormats(0); f != 0; f = windows.EnumClipboardFormats(f) {
+		if f == windows.CF_UNICODETEXT {
+			e.Types = append(e.Types, "text/plain")
+			continue
+		}
+		if t := clipboardFormatMIME(windows.GetClipboardFormatName(f)); t != "" {
+			e.Types = append(e.Types, t)
+		}
+	}
+	format, err := windows.RegisterClipboardFormat(clipboardFormatName(e.Type))
+	if err != nil {
+		return err
+	}
+	mem, err := windows.GetClipboardData(format)
+	if err != nil {
+		return err
+	}
+	ptr, err := windows.GlobalLock(mem)
+	if err != nil {
+		return err
+	}
+	defer windows.GlobalUnlock(mem)
+	e.Data = append([]byte{}, unsafe.Slice((*byte)(ptr), windows.GlobalSize(mem))...)
+	return nil
+}
+
+func (w *window) WriteClipboardData(mime string, data []byte) {
+	w.writeClipboardData(mime, data)
+}
+
+func (w *window) StartDrag(data []byte, types []string) {
+	var formats []windows.DragFormat
+	for _, mime := range types {
+		if text, err := gowindows.UTF16FromString(string(data)); err == nil && isTextMIME(mime) {
+			formats = append(formats, windows.DragFormat{
+				Format: windows.CF_UNICODETEXT,
+				Data:   unsafe.Slice((*byte)(unsafe.Pointer(&text[0])), len(text)*2),
+			})
+		}
+		if mime == "text/uri-list" {
+			// File managers such as Explorer accept file URLs as
+			// a list of paths.
+			if paths := fileURLPaths(data); len(paths) > 0 {
+				formats = append(formats, windows.DragFormat{
+					Format: windows.CF_HDROP,
+					Data:   windows.DropFiles(paths),
+				})
+			}
+		}
+		format, err := windows.RegisterClipboardFormat(clipboardFormatName(mime))
+		if err != nil {
+			continue
+		}
+		formats = append(formats, windows.DragFormat{Format: format, Data: data})
+	}
+	effect, err := windows.DoDragDrop(formats, windows.DROPEFFECT_COPY|windows.DROPEFFECT_MOVE)
+	// The drag loop consumed the release of the button.
+	w.w.Event(pointer.Event{Type: pointer.Cancel, Source: pointer.Mouse})
+	res := DragCanceled
+	switch {
+	case err != nil:
+	case effect&windows.DROPEFFECT_MOVE != 0:
+		res = DragMoved
+	case effect&windows.DROPEFFECT_COPY != 0:
+		res = DragCopied
+	}
+	w.w.Event(DragResultEvent{Result: res})
+}
+
+func (w *window) SetProgress(fraction float64, state ProgressState) {
+	if w.taskbar == nil {
+		if err := windows.CoInitializeEx(windows.COINIT_APARTMENTTHREADED); err != nil {
+			return
+		}
+		tbl, err := windows.CreateTaskbarList()
+		if err != nil {
+			return
+		}
+		w.taskbar = tbl
+	}
+	flags := uint32(windows.TBPF_NORMAL)
+	switch state {
+	case ProgressNone:
+		flags = windows.TBPF_NOPROGRESS
+	case ProgressPaused:
+		flags = windows.TBPF_PAUSED
+	case ProgressError:
+		flags = windows.TBPF_ERROR
+	}
+	w.taskbar.SetProgressState(w.hwnd, flags)
+	if state != ProgressNone {
+		const total = 1000
+		w.taskbar.SetProgressValue(w.hwnd, uint64(fraction*total), total)
+	}
+}
+
+func (w *window) StartMove() {
+	// SC_MOVE enters the system move loop, which accepts both
+	// the keyboard and the mouse.
+	windows.PostMessage(w.hwnd, windows.WM_SYSCOMMAND, windows.SC_MOVE, 0)
+}
+
+func (w *window) StartResize(edge ResizeEdge) {
+	var sz uintptr
+	switch edge {
+	case EdgeNorth:
+		sz = windows.WMSZ_TOP
+	case EdgeSouth:
+		sz = windows.WMSZ_BOTTOM
+	case EdgeWest:
+		sz = windows.WMSZ_LEFT
+	case EdgeEast:
+		sz = windows.WMSZ_RIGHT
+	case EdgeNorthWest:
+		sz = windows.WMSZ_TOPLEFT
+	case EdgeNorthEast:
+		sz = windows.WMSZ_TOPRIGHT
+	case EdgeSouthWest:
+		sz = windows.WMSZ_BOTTOMLEFT
+	case EdgeSouthEast:
+		sz = windows.WMSZ_BOTTOMRIGHT
+	}
+	windows.PostMessage(w.hwnd, windows.WM_SYSCOMMAND, windows.SC_SIZE|sz, 0)
+}
+
+func (w *window) writeClipboardData(mime string, data []byte) error {
+	format, err := windows.RegisterClipboardFormat(clipboardFormatName(mime))
+	if err != nil {
+		return err
+	}
+	if err := windows.OpenClipboard(w.hwnd); err != nil {
+		return err
+	}
+	defer windows.CloseClipboard()
+	if err := windows.EmptyClipboard(); err != nil {
+		return err
+	}
+	mem, err := windows.GlobalAlloc(len(data))
+	if err != nil {
+		return err
+	}
+	ptr, err := windows.GlobalLock(mem)
+	if err != nil {
+		windows.GlobalFree(mem)
+		return err
+	}
+	copy(unsafe.Slice((*byte)(ptr), len(data)), data)
+	windows.GlobalUnlock(mem)
+	if err := windows.SetClipboardData(format, mem); err != nil {
+		windows.GlobalFree(mem)
+		return err
+	}
+	return nil
+}
+
+// clipboardFormatName maps a MIME type to the name of its registered
+// clipboard format.
+func clipboardFormatName(mime string) string {
+	if mime == "image/png" {
+		// The de facto standard name for PNG data.
+		return "PNG"
+	}
+	return mime
+}
+
+// clipboardFormatMIME is the inverse of clipboardFormatName. It returns
+// the empty string for format names that are not MIME types.
+func clipboardFormatMIME(name string) string {
+	switch {
+	case name == "PNG":
+		return "image/png"
+	case strings.Contains(name, "/"):
+		return name
+	default:
+		return ""
+	}
+}
+
 func (w *window) writeClipboard(s string) error {
 	if err := windows.OpenClipboard(w.hwnd); err != nil {
 		return err
@@ -807,6 +1390,173 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	}
 }
 
+func (w *window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	old := w.customCursor
+	w.customCursor = 0
+	c := resources.cursor
+	if img != nil {
+		var err error
+		c, err = createCursor(img, hotspot)
+		if err != nil {
+			return false
+		}
+		w.customCursor = c
+	}
+	w.cursor = c
+	if w.cursorIn && !w.relMouse {
+		windows.SetCursor(w.cursor)
+	}
+	if old != 0 {
+		windows.DestroyIcon(old)
+	}
+	return true
+}
+
+// createCursor creates a cursor from an image.
+func createCursor(img *image.NRGBA, hotspot image.Point) (syscall.Handle, error) {
+	size := img.Bounds().Size()
+	// The color bitmap is in BGRA order.
+	bgra := make([]byte, size.X*size.Y*4)
+	for y := 0; y < size.Y; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+size.X*4]
+		for x := 0; x < len(row); x += 4 {
+			o := y*size.X*4 + x
+			bgra[o+0] = row[x+2]
+			bgra[o+1] = row[x+1]
+			bgra[o+2] = row[x+0]
+			bgra[o+3] = row[x+3]
+		}
+	}
+	colors, err := windows.CreateBitmap(int32(size.X), int32(size.Y), 1, 32, bgra)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.DeleteObject(colors)
+	// The mask is unused for cursors with alpha, but required. Its rows
+	// are aligned to 16 bits.
+	mask, err := windows.CreateBitmap(int32(size.X), int32(size.Y), 1, 1, make([]byte, (size.X+15)/16*2*size.Y))
+	if err != nil {
+		return 0, err
+	}
+	defer windows.DeleteObject(mask)
+	return windows.CreateIconIndirect(&windows.IconInfo{
+		XHotspot: uint32(hotspot.X),
+		YHotspot: uint32(hotspot.Y),
+		Mask:     mask,
+		Color:    colors,
+	})
+}
+
+func (w *window) SetRelativeMouse(enable bool) {
+	if enable == w.relMouse {
+		return
+	}
+	w.relMouse = enable
+	if enable {
+		w.relAnchor = windows.GetCursorPos()
+		windows.SetCursor(0)
+		w.lockCursor()
+		w.SetCursorPos(w.config.Size.Div(2))
+		return
+	}
+	if !w.confined {
+		windows.ClipCursor(nil)
+	}
+	windows.SetCursorPos(w.relAnchor.X, w.relAnchor.Y)
+	if w.cursorIn {
+		windows.SetCursor(w.cursor)
+	}
+}
+
+// SetLayoutDirection is a no-op, because right-to-left window layouts
+// mirror the client area coordinates.
+func (w *window) SetLayoutDirection(dir system.TextDirection) {}
+
+func (w *window) SetCursorConfined(confine bool) {
+	if confine == w.confined {
+		return
+	}
+	w.confined = confine
+	switch {
+	case w.relMouse:
+		// The cursor is confined regardless.
+	case confine && w.focused:
+		w.lockCursor()
+	case !confine:
+		windows.ClipCursor(nil)
+	}
+}
+
+func (w *window) SetInputShape(shape []image.Rectangle) {
+	w.inputShape = shape
+	if shape == nil {
+		windows.SetWindowRgn(w.hwnd, 0, true)
+		return
+	}
+	w.updateInputShape()
+}
+
+// updateInputShape sets the window region to the input shape.
+func (w *window) updateInputShape() {
+	shape := w.inputShape
+	// Window regions are relative to the window, not its client area.
+	var off windows.Point
+	windows.ClientToScreen(w.hwnd, &off)
+	wr := windows.GetWindowRect(w.hwnd)
+	dx, dy := off.X-wr.Left, off.Y-wr.Top
+	rgn := windows.CreateRectRgn(0, 0, 0, 0)
+	for _, r := range shape {
+		rr := windows.CreateRectRgn(int32(r.Min.X)+dx, int32(r.Min.Y)+dy, int32(r.Max.X)+dx, int32(r.Max.Y)+dy)
+		windows.CombineRgn(rgn, rgn, rr, windows.RGN_OR)
+		windows.DeleteObject(rr)
+	}
+	windows.SetWindowRgn(w.hwnd, rgn, true)
+}
+
+// fillSplash fills the client area with the splash color, if any.
+func (w *window) fillSplash() {
+	c := w.config.SplashColor
+	if c.A == 0 {
+		return
+	}
+	hdc, err := windows.GetDC(w.hwnd)
+	if err != nil {
+		return
+	}
+	defer windows.ReleaseDC(hdc)
+	brush := windows.CreateSolidBrush(uint32(c.B)<<16 | uint32(c.G)<<8 | uint32(c.R))
+	defer windows.DeleteObject(brush)
+	r := windows.Rect{Right: int32(w.config.Size.X), Bottom: int32(w.config.Size.Y)}
+	windows.FillRect(hdc, &r, brush)
+}
+
+// lockCursor confines the cursor to the client area.
+func (w *window) lockCursor() {
+	var p windows.Point
+	windows.ClientToScreen(w.hwnd, &p)
+	r := windows.Rect{
+		Left:   p.X,
+		Top:    p.Y,
+		Right:  p.X + int32(w.config.Size.X),
+		Bottom: p.Y + int32(w.config.Size.Y),
+	}
+	windows.ClipCursor(&r)
+}
+
+// relPosition returns the pointer position reported in relative mouse
+// mode.
+func (w *window) relPosition() f32.Point {
+	p := w.relAnchor
+	windows.ScreenToClient(w.hwnd, &p)
+	return f32.Point{X: float32(p.X), Y: float32(p.Y)}
+}
+
+func (w *window) SetCursorPos(pos image.Point) {
+	p := windows.Point{X: int32(pos.X), Y: int32(pos.Y)}
+	windows.ClientToScreen(w.hwnd, &p)
+	windows.SetCursorPos(p.X, p.Y)
+}
+
 // windowsCursor contains mapping from pointer.Cursor to an IDC.
 var windowsCursor = [...]uint16{
 	pointer.CursorDefault:                  windows.IDC_ARROW,
@@ -850,7 +1600,7 @@ func loadCursor(cursor pointer.Cursor) (syscall.Handle, error) {
 
 func (w *window) ShowTextInput(show bool) {}
 
-func (w *window) SetInputHint(_ key.InputHint) {}
+func (w *window) SetInputHint(_ key.InputHint, _ key.InputOptions) {}
 
 func (w *window) HDC() syscall.Handle {
 	return w.hdc
@@ -878,14 +1628,20 @@ func (w *window) Perform(acts system.Action) {
 		case system.ActionRaise:
 			w.raise()
 		case system.ActionClose:
+			w.closing = true
 			windows.PostMessage(w.hwnd, windows.WM_CLOSE, 0, 0)
 		}
 	})
 }
 
 func (w *window) raise() {
+	if w.config.Mode == Minimized {
+		windows.ShowWindow(w.hwnd, windows.SW_RESTORE)
+	}
 	windows.SetForegroundWindow(w.hwnd)
-	windows.SetWindowPos(w.hwnd, windows.HWND_TOPMOST, 0, 0, 0, 0,
+	// Use HWND_TOP, not HWND_TOPMOST, to avoid keeping the window above
+	// all others once raised.
+	windows.SetWindowPos(w.hwnd, windows.HWND_TOP, 0, 0, 0, 0,
 		windows.SWP_NOMOVE|windows.SWP_NOSIZE|windows.SWP_SHOWWINDOW)
 }
 
@@ -9,8 +9,8 @@ package app
 /*
 #cgo freebsd openbsd CFLAGS: -I/usr/X11R6/include -I/usr/local/include
 #cgo freebsd openbsd LDFLAGS: -L/usr/X11R6/lib -L/usr/local/lib
-#cgo freebsd openbsd LDFLAGS: -lX11 -lxkbcommon -lxkbcommon-x11 -lX11-xcb -lXcursor -lXfixes
-#cgo linux pkg-config: x11 xkbcommon xkbcommon-x11 x11-xcb xcursor xfixes
+#cgo freebsd openbsd LDFLAGS: -lX11 -lxkbcommon -lxkbcommon-x11 -lX11-xcb -lXcursor -lXfixes -lXrandr
+#cgo linux pkg-config: x11 xkbcommon xkbcommon-x11 x11-xcb xcursor xfixes xrandr
 
 #include <stdlib.h>
 #include <locale.h>
@@ -21,6 +21,8 @@ package app
 #include <X11/XKBlib.h>
 #include <X11/Xlib-xcb.h>
 #include <X11/extensions/Xfixes.h>
+#include <X11/extensions/shape.h>
+#include <X11/extensions/Xrandr.h>
 #include <X11/Xcursor/Xcursor.h>
 #include <xkbcommon/xkbcommon-x11.h>
 
@@ -57,7 +59,10 @@ type x11Window struct {
 	x            *C.Display
 	xkb          *xkb.Context
 	xkbEventBase C.int
-	xw           C.Window
+	// randrEvent is the RRScreenChangeNotify event type, or -1 if
+	// the server doesn't support RandR 1.5 monitors.
+	randrEvent C.int
+	xw         C.Window
 
 	atoms struct {
 		// "UTF8_STRING".
@@ -72,6 +77,8 @@ type x11Window struct {
 		primary C.Atom
 		// "CLIPBOARD_CONTENT", the clipboard destination property.
 		clipboardContent C.Atom
+		// "CLIPBOARD_TARGETS", the clipboard targets destination property.
+		clipboardTargets C.Atom
 		// "WM_DELETE_WINDOW"
 		evDelWindow C.Atom
 		// "ATOM"
@@ -82,6 +89,8 @@ type x11Window struct {
 		wmName C.Atom
 		// "_NET_WM_STATE"
 		wmState C.Atom
+		// "_NET_WM_MOVERESIZE"
+		wmMoveResize C.Atom
 		// "_NET_WM_STATE_FULLSCREEN"
 		wmStateFullscreen C.Atom
 		// "_NET_ACTIVE_WINDOW"
@@ -90,6 +99,26 @@ type x11Window struct {
 		wmStateMaximizedHorz C.Atom
 		// _NET_WM_STATE_MAXIMIZED_VERT
 		wmStateMaximizedVert C.Atom
+		// _NET_WM_STATE_SKIP_TASKBAR
+		wmStateSkipTaskbar C.Atom
+		// _NET_WM_STATE_SKIP_PAGER
+		wmStateSkipPager C.Atom
+		// "_NET_WORKAREA"
+		workArea C.Atom
+		// "_NET_CURRENT_DESKTOP"
+		currentDesktop C.Atom
+		// XDND protocol atoms, for StartDrag.
+		xdndAware      C.Atom
+		xdndSelection  C.Atom
+		xdndTypeList   C.Atom
+		xdndEnter      C.Atom
+		xdndPosition   C.Atom
+		xdndStatus     C.Atom
+		xdndLeave      C.Atom
+		xdndDrop       C.Atom
+		xdndFinished   C.Atom
+		xdndActionCopy C.Atom
+		xdndActionMove C.Atom
 	}
 	stage  system.Stage
 	metric unit.Metric
@@ -101,16 +130,77 @@ type x11Window struct {
 	animating bool
 
 	pointerBtns pointer.Buttons
+	// relMouse is set in relative mouse mode, and relAnchor is the
+	// pointer position when the mode was enabled.
+	relMouse  bool
+	relAnchor image.Point
+	// confined is set by SetCursorConfined.
+	confined bool
+	focused  bool
+	// closing is set when the program closes the window.
+	closing bool
+	// customCursor is the cursor created by SetCustomCursor.
+	customCursor C.Cursor
 
 	clipboard struct {
 		content []byte
+		primary []byte
+		// mime and data is the most recent write of
+		// non-text data, if any.
+		mime C.Atom
+		data []byte
+		// read is the type of a pending data read.
+		read C.Atom
+		// types is the most recent list of clipboard targets.
+		types []string
 	}
+	// drag is the XDND drag operation started by StartDrag, if active.
+	drag   x11Drag
 	cursor pointer.Cursor
 	config Config
+	// pos is the position of the window on the root window, as
+	// reported by the window manager.
+	pos image.Point
+	// monitors caches the monitors and their work areas. It is
+	// reloaded when the screen configuration or the work area changes.
+	monitors      []x11Monitor
+	monitorsValid bool
 
 	wakeups chan struct{}
 }
 
+// x11Monitor is a monitor and the part of it not covered by panels.
+type x11Monitor struct {
+	bounds   image.Rectangle
+	workArea image.Rectangle
+}
+
+// x11Drag is the state of an XDND drag started by StartDrag.
+type x11Drag struct {
+	active bool
+	data   []byte
+	types  []C.Atom
+	// target is the XDND aware window under the pointer, and
+	// version its protocol version.
+	target  C.Window
+	version int
+	// waiting is set while a position message awaits its status
+	// reply, and moved if the pointer moved in the meantime to pos,
+	// at time with action.
+	waiting bool
+	moved   bool
+	pos     image.Point
+	time    C.Time
+	action  C.Atom
+	// accepted is set if the target accepts the drop, and released
+	// when the button is released while waiting.
+	accepted bool
+	released bool
+	// dropped is set when the drop is waiting for the target to
+	// finish.
+	dropped bool
+}
+
 var (
 	newX11EGLContext    func(w *x11Window) (context, error)
 	newX11VulkanContext func(w *x11Window) (context, error)
@@ -146,15 +236,574 @@ func (w *x11Window) SetAnimating(anim bool) {
 	w.animating = anim
 }
 
-func (w *x11Window) ReadClipboard() {
+func (w *x11Window) ReadClipboard(sel clipboard.Selection) {
+	selection := w.atoms.clipboard
+	if sel == clipboard.SelectionPrimary {
+		selection = w.atoms.primary
+	}
 	C.XDeleteProperty(w.x, w.xw, w.atoms.clipboardContent)
-	C.XConvertSelection(w.x, w.atoms.clipboard, w.atoms.utf8string, w.atoms.clipboardContent, w.xw, C.CurrentTime)
+	C.XConvertSelection(w.x, selection, w.atoms.utf8string, w.atoms.clipboardContent, w.xw, C.CurrentTime)
 }
 
-func (w *x11Window) WriteClipboard(s string) {
-	w.clipboard.content = []byte(s)
-	C.XSetSelectionOwner(w.x, w.atoms.clipboard, w.xw, C.CurrentTime)
+func (w *x11Window) WriteClipboard(sel clipboard.Selection, s string) {
+	content := []byte(s)
+	w.clipboard.primary = content
 	C.XSetSelectionOwner(w.x, w.atoms.primary, w.xw, C.CurrentTime)
+	if sel == clipboard.SelectionPrimary {
+		return
+	}
+	w.clipboard.content = content
+	w.clipboard.mime = C.None
+	w.clipboard.data = nil
+	C.XSetSelectionOwner(w.x, w.atoms.clipboard, w.xw, C.CurrentTime)
+}
+
+func (w *x11Window) ReadClipboardData(mime string) {
+	w.clipboard.read = w.atom(mime, false)
+	w.clipboard.types = nil
+	// Request the targets first; the data is requested when they
+	// arrive, so the event includes them.
+	C.XDeleteProperty(w.x, w.xw, w.atoms.clipboardTargets)
+	C.XConvertSelection(w.x, w.atoms.clipboard, w.atoms.targets, w.atoms.clipboardTargets, w.xw, C.CurrentTime)
+}
+
+func (w *x11Window) WriteClipboardData(mime string, data []byte) {
+	w.clipboard.content = nil
+	w.clipboard.mime = w.atom(mime, false)
+	w.clipboard.data = data
+	C.XSetSelectionOwner(w.x, w.atoms.clipboard, w.xw, C.CurrentTime)
+}
+
+// xdndVersion is the supported version of the XDND protocol.
+const xdndVersion = 5
+
+func (w *x11Window) StartDrag(data []byte, types []string) {
+	w.finishDrag(DragCanceled)
+	if len(types) == 0 {
+		w.w.Event(DragResultEvent{Result: DragCanceled})
+		return
+	}
+	const mask = C.ButtonReleaseMask | C.PointerMotionMask
+	if C.XGrabPointer(w.x, w.xw, C.False, mask, C.GrabModeAsync, C.GrabModeAsync, C.None, C.None, C.CurrentTime) != C.GrabSuccess {
+		w.w.Event(DragResultEvent{Result: DragCanceled})
+		return
+	}
+	atoms := make([]C.Atom, len(types))
+	for i, t := range types {
+		atoms[i] = w.atom(t, false)
+	}
+	C.XChangeProperty(w.x, w.xw, w.atoms.xdndTypeList, w.atoms.atom,
+		32, C.PropModeReplace,
+		(*C.uchar)(unsafe.Pointer(&atoms[0])), C.int(len(atoms)),
+	)
+	C.XSetSelectionOwner(w.x, w.atoms.xdndSelection, w.xw, C.CurrentTime)
+	w.drag.active = true
+	w.drag.data = data
+	w.drag.types = atoms
+}
+
+// dragMotion tracks the pointer during a drag, at (x, y) of the root
+// window.
+func (w *x11Window) dragMotion(x, y int, t C.Time, state C.uint) {
+	if w.drag.dropped {
+		return
+	}
+	action := w.atoms.xdndActionCopy
+	if state&C.ShiftMask != 0 {
+		action = w.atoms.xdndActionMove
+	}
+	w.drag.pos, w.drag.time, w.drag.action = image.Pt(x, y), t, action
+	target, version := w.dndTarget(x, y)
+	if target != w.drag.target {
+		if w.drag.target != C.None {
+			w.sendDnd(w.drag.target, w.atoms.xdndLeave, 0, 0, 0, 0)
+		}
+		w.drag.target, w.drag.version = target, version
+		w.drag.waiting, w.drag.moved, w.drag.accepted = false, false, false
+		if target == C.None {
+			return
+		}
+		flags := C.long(version) << 24
+		if len(w.drag.types) > 3 {
+			// The target must read the full list from
+			// XdndTypeList.
+			flags |= 1
+		}
+		var first [3]C.long
+		for i := 0; i < len(first) && i < len(w.drag.types); i++ {
+			first[i] = C.long(w.drag.types[i])
+		}
+		w.sendDnd(target, w.atoms.xdndEnter, flags, first[0], first[1], first[2])
+	}
+	if target == C.None {
+		return
+	}
+	if w.drag.waiting {
+		// Send the position when the target replies.
+		w.drag.moved = true
+		return
+	}
+	w.sendDragPosition()
+}
+
+func (w *x11Window) sendDragPosition() {
+	p := w.drag.pos
+	w.drag.waiting = true
+	w.drag.moved = false
+	w.sendDnd(w.drag.target, w.atoms.xdndPosition, 0, C.long(p.X)<<16|C.long(p.Y&0xffff), C.long(w.drag.time), C.long(w.drag.action))
+}
+
+// dragRelease drops the data on the target, if any, when the pointer
+// button is released.
+func (w *x11Window) dragRelease(t C.Time) {
+	C.XUngrabPointer(w.x, C.CurrentTime)
+	w.drag.time = t
+	switch {
+	case w.drag.target == C.None:
+		w.finishDrag(DragCanceled)
+	case w.drag.waiting:
+		// Decide when the target replies.
+		w.drag.released = true
+	default:
+		w.dragDrop()
+	}
+}
+
+// dragDrop sends the drop to the target if it accepts the data, and
+// cancels the drag otherwise.
+func (w *x11Window) dragDrop() {
+	if !w.drag.accepted {
+		w.sendDnd(w.drag.target, w.atoms.xdndLeave, 0, 0, 0, 0)
+		w.finishDrag(DragCanceled)
+		return
+	}
+	w.drag.dropped = true
+	w.sendDnd(w.drag.target, w.atoms.xdndDrop, 0, C.long(w.drag.time), 0, 0)
+}
+
+// handleDnd handles the XDND messages from the drag target, and reports
+// whether cevt is one.
+func (w *x11Window) handleDnd(cevt *C.XClientMessageEvent) bool {
+	if !w.drag.active {
+		return false
+	}
+	l := (*[5]C.long)(unsafe.Pointer(&cevt.data))
+	if C.Window(l[0]) != w.drag.target {
+		return cevt.message_type == w.atoms.xdndStatus || cevt.message_type == w.atoms.xdndFinished
+	}
+	switch cevt.message_type {
+	case w.atoms.xdndStatus:
+		w.drag.waiting = false
+		w.drag.accepted = l[1]&1 != 0
+		switch {
+		case w.drag.dropped:
+		case w.drag.released:
+			w.dragDrop()
+		case w.drag.moved:
+			w.sendDragPosition()
+		}
+	case w.atoms.xdndFinished:
+		if !w.drag.dropped {
+			break
+		}
+		res := DragCopied
+		switch {
+		case w.drag.version >= 5 && l[1]&1 == 0:
+			res = DragCanceled
+		case w.drag.version >= 5 && C.Atom(l[2]) == w.atoms.xdndActionMove:
+			res = DragMoved
+		}
+		w.finishDrag(res)
+	default:
+		return false
+	}
+	return true
+}
+
+// cancelDrag cancels the drag when escape is pressed.
+func (w *x11Window) cancelDrag() {
+	if !w.drag.active || w.drag.dropped {
+		return
+	}
+	C.XUngrabPointer(w.x, C.CurrentTime)
+	if w.drag.target != C.None {
+		w.sendDnd(w.drag.target, w.atoms.xdndLeave, 0, 0, 0, 0)
+	}
+	w.finishDrag(DragCanceled)
+}
+
+// finishDrag ends the drag, if any, with res.
+func (w *x11Window) finishDrag(res DragResult) {
+	if !w.drag.active {
+		return
+	}
+	w.drag = x11Drag{}
+	w.w.Event(DragResultEvent{Result: res})
+}
+
+// serveDrag answers a request for the data of the drag.
+func (w *x11Window) serveDrag(cevt *C.XSelectionRequestEvent) {
+	if !w.drag.active || cevt.property == C.None {
+		w.selectionNotify(cevt, C.None)
+		return
+	}
+	if cevt.target == w.atoms.targets {
+		formats := []C.long{C.long(w.atoms.targets)}
+		for _, t := range w.drag.types {
+			formats = append(formats, C.long(t))
+		}
+		C.XChangeProperty(w.x, cevt.requestor, cevt.property, w.atoms.atom,
+			32, C.PropModeReplace,
+			(*C.uchar)(unsafe.Pointer(&formats[0])), C.int(len(formats)),
+		)
+		w.selectionNotify(cevt, cevt.property)
+		return
+	}
+	for _, t := range w.drag.types {
+		if t != cevt.target {
+			continue
+		}
+		data := w.drag.data
+		var ptr *C.uchar
+		if len(data) > 0 {
+			ptr = (*C.uchar)(unsafe.Pointer(&data[0]))
+		}
+		C.XChangeProperty(w.x, cevt.requestor, cevt.property, cevt.target,
+			8 /* bitwidth */, C.PropModeReplace,
+			ptr, C.int(len(data)),
+		)
+		w.selectionNotify(cevt, cevt.property)
+		return
+	}
+	// Refuse unsupported targets.
+	w.selectionNotify(cevt, C.None)
+}
+
+// selectionNotify notifies the requestor of cevt that the selection is
+// stored in prop, or refused if prop is None.
+func (w *x11Window) selectionNotify(cevt *C.XSelectionRequestEvent, prop C.Atom) {
+	var xev C.XEvent
+	ev := (*C.XSelectionEvent)(unsafe.Pointer(&xev))
+	*ev = C.XSelectionEvent{
+		_type:     C.SelectionNotify,
+		display:   cevt.display,
+		requestor: cevt.requestor,
+		selection: cevt.selection,
+		target:    cevt.target,
+		property:  prop,
+		time:      cevt.time,
+	}
+	C.XSendEvent(w.x, cevt.requestor, 0, 0, &xev)
+}
+
+// dndTarget returns the XDND aware top-level window at (x, y) of the
+// root window and its protocol version, or None.
+func (w *x11Window) dndTarget(x, y int) (C.Window, int) {
+	root := C.XDefaultRootWindow(w.x)
+	win := root
+	for {
+		var child C.Window
+		var cx, cy C.int
+		if C.XTranslateCoordinates(w.x, root, win, C.int(x), C.int(y), &cx, &cy, &child) == 0 || child == C.None {
+			return C.None, 0
+		}
+		win = child
+		if win == w.xw {
+			// Drags within the window are handled by the program.
+			return C.None, 0
+		}
+		data, format := w.windowProperty(win, w.atoms.xdndAware)
+		if format == 32 && len(data) > 0 {
+			version := int(*(*C.long)(unsafe.Pointer(&data[0])))
+			if version < 3 {
+				return C.None, 0
+			}
+			if version > xdndVersion {
+				version = xdndVersion
+			}
+			return win, version
+		}
+	}
+}
+
+// sendDnd sends the XDND message typ to target.
+func (w *x11Window) sendDnd(target C.Window, typ C.Atom, l1, l2, l3, l4 C.long) {
+	var xev C.XEvent
+	ev := (*C.XClientMessageEvent)(unsafe.Pointer(&xev))
+	*ev = C.XClientMessageEvent{
+		_type:        C.ClientMessage,
+		display:      w.x,
+		window:       target,
+		message_type: typ,
+		format:       32,
+	}
+	arr := (*[5]C.long)(unsafe.Pointer(&ev.data))
+	arr[0] = C.long(w.xw)
+	arr[1], arr[2], arr[3], arr[4] = l1, l2, l3, l4
+	C.XSendEvent(w.x, target, C.False, C.NoEventMask, &xev)
+}
+
+// _NET_WM_MOVERESIZE directions.
+const (
+	x11SizeTopLeft     = 0
+	x11SizeTop         = 1
+	x11SizeTopRight    = 2
+	x11SizeRight       = 3
+	x11SizeBottomRight = 4
+	x11SizeBottom      = 5
+	x11SizeBottomLeft  = 6
+	x11SizeLeft        = 7
+	x11Move            = 8
+	x11SizeKeyboard    = 9
+	x11MoveKeyboard    = 10
+)
+
+func (w *x11Window) SetProgress(fraction float64, state ProgressState) {}
+
+func (w *x11Window) StartMove() {
+	if w.pointerBtns != 0 {
+		w.sendMoveResizeEvent(x11Move)
+	} else {
+		w.sendMoveResizeEvent(x11MoveKeyboard)
+	}
+}
+
+func (w *x11Window) StartResize(edge ResizeEdge) {
+	if w.pointerBtns == 0 {
+		// The keyboard resize lets the user pick the edge.
+		w.sendMoveResizeEvent(x11SizeKeyboard)
+		return
+	}
+	var dir C.long
+	switch edge {
+	case EdgeNorth:
+		dir = x11SizeTop
+	case EdgeSouth:
+		dir = x11SizeBottom
+	case EdgeWest:
+		dir = x11SizeLeft
+	case EdgeEast:
+		dir = x11SizeRight
+	case EdgeNorthWest:
+		dir = x11SizeTopLeft
+	case EdgeNorthEast:
+		dir = x11SizeTopRight
+	case EdgeSouthWest:
+		dir = x11SizeBottomLeft
+	case EdgeSouthEast:
+		dir = x11SizeBottomRight
+	}
+	w.sendMoveResizeEvent(dir)
+}
+
+// sendMoveResizeEvent asks the window manager to start an interactive
+// move or resize in the _NET_WM_MOVERESIZE direction dir.
+func (w *x11Window) sendMoveResizeEvent(dir C.long) {
+	var (
+		root, child              C.Window
+		rootX, rootY, winX, winY C.int
+		mask                     C.uint
+	)
+	C.XQueryPointer(w.x, w.xw, &root, &child, &rootX, &rootY, &winX, &winY, &mask)
+	// The window manager can't grab the pointer while we hold it.
+	C.XUngrabPointer(w.x, C.CurrentTime)
+	var xev C.XEvent
+	ev := (*C.XClientMessageEvent)(unsafe.Pointer(&xev))
+	*ev = C.XClientMessageEvent{
+		_type:        C.ClientMessage,
+		display:      w.x,
+		window:       w.xw,
+		message_type: w.atoms.wmMoveResize,
+		format:       32,
+	}
+	data := (*[5]C.long)(unsafe.Pointer(&ev.data))
+	data[0] = C.long(rootX)
+	data[1] = C.long(rootY)
+	data[2] = dir
+	data[3] = 1 // button
+	data[4] = 1 // application
+
+	C.XSendEvent(
+		w.x,
+		C.XDefaultRootWindow(w.x),
+		C.False,
+		C.SubstructureNotifyMask|C.SubstructureRedirectMask,
+		&xev,
+	)
+}
+
+// property returns the contents of a property of the window, and deletes it.
+// Large transfers through the INCR mechanism are not supported.
+func (w *x11Window) property(prop C.Atom) (data []byte, format int) {
+	return w.getProperty(w.xw, prop, C.True)
+}
+
+// windowProperty returns the contents of a property of win.
+func (w *x11Window) windowProperty(win C.Window, prop C.Atom) (data []byte, format int) {
+	return w.getProperty(win, prop, C.False)
+}
+
+func (w *x11Window) getProperty(win C.Window, prop C.Atom, del C.Bool) (data []byte, format int) {
+	var (
+		typ           C.Atom
+		cformat       C.int
+		nitems, after C.ulong
+		ptr           *C.uchar
+	)
+	if C.XGetWindowProperty(w.x, win, prop, 0, 1<<24, del, C.AnyPropertyType, &typ, &cformat, &nitems, &after, &ptr) != C.Success {
+		return nil, 0
+	}
+	if ptr == nil {
+		return nil, 0
+	}
+	defer C.XFree(unsafe.Pointer(ptr))
+	// Xlib returns 32-bit items as C longs.
+	size := int(nitems)
+	switch cformat {
+	case 16:
+		size *= 2
+	case 32:
+		size *= int(unsafe.Sizeof(C.long(0)))
+	}
+	return C.GoBytes(unsafe.Pointer(ptr), C.int(size)), int(cformat)
+}
+
+// updateWorkArea reports the work area of the monitor showing the
+// window.
+func (w *x11Window) updateWorkArea() {
+	if !w.monitorsValid {
+		w.monitorsValid = true
+		w.monitors = w.loadMonitors()
+	}
+	if len(w.monitors) == 0 {
+		return
+	}
+	// Pick the monitor containing the center of the window, or the
+	// first monitor.
+	center := w.pos.Add(w.config.Size.Div(2))
+	m := w.monitors[0]
+	for _, m2 := range w.monitors {
+		if center.In(m2.bounds) {
+			m = m2
+			break
+		}
+	}
+	w.w.SetWorkArea(m.workArea)
+}
+
+// loadMonitors returns the monitors of the screen, with their work areas
+// reduced by the struts of panels.
+func (w *x11Window) loadMonitors() []x11Monitor {
+	root := C.XDefaultRootWindow(w.x)
+	scr := C.XDefaultScreen(w.x)
+	screen := image.Pt(int(C.XDisplayWidth(w.x, scr)), int(C.XDisplayHeight(w.x, scr)))
+	var bounds []image.Rectangle
+	if w.randrEvent != -1 {
+		var n C.int
+		if mons := C.XRRGetMonitors(w.x, root, C.True, &n); mons != nil {
+			for _, m := range unsafe.Slice(mons, int(n)) {
+				bounds = append(bounds, image.Rect(int(m.x), int(m.y), int(m.x)+int(m.width), int(m.y)+int(m.height)))
+			}
+			C.XRRFreeMonitors(mons)
+		}
+	}
+	if len(bounds) == 0 {
+		bounds = []image.Rectangle{{Max: screen}}
+	}
+	struts := w.struts(screen)
+	// Mutter doesn't reserve space for its own panels with struts, but
+	// lists the work area of every monitor in _GTK_WORKAREAS_D<desktop>.
+	var gtkAreas []image.Rectangle
+	if data, format := w.windowProperty(root, w.atoms.currentDesktop); format == 32 && len(data) > 0 {
+		desktop := x11Cardinals(data)[0]
+		if prop := w.atom(fmt.Sprintf("_GTK_WORKAREAS_D%d", desktop), true); prop != C.None {
+			if data, format := w.windowProperty(root, prop); format == 32 {
+				v := x11Cardinals(data)
+				for i := 0; i+4 <= len(v); i += 4 {
+					gtkAreas = append(gtkAreas, image.Rect(v[i], v[i+1], v[i]+v[i+2], v[i+1]+v[i+3]))
+				}
+			}
+		}
+	}
+	monitors := make([]x11Monitor, len(bounds))
+	for i, b := range bounds {
+		m := x11Monitor{bounds: b, workArea: workArea(b, screen, struts)}
+		for _, a := range gtkAreas {
+			if !a.Empty() && a.In(b) {
+				m.workArea = a
+				break
+			}
+		}
+		monitors[i] = m
+	}
+	return monitors
+}
+
+// struts returns the space reserved by the windows managed by the window
+// manager, such as docks and panels.
+func (w *x11Window) struts(screen image.Point) []strut {
+	root := C.XDefaultRootWindow(w.x)
+	data, format := w.windowProperty(root, w.atom("_NET_CLIENT_LIST", false))
+	if format != 32 || len(data) == 0 {
+		return nil
+	}
+	partial := w.atom("_NET_WM_STRUT_PARTIAL", false)
+	full := w.atom("_NET_WM_STRUT", false)
+	var struts []strut
+	clients := unsafe.Slice((*C.Window)(unsafe.Pointer(&data[0])), len(data)/int(unsafe.Sizeof(C.Window(0))))
+	for _, c := range clients {
+		if data, format := w.windowProperty(c, partial); format == 32 {
+			if v := x11Cardinals(data); len(v) >= 12 {
+				var s strut
+				copy(s[:], v)
+				struts = append(struts, s)
+				continue
+			}
+		}
+		// Fall back to _NET_WM_STRUT, which spans the full edges.
+		if data, format := w.windowProperty(c, full); format == 32 {
+			if v := x11Cardinals(data); len(v) >= 4 {
+				maxX, maxY := screen.X-1, screen.Y-1
+				struts = append(struts, strut{v[0], v[1], v[2], v[3], 0, maxY, 0, maxY, 0, maxX, 0, maxX})
+			}
+		}
+	}
+	return struts
+}
+
+// x11Cardinals converts the data of a 32-bit property returned by
+// windowProperty to integers.
+func x11Cardinals(data []byte) []int {
+	n := len(data) / int(unsafe.Sizeof(C.long(0)))
+	if n == 0 {
+		return nil
+	}
+	v := make([]int, n)
+	for i, l := range unsafe.Slice((*C.long)(unsafe.Pointer(&data[0])), n) {
+		v[i] = int(l)
+	}
+	return v
+}
+
+func (w *x11Window) atomName(a C.Atom) string {
+	cname := C.XGetAtomName(w.x, a)
+	if cname == nil {
+		return ""
+	}
+	defer C.XFree(unsafe.Pointer(cname))
+	return C.GoString(cname)
+}
+
+// atomNames converts a list of atoms read from a property to their names.
+func (w *x11Window) atomNames(data []byte) []string {
+	var names []string
+	atoms := unsafe.Slice((*C.Atom)(unsafe.Pointer(&data[0])), len(data)/int(unsafe.Sizeof(C.Atom(0))))
+	for _, a := range atoms {
+		if name := w.atomName(a); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 func (w *x11Window) Configure(options []Option) {
@@ -164,6 +813,13 @@ func (w *x11Window) Configure(options []Option) {
 	cnf.apply(w.metric, options)
 	// Decorations are never disabled.
 	cnf.Decorated = true
+	w.config.DocumentEdited = cnf.DocumentEdited
+	if cnf.Mode == Minimized && cnf.MinimizeToTray {
+		// Hide the window instead of minimizing it.
+		cnf.Mode = prev.Mode
+		cnf.Hidden = true
+	}
+	w.config.MinimizeToTray = cnf.MinimizeToTray
 
 	switch cnf.Mode {
 	case Fullscreen:
@@ -213,25 +869,70 @@ func (w *x11Window) Configure(options []Option) {
 			w.config.Size = cnf.Size
 			C.XResizeWindow(w.x, w.xw, C.uint(cnf.Size.X), C.uint(cnf.Size.Y))
 		}
-		if prev.MinSize != cnf.MinSize {
+		if prev.MinSize != cnf.MinSize || prev.MaxSize != cnf.MaxSize || prev.AspectRatio != cnf.AspectRatio || prev.ResizeIncrements != cnf.ResizeIncrements || prev.Resizable != cnf.Resizable || (!cnf.Resizable && prev.Size != cnf.Size) {
 			w.config.MinSize = cnf.MinSize
-			shints.min_width = C.int(cnf.MinSize.X)
-			shints.min_height = C.int(cnf.MinSize.Y)
-			shints.flags = C.PMinSize
-		}
-		if prev.MaxSize != cnf.MaxSize {
 			w.config.MaxSize = cnf.MaxSize
-			shints.max_width = C.int(cnf.MaxSize.X)
-			shints.max_height = C.int(cnf.MaxSize.Y)
-			shints.flags = shints.flags | C.PMaxSize
-		}
-		if shints.flags != 0 {
+			w.config.AspectRatio = cnf.AspectRatio
+			w.config.ResizeIncrements = cnf.ResizeIncrements
+			w.config.Resizable = cnf.Resizable
+			if !cnf.Resizable {
+				// Window managers don't resize windows whose minimum
+				// and maximum sizes are equal.
+				cnf.MinSize, cnf.MaxSize = cnf.Size, cnf.Size
+			}
+			if p := cnf.MinSize; p != (image.Point{}) {
+				shints.min_width = C.int(p.X)
+				shints.min_height = C.int(p.Y)
+				shints.flags = C.PMinSize
+			}
+			if p := cnf.MaxSize; p != (image.Point{}) {
+				shints.max_width = C.int(p.X)
+				shints.max_height = C.int(p.Y)
+				shints.flags = shints.flags | C.PMaxSize
+			}
+			if p := cnf.AspectRatio; p != (image.Point{}) {
+				shints.min_aspect.x = C.int(p.X)
+				shints.min_aspect.y = C.int(p.Y)
+				shints.max_aspect = shints.min_aspect
+				shints.flags = shints.flags | C.PAspect
+			}
+			if p := cnf.ResizeIncrements; p != (image.Point{}) {
+				// Increments are relative to the base size.
+				shints.width_inc, shints.height_inc = 1, 1
+				if p.X > 0 {
+					shints.width_inc = C.int(p.X)
+				}
+				if p.Y > 0 {
+					shints.height_inc = C.int(p.Y)
+				}
+				shints.flags = shints.flags | C.PResizeInc | C.PBaseSize
+			}
 			C.XSetWMNormalHints(w.x, w.xw, &shints)
 		}
 	}
 	if cnf.Decorated != prev.Decorated {
 		w.config.Decorated = cnf.Decorated
 	}
+	if cnf.Hidden != prev.Hidden {
+		w.config.Hidden = cnf.Hidden
+		if cnf.Hidden {
+			C.XUnmapWindow(w.x, w.xw)
+			w.setStage(system.StagePaused)
+		} else {
+			C.XMapWindow(w.x, w.xw)
+			w.setStage(system.StageRunning)
+		}
+	}
+	// The window manager forgets the state of unmapped windows, so
+	// set it again when the window is shown.
+	if cnf.SkipTaskbar != prev.SkipTaskbar || (cnf.SkipTaskbar && prev.Hidden && !cnf.Hidden) {
+		w.config.SkipTaskbar = cnf.SkipTaskbar
+		action := C.long(_NET_WM_STATE_REMOVE)
+		if cnf.SkipTaskbar {
+			action = _NET_WM_STATE_ADD
+		}
+		w.sendWMStateEvent(action, w.atoms.wmStateSkipTaskbar, w.atoms.wmStateSkipPager)
+	}
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
@@ -324,14 +1025,135 @@ func (w *x11Window) SetCursor(cursor pointer.Cursor) {
 	C.XDefineCursor(w.x, w.xw, c)
 }
 
+func (w *x11Window) SetCustomCursor(img *image.NRGBA, hotspot image.Point) bool {
+	if w.customCursor != 0 {
+		C.XUndefineCursor(w.x, w.xw)
+		C.XFreeCursor(w.x, w.customCursor)
+		w.customCursor = 0
+	}
+	if img == nil {
+		return true
+	}
+	size := img.Bounds().Size()
+	ximg := C.XcursorImageCreate(C.int(size.X), C.int(size.Y))
+	if ximg == nil {
+		return false
+	}
+	defer C.XcursorImageDestroy(ximg)
+	ximg.xhot = C.XcursorDim(hotspot.X)
+	ximg.yhot = C.XcursorDim(hotspot.Y)
+	// The pixels are premultiplied ARGB.
+	pixels := unsafe.Slice(ximg.pixels, size.X*size.Y)
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			c := img.NRGBAAt(x, y)
+			a := uint32(c.A)
+			r, g, b := uint32(c.R)*a/0xff, uint32(c.G)*a/0xff, uint32(c.B)*a/0xff
+			pixels[y*size.X+x] = C.XcursorPixel(a<<24 | r<<16 | g<<8 | b)
+		}
+	}
+	w.customCursor = C.XcursorImageLoadCursor(w.x, ximg)
+	if w.customCursor == 0 {
+		return false
+	}
+	if w.cursor == pointer.CursorNone {
+		w.cursor = pointer.CursorDefault
+		C.XFixesShowCursor(w.x, w.xw)
+	}
+	C.XDefineCursor(w.x, w.xw, w.customCursor)
+	return true
+}
+
+func (w *x11Window) SetRelativeMouse(enable bool) {
+	if enable == w.relMouse {
+		return
+	}
+	w.relMouse = enable
+	if !enable {
+		if !w.confined {
+			C.XUngrabPointer(w.x, C.CurrentTime)
+		}
+		w.SetCursorPos(w.relAnchor)
+		if w.cursor != pointer.CursorNone {
+			C.XFixesShowCursor(w.x, w.xw)
+		}
+		return
+	}
+	var (
+		root, child              C.Window
+		rootX, rootY, winX, winY C.int
+		mask                     C.uint
+	)
+	C.XQueryPointer(w.x, w.xw, &root, &child, &rootX, &rootY, &winX, &winY, &mask)
+	w.relAnchor = image.Pt(int(winX), int(winY))
+	if w.cursor != pointer.CursorNone {
+		C.XFixesHideCursor(w.x, w.xw)
+	}
+	w.grabPointer()
+	w.SetCursorPos(w.config.Size.Div(2))
+}
+
+func (w *x11Window) SetLayoutDirection(dir system.TextDirection) {}
+
+func (w *x11Window) SetCursorConfined(confine bool) {
+	if confine == w.confined {
+		return
+	}
+	w.confined = confine
+	switch {
+	case w.relMouse:
+		// The pointer is grabbed regardless.
+	case confine && w.focused:
+		w.grabPointer()
+	case !confine:
+		C.XUngrabPointer(w.x, C.CurrentTime)
+	}
+}
+
+// grabPointer confines the pointer to the window.
+func (w *x11Window) grabPointer() {
+	const evMask = C.ButtonPressMask | C.ButtonReleaseMask | C.PointerMotionMask
+	C.XGrabPointer(w.x, w.xw, C.True, evMask, C.GrabModeAsync, C.GrabModeAsync, w.xw, C.None, C.CurrentTime)
+}
+
+func (w *x11Window) SetInputShape(shape []image.Rectangle) {
+	region := C.XserverRegion(C.None)
+	if shape != nil {
+		rects := make([]C.XRectangle, len(shape))
+		for i, r := range shape {
+			rects[i] = C.XRectangle{
+				x:      C.short(r.Min.X),
+				y:      C.short(r.Min.Y),
+				width:  C.ushort(r.Dx()),
+				height: C.ushort(r.Dy()),
+			}
+		}
+		var ptr *C.XRectangle
+		if len(rects) > 0 {
+			ptr = &rects[0]
+		}
+		region = C.XFixesCreateRegion(w.x, ptr, C.int(len(rects)))
+		defer C.XFixesDestroyRegion(w.x, region)
+	}
+	// The None region restores the default input shape.
+	C.XFixesSetWindowShapeRegion(w.x, w.xw, C.ShapeInput, 0, 0, region)
+	C.XFlush(w.x)
+}
+
+func (w *x11Window) SetCursorPos(pos image.Point) {
+	C.XWarpPointer(w.x, C.None, w.xw, 0, 0, 0, 0, C.int(pos.X), C.int(pos.Y))
+	C.XFlush(w.x)
+}
+
 func (w *x11Window) ShowTextInput(show bool) {}
 
-func (w *x11Window) SetInputHint(_ key.InputHint) {}
+func (w *x11Window) SetInputHint(_ key.InputHint, _ key.InputOptions) {}
 
 func (w *x11Window) EditorStateChanged(old, new editorState) {}
 
 // close the window.
 func (w *x11Window) close() {
+	w.closing = true
 	var xev C.XEvent
 	ev := (*C.XClientMessageEvent)(unsafe.Pointer(&xev))
 	*ev = C.XClientMessageEvent{
@@ -456,19 +1278,36 @@ loop:
 		default:
 		}
 
-		if (anim || syn) && w.config.Size.X != 0 && w.config.Size.Y != 0 {
-			w.w.Event(frameEvent{
-				FrameEvent: system.FrameEvent{
-					Now:    time.Now(),
-					Size:   w.config.Size,
-					Metric: w.metric,
-				},
-				Sync: syn,
-			})
+		if anim || syn {
+			w.frame(syn)
 		}
 	}
 }
 
+// frame sends a frameEvent if the window has a size.
+func (w *x11Window) frame(sync bool) {
+	if w.config.Size.X == 0 || w.config.Size.Y == 0 {
+		return
+	}
+	w.w.Event(frameEvent{
+		FrameEvent: system.FrameEvent{
+			Now:    time.Now(),
+			Size:   w.config.Size,
+			Metric: w.metric,
+		},
+		Sync: sync,
+	})
+}
+
+func (w *x11Window) ProcessEvents() {
+	// Fetch the events the server has pending for us.
+	C.XSync(w.x, C.False)
+	h := x11EventHandler{w: w, xev: new(C.XEvent), text: make([]byte, 4)}
+	if h.handleEvents() {
+		w.frame(true)
+	}
+}
+
 func (w *x11Window) destroy() {
 	if w.notify.write != 0 {
 		syscall.Close(w.notify.write)
@@ -482,6 +1321,9 @@ func (w *x11Window) destroy() {
 		w.xkb.Destroy()
 		w.xkb = nil
 	}
+	if w.customCursor != 0 {
+		C.XFreeCursor(w.x, w.customCursor)
+	}
 	C.XDestroyWindow(w.x, w.xw)
 	C.XCloseDisplay(w.x)
 }
@@ -536,6 +1378,10 @@ func (h *x11EventHandler) handleEvents() bool {
 				ks = key.Release
 			}
 			kevt := (*C.XKeyPressedEvent)(unsafe.Pointer(xev))
+			if w.drag.active && ks == key.Press && kevt.keycode == C.uint(C.XKeysymToKeycode(w.x, C.XK_Escape)) {
+				w.cancelDrag()
+				break
+			}
 			for _, e := range h.w.xkb.DispatchKey(uint32(kevt.keycode), ks) {
 				if ee, ok := e.(key.EditEvent); ok {
 					// There's no support for IME yet.
@@ -556,6 +1402,9 @@ func (h *x11EventHandler) handleEvents() bool {
 				Time:      time.Duration(bevt.time) * time.Millisecond,
 				Modifiers: w.xkb.Modifiers(),
 			}
+			if w.relMouse {
+				ev.Position = f32.Point{X: float32(w.relAnchor.X), Y: float32(w.relAnchor.Y)}
+			}
 			if bevt._type == C.ButtonRelease {
 				ev.Type = pointer.Release
 			}
@@ -593,11 +1442,37 @@ func (h *x11EventHandler) handleEvents() bool {
 				w.pointerBtns |= btn
 			case C.ButtonRelease:
 				w.pointerBtns &^= btn
+				if w.drag.active && btn == pointer.ButtonPrimary {
+					w.dragRelease(bevt.time)
+				}
 			}
 			ev.Buttons = w.pointerBtns
 			w.w.Event(ev)
 		case C.MotionNotify:
 			mevt := (*C.XMotionEvent)(unsafe.Pointer(xev))
+			if w.drag.active {
+				w.dragMotion(int(mevt.x_root), int(mevt.y_root), mevt.time, mevt.state)
+				break
+			}
+			if w.relMouse {
+				c := w.config.Size.Div(2)
+				d := f32.Point{X: float32(int(mevt.x) - c.X), Y: float32(int(mevt.y) - c.Y)}
+				if d == (f32.Point{}) {
+					// Ignore the motion caused by the warp below.
+					break
+				}
+				w.SetCursorPos(c)
+				w.w.Event(pointer.Event{
+					Type:      pointer.Move,
+					Source:    pointer.Mouse,
+					Buttons:   w.pointerBtns,
+					Position:  f32.Point{X: float32(w.relAnchor.X), Y: float32(w.relAnchor.Y)},
+					Delta:     d,
+					Time:      time.Duration(mevt.time) * time.Millisecond,
+					Modifiers: w.xkb.Modifiers(),
+				})
+				break
+			}
 			w.w.Event(pointer.Event{
 				Type:    pointer.Move,
 				Source:  pointer.Mouse,
@@ -609,12 +1484,30 @@ func (h *x11EventHandler) handleEvents() bool {
 				Time:      time.Duration(mevt.time) * time.Millisecond,
 				Modifiers: w.xkb.Modifiers(),
 			})
+		case C.VisibilityNotify:
+			vevt := (*C.XVisibilityEvent)(unsafe.Pointer(xev))
+			var visible float32
+			switch vevt.state {
+			case C.VisibilityUnobscured:
+				visible = 1
+			case C.VisibilityPartiallyObscured:
+				visible = .5
+			}
+			w.w.Event(VisibilityEvent{Visible: visible})
 		case C.Expose: // update
 			// redraw only on the last expose event
 			redraw = (*C.XExposeEvent)(unsafe.Pointer(xev)).count == 0
 		case C.FocusIn:
+			w.focused = true
+			if w.confined {
+				w.grabPointer()
+			}
 			w.w.Event(key.FocusEvent{Focus: true})
 		case C.FocusOut:
+			w.focused = false
+			if w.confined && !w.relMouse {
+				C.XUngrabPointer(w.x, C.CurrentTime)
+			}
 			w.w.Event(key.FocusEvent{Focus: false})
 		case C.ConfigureNotify: // window configuration change
 			cevt := (*C.XConfigureEvent)(unsafe.Pointer(xev))
@@ -622,14 +1515,52 @@ func (h *x11EventHandler) handleEvents() bool {
 				w.config.Size = sz
 				w.w.Event(ConfigEvent{Config: w.config})
 			}
+			// Window managers send synthetic events with the position
+			// on the root window.
+			if cevt.send_event != 0 {
+				w.pos = image.Pt(int(cevt.x), int(cevt.y))
+			}
+			w.updateWorkArea()
+		case w.randrEvent:
+			C.XRRUpdateConfiguration(xev)
+			w.monitorsValid = false
+			w.updateWorkArea()
+		case C.PropertyNotify:
+			pevt := (*C.XPropertyEvent)(unsafe.Pointer(xev))
+			if pevt.window == C.XDefaultRootWindow(w.x) && (pevt.atom == w.atoms.workArea || pevt.atom == w.atoms.currentDesktop) {
+				w.monitorsValid = false
+				w.updateWorkArea()
+			}
 			// redraw will be done by a later expose event
 		case C.SelectionNotify:
 			cevt := (*C.XSelectionEvent)(unsafe.Pointer(xev))
+			if read := w.clipboard.read; read != C.None && cevt.target == w.atoms.targets {
+				if cevt.property == w.atoms.clipboardTargets {
+					if data, format := w.property(cevt.property); format == 32 && len(data) > 0 {
+						w.clipboard.types = w.atomNames(data)
+					}
+				}
+				C.XDeleteProperty(w.x, w.xw, w.atoms.clipboardContent)
+				C.XConvertSelection(w.x, w.atoms.clipboard, read, w.atoms.clipboardContent, w.xw, C.CurrentTime)
+				break
+			}
+			if read := w.clipboard.read; read != C.None && cevt.target == read {
+				w.clipboard.read = C.None
+				e := clipboard.Event{
+					Type:  w.atomName(read),
+					Types: w.clipboard.types,
+				}
+				if cevt.property == w.atoms.clipboardContent {
+					e.Data, _ = w.property(cevt.property)
+				}
+				w.w.Event(e)
+				break
+			}
 			prop := w.atoms.clipboardContent
 			if cevt.property != prop {
 				break
 			}
-			if cevt.selection != w.atoms.clipboard {
+			if cevt.selection != w.atoms.clipboard && cevt.selection != w.atoms.primary {
 				break
 			}
 			var text C.XTextProperty
@@ -645,43 +1576,46 @@ func (h *x11EventHandler) handleEvents() bool {
 			w.w.Event(clipboard.Event{Text: str})
 		case C.SelectionRequest:
 			cevt := (*C.XSelectionRequestEvent)(unsafe.Pointer(xev))
+			if cevt.selection == w.atoms.xdndSelection {
+				w.serveDrag(cevt)
+				break
+			}
 			if (cevt.selection != w.atoms.clipboard && cevt.selection != w.atoms.primary) || cevt.property == C.None {
 				// Unsupported clipboard or obsolete requestor.
 				break
 			}
 			notify := func() {
-				var xev C.XEvent
-				ev := (*C.XSelectionEvent)(unsafe.Pointer(&xev))
-				*ev = C.XSelectionEvent{
-					_type:     C.SelectionNotify,
-					display:   cevt.display,
-					requestor: cevt.requestor,
-					selection: cevt.selection,
-					target:    cevt.target,
-					property:  cevt.property,
-					time:      cevt.time,
-				}
-				C.XSendEvent(w.x, cevt.requestor, 0, 0, &xev)
+				w.selectionNotify(cevt, cevt.property)
 			}
 			switch cevt.target {
 			case w.atoms.targets:
 				// The requestor wants the supported clipboard
 				// formats. First write the targets...
-				formats := [...]C.long{
+				formats := []C.long{
 					C.long(w.atoms.targets),
 					C.long(w.atoms.utf8string),
 					C.long(w.atoms.plaintext),
 					// GTK clients need this.
 					C.long(w.atoms.gtk_text_buffer_contents),
 				}
+				if w.clipboard.mime != C.None && cevt.selection == w.atoms.clipboard {
+					formats = append(formats[:1], C.long(w.clipboard.mime))
+				}
 				C.XChangeProperty(w.x, cevt.requestor, cevt.property, w.atoms.atom,
 					32 /* bitwidth of formats */, C.PropModeReplace,
-					(*C.uchar)(unsafe.Pointer(&formats)), C.int(len(formats)),
+					(*C.uchar)(unsafe.Pointer(&formats[0])), C.int(len(formats)),
 				)
 				// ...then notify the requestor.
 				notify()
 			case w.atoms.plaintext, w.atoms.utf8string, w.atoms.gtk_text_buffer_contents:
 				content := w.clipboard.content
+				if cevt.selection == w.atoms.primary {
+					content = w.clipboard.primary
+				}
+				if content == nil && cevt.selection == w.atoms.clipboard {
+					// The clipboard contains non-text data.
+					break
+				}
 				var ptr *C.uchar
 				if len(content) > 0 {
 					ptr = (*C.uchar)(unsafe.Pointer(&content[0]))
@@ -691,11 +1625,33 @@ func (h *x11EventHandler) handleEvents() bool {
 					ptr, C.int(len(content)),
 				)
 				notify()
+			case w.clipboard.mime:
+				data := w.clipboard.data
+				if data == nil || cevt.selection != w.atoms.clipboard {
+					break
+				}
+				var ptr *C.uchar
+				if len(data) > 0 {
+					ptr = (*C.uchar)(unsafe.Pointer(&data[0]))
+				}
+				C.XChangeProperty(w.x, cevt.requestor, cevt.property, cevt.target,
+					8 /* bitwidth */, C.PropModeReplace,
+					ptr, C.int(len(data)),
+				)
+				notify()
 			}
 		case C.ClientMessage: // extensions
 			cevt := (*C.XClientMessageEvent)(unsafe.Pointer(xev))
+			if w.handleDnd(cevt) {
+				break
+			}
 			switch *(*C.long)(unsafe.Pointer(&cevt.data)) {
 			case C.long(w.atoms.evDelWindow):
+				if w.config.DocumentEdited && !w.closing && w.w.WantsCloseRequest() {
+					// Let the program confirm the close.
+					w.w.Event(CloseRequestEvent{})
+					break
+				}
 				w.dead = true
 				return false
 			}
@@ -757,7 +1713,7 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 	cnf.apply(cfg, options)
 
 	swa := C.XSetWindowAttributes{
-		event_mask: C.ExposureMask | C.FocusChangeMask | // update
+		event_mask: C.ExposureMask | C.FocusChangeMask | C.VisibilityChangeMask | // update
 			C.KeyPressMask | C.KeyReleaseMask | // keyboard
 			C.ButtonPressMask | C.ButtonReleaseMask | // mouse clicks
 			C.PointerMotionMask | // mouse movement
@@ -765,18 +1721,38 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 		background_pixmap: C.None,
 		override_redirect: C.False,
 	}
+	var swaMask C.ulong = C.CWEventMask | C.CWBackPixmap | C.CWOverrideRedirect
+	if c := cnf.SplashColor; c.A != 0 {
+		// Let the server fill the window until the first frame. The
+		// pixel value assumes the common 24-bit TrueColor visual.
+		swa.background_pixel = C.ulong(c.R)<<16 | C.ulong(c.G)<<8 | C.ulong(c.B)
+		swaMask = swaMask&^C.CWBackPixmap | C.CWBackPixel
+	}
 	win := C.XCreateWindow(dpy, C.XDefaultRootWindow(dpy),
 		0, 0, C.uint(cnf.Size.X), C.uint(cnf.Size.Y),
 		0, C.CopyFromParent, C.InputOutput, nil,
-		C.CWEventMask|C.CWBackPixmap|C.CWOverrideRedirect, &swa)
+		swaMask, &swa)
+
+	// Watch the monitors and the work area.
+	root := C.XDefaultRootWindow(dpy)
+	randrEvent := C.int(-1)
+	var rrEventBase, rrErrorBase, rrMajor, rrMinor C.int
+	if C.XRRQueryExtension(dpy, &rrEventBase, &rrErrorBase) == C.True &&
+		C.XRRQueryVersion(dpy, &rrMajor, &rrMinor) != 0 &&
+		(rrMajor > 1 || rrMajor == 1 && rrMinor >= 5) {
+		randrEvent = rrEventBase + C.RRScreenChangeNotify
+		C.XRRSelectInput(dpy, root, C.RRScreenChangeNotifyMask)
+	}
+	C.XSelectInput(dpy, root, C.PropertyChangeMask)
 
 	w := &x11Window{
 		w: gioWin, x: dpy, xw: win,
 		metric:       cfg,
 		xkb:          xkb,
 		xkbEventBase: xkbEventBase,
+		randrEvent:   randrEvent,
 		wakeups:      make(chan struct{}, 1),
-		config:       Config{Size: cnf.Size},
+		config:       Config{Size: cnf.Size, Hidden: cnf.Hidden},
 	}
 	w.notify.read = pipe[0]
 	w.notify.write = pipe[1]
@@ -803,31 +1779,53 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 	w.atoms.clipboard = w.atom("CLIPBOARD", false)
 	w.atoms.primary = w.atom("PRIMARY", false)
 	w.atoms.clipboardContent = w.atom("CLIPBOARD_CONTENT", false)
+	w.atoms.clipboardTargets = w.atom("CLIPBOARD_TARGETS", false)
 	w.atoms.atom = w.atom("ATOM", false)
 	w.atoms.targets = w.atom("TARGETS", false)
 	w.atoms.wmName = w.atom("_NET_WM_NAME", false)
 	w.atoms.wmState = w.atom("_NET_WM_STATE", false)
+	w.atoms.wmMoveResize = w.atom("_NET_WM_MOVERESIZE", false)
 	w.atoms.wmStateFullscreen = w.atom("_NET_WM_STATE_FULLSCREEN", false)
 	w.atoms.wmActiveWindow = w.atom("_NET_ACTIVE_WINDOW", false)
 	w.atoms.wmStateMaximizedHorz = w.atom("_NET_WM_STATE_MAXIMIZED_HORZ", false)
 	w.atoms.wmStateMaximizedVert = w.atom("_NET_WM_STATE_MAXIMIZED_VERT", false)
+	w.atoms.wmStateSkipTaskbar = w.atom("_NET_WM_STATE_SKIP_TASKBAR", false)
+	w.atoms.wmStateSkipPager = w.atom("_NET_WM_STATE_SKIP_PAGER", false)
+	w.atoms.workArea = w.atom("_NET_WORKAREA", false)
+	w.atoms.currentDesktop = w.atom("_NET_CURRENT_DESKTOP", false)
+	w.atoms.xdndAware = w.atom("XdndAware", false)
+	w.atoms.xdndSelection = w.atom("XdndSelection", false)
+	w.atoms.xdndTypeList = w.atom("XdndTypeList", false)
+	w.atoms.xdndEnter = w.atom("XdndEnter", false)
+	w.atoms.xdndPosition = w.atom("XdndPosition", false)
+	w.atoms.xdndStatus = w.atom("XdndStatus", false)
+	w.atoms.xdndLeave = w.atom("XdndLeave", false)
+	w.atoms.xdndDrop = w.atom("XdndDrop", false)
+	w.atoms.xdndFinished = w.atom("XdndFinished", false)
+	w.atoms.xdndActionCopy = w.atom("XdndActionCopy", false)
+	w.atoms.xdndActionMove = w.atom("XdndActionMove", false)
 
 	// extensions
 	C.XSetWMProtocols(dpy, win, &w.atoms.evDelWindow, 1)
 
-	go func() {
+	gioWin.Spawn(func() {
 		w.w.SetDriver(w)
+		w.updateWorkArea()
 
-		// make the window visible on the screen
-		C.XMapWindow(dpy, win)
+		if !cnf.Hidden {
+			// make the window visible on the screen
+			C.XMapWindow(dpy, win)
+		}
 		w.Configure(options)
 		w.w.Event(X11ViewEvent{Display: unsafe.Pointer(dpy), Window: uintptr(win)})
-		w.setStage(system.StageRunning)
+		if !w.config.Hidden {
+			w.setStage(system.StageRunning)
+		}
 		w.loop()
 		w.w.Event(X11ViewEvent{})
 		w.w.Event(system.DestroyEvent{Err: nil})
 		w.destroy()
-	}()
+	})
 	return nil
 }
 
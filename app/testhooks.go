@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build giotest
+// +build giotest
+
+package app
+
+// ForceContextLoss makes the next frame of w fail as if the GPU
+// device was lost, exercising the path that recreates the GPU
+// context. It is available with the giotest build tag and meant
+// for testing GPU recovery.
+func (w *Window) ForceContextLoss() {
+	w.driverDefer(func(d driver) {
+		w.loseContext = true
+	})
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build giotest
+// +build giotest
+
+package app
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"gioui.org/gpu"
+	"gioui.org/op"
+)
+
+type lossGPU struct {
+	fakeGPU
+	frames int
+}
+
+func (g *lossGPU) Clear(color.NRGBA)                {}
+func (g *lossGPU) SetTessellationTolerance(float32) {}
+func (g *lossGPU) SetMaxInFlightFrames(int)         {}
+func (g *lossGPU) CanDrawTo() bool                  { return false }
+func (g *lossGPU) Frame(*op.Ops, gpu.RenderTarget, image.Point) error {
+	g.frames++
+	return nil
+}
+
+type lossContext struct {
+	fakeContext
+	released bool
+}
+
+func (c *lossContext) API() gpu.API                            { return nil }
+func (c *lossContext) RenderTarget() (gpu.RenderTarget, error) { return nil, nil }
+func (c *lossContext) Refresh() error                          { return nil }
+func (c *lossContext) Present() error                          { return nil }
+func (c *lossContext) Release()                                { c.released = true }
+
+type lossDriver struct {
+	driver
+	contexts []*lossContext
+}
+
+func (d *lossDriver) NewContext() (context, error) {
+	c := new(lossContext)
+	d.contexts = append(d.contexts, c)
+	return c, nil
+}
+
+func TestForceContextLoss(t *testing.T) {
+	var gpus []*lossGPU
+	defer func(f func(gpu.API) (gpu.GPU, error)) { newGPU = f }(newGPU)
+	newGPU = func(gpu.API) (gpu.GPU, error) {
+		g := new(lossGPU)
+		gpus = append(gpus, g)
+		return g, nil
+	}
+	w := &Window{
+		driverFuncs: make(chan func(d driver), 1),
+		wakeups:     make(chan struct{}, 1),
+		dead:        make(chan struct{}),
+	}
+	d := new(lossDriver)
+	frame := func() {
+		t.Helper()
+		if err := w.validateAndProcess(d, image.Pt(10, 10), false, time.Now(), new(op.Ops), new(op.Ops), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frame()
+	w.ForceContextLoss()
+	(<-w.driverFuncs)(d)
+	frame()
+	if n := len(d.contexts); n != 2 {
+		t.Fatalf("got %d contexts, want the lost context replaced", n)
+	}
+	if !gpus[0].released || !d.contexts[0].released {
+		t.Error("the lost GPU and context weren't released")
+	}
+	if g := gpus[len(gpus)-1]; g.released || g.frames == 0 {
+		t.Error("the frame wasn't drawn with a new GPU")
+	}
+	frame()
+	if len(d.contexts) != 2 {
+		t.Error("the context was replaced without a forced loss")
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tracer records the stages of frames in the Chrome trace event format,
+// for StartTrace and StopTrace.
+type tracer struct {
+	// enabled is non-zero while tracing. Accessed atomically.
+	enabled int32
+	mu      sync.Mutex
+	out     io.Writer
+	start   time.Time
+	events  []traceEvent
+}
+
+// The thread IDs of trace events. Frames are handled and drawn on the
+// window thread, and presented there or on the presenter thread.
+const (
+	traceWindowThread    = 1
+	tracePresenterThread = 2
+)
+
+// traceEvent is a complete event of the trace event format. Times are
+// in microseconds.
+type traceEvent struct {
+	Name  string                 `json:"name"`
+	Phase string                 `json:"ph"`
+	TS    float64                `json:"ts"`
+	Dur   float64                `json:"dur"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// StartTrace starts recording the timings of the frames drawn by the
+// window: the program's handling of the FrameEvent, the drawing and
+// the presentation, and the frame as a whole. The trace is written to
+// out by StopTrace in the JSON trace event format understood by
+// chrome://tracing and Perfetto. The GPU stages of frames are included
+// while a profile.Op requests them.
+//
+// StartTrace discards the trace in progress, if any.
+func (w *Window) StartTrace(out io.Writer) {
+	t := &w.trace
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out = out
+	t.start = time.Now()
+	t.events = nil
+	atomic.StoreInt32(&t.enabled, 1)
+}
+
+// StopTrace stops the recording started by StartTrace and writes the
+// trace.
+func (w *Window) StopTrace() error {
+	t := &w.trace
+	t.mu.Lock()
+	atomic.StoreInt32(&t.enabled, 0)
+	out, events := t.out, t.events
+	t.out, t.events = nil, nil
+	t.mu.Unlock()
+	if out == nil {
+		return errors.New("app: no trace in progress")
+	}
+	// Name the threads.
+	threads := []struct {
+		tid  int
+		name string
+	}{{traceWindowThread, "Window"}, {tracePresenterThread, "Presenter"}}
+	for _, th := range threads {
+		events = append(events, traceEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			PID:   1,
+			TID:   th.tid,
+			Args:  map[string]interface{}{"name": th.name},
+		})
+	}
+	return json.NewEncoder(out).Encode(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{events, "ms"})
+}
+
+// active reports whether a trace is being recorded.
+func (t *tracer) active() bool {
+	return atomic.LoadInt32(&t.enabled) != 0
+}
+
+// span records a stage of a frame on the thread tid that started at
+// start and lasted dur.
+func (t *tracer) span(name string, tid int, start time.Time, dur time.Duration, args map[string]interface{}) {
+	if !t.active() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.out == nil {
+		return
+	}
+	t.events = append(t.events, traceEvent{
+		Name:  name,
+		Phase: "X",
+		TS:    float64(start.Sub(t.start)) / float64(time.Microsecond),
+		Dur:   float64(dur) / float64(time.Microsecond),
+		PID:   1,
+		TID:   tid,
+		Args:  args,
+	})
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import "sync"
+
+// transactionMu guards the state of every Transaction and the
+// transaction field of every Window.
+var transactionMu sync.Mutex
+
+// Transaction defers the presentation of the frames of a set of
+// windows until Commit.
+type Transaction struct {
+	// windows are the participating windows.
+	windows map[*Window]struct{}
+	// pending are the windows with frames waiting for Commit.
+	pending map[*Window]struct{}
+}
+
+// BeginTransaction starts deferring the presentation of the frames of
+// windows until Commit, so that the frames of related windows, such as
+// a main view and a detached panel, appear together. Frames of other
+// windows are presented as usual. BeginTransaction panics if a window
+// participates in another transaction that is not yet committed.
+//
+// A window that draws another frame before Commit presents its deferred
+// frame first. A window destroyed before Commit leaves the transaction.
+// The platforms don't support atomic presentation across windows, so
+// frames presented together by Commit may still appear in different
+// display refreshes, but no window shows its new frame before the
+// others are ready.
+func BeginTransaction(windows ...*Window) *Transaction {
+	transactionMu.Lock()
+	defer transactionMu.Unlock()
+	t := &Transaction{
+		windows: make(map[*Window]struct{}),
+		pending: make(map[*Window]struct{}),
+	}
+	for _, w := range windows {
+		if w.transaction != nil && w.transaction != t {
+			panic("app: window already participates in a transaction")
+		}
+		w.transaction = t
+		t.windows[w] = struct{}{}
+	}
+	return t
+}
+
+// Commit ends the transaction and presents the deferred frames of the
+// participating windows. Commit does nothing if the transaction is
+// already committed.
+func (t *Transaction) Commit() {
+	transactionMu.Lock()
+	pending := t.pending
+	for w := range t.windows {
+		w.transaction = nil
+	}
+	t.windows = nil
+	t.pending = nil
+	transactionMu.Unlock()
+	for w := range pending {
+		w := w
+		w.driverDefer(func(d driver) {
+			w.presentPending(d)
+		})
+	}
+}
+
+// deferPresent reports whether the present of w's frame is deferred by
+// a transaction, and adds w to its pending windows if so.
+func deferPresent(w *Window) bool {
+	transactionMu.Lock()
+	defer transactionMu.Unlock()
+	t := w.transaction
+	if t == nil {
+		return false
+	}
+	t.pending[w] = struct{}{}
+	return true
+}
+
+// leaveTransaction removes the destroyed window w from its transaction,
+// if any.
+func leaveTransaction(w *Window) {
+	transactionMu.Lock()
+	defer transactionMu.Unlock()
+	t := w.transaction
+	if t == nil {
+		return
+	}
+	w.transaction = nil
+	delete(t.windows, w)
+	delete(t.pending, w)
+}
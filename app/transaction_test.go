@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import "testing"
+
+func TestTransaction(t *testing.T) {
+	w1, w2, other := new(Window), new(Window), new(Window)
+	tx := BeginTransaction(w1, w2)
+	if deferPresent(other) {
+		t.Error("deferred present of a window outside the transaction")
+	}
+	if !deferPresent(w1) || !deferPresent(w2) {
+		t.Fatal("didn't defer present of participating windows")
+	}
+	// A destroyed window leaves the transaction.
+	leaveTransaction(w1)
+	if _, ok := tx.pending[w1]; ok {
+		t.Error("destroyed window is still pending")
+	}
+	if deferPresent(w1) {
+		t.Error("deferred present of a destroyed window")
+	}
+	// Commit presents w2 through its event loop; pretend it is dead.
+	w2.dead = make(chan struct{})
+	close(w2.dead)
+	tx.Commit()
+	if deferPresent(w2) {
+		t.Error("deferred present after Commit")
+	}
+	// The windows can participate in a new transaction.
+	BeginTransaction(w1, w2).Commit()
+}
@@ -24,11 +24,13 @@ type vkContext struct {
 	presentSem vk.Semaphore
 	fence      vk.Fence
 
-	swchain    vk.Swapchain
-	imgs       []vk.Image
-	views      []vk.ImageView
-	fbos       []vk.Framebuffer
-	format     vk.Format
+	swchain vk.Swapchain
+	imgs    []vk.Image
+	views   []vk.ImageView
+	fbos    []vk.Framebuffer
+	format  vk.Format
+	// mode is the present mode of the swapchain.
+	mode       PresentMode
 	presentIdx int
 }
 
@@ -165,7 +167,7 @@ func (c *vkContext) destroySwapchain() {
 	}
 }
 
-func (c *vkContext) refresh(surf vk.Surface, width, height int) error {
+func (c *vkContext) refresh(surf vk.Surface, width, height int, mode PresentMode) error {
 	vk.DeviceWaitIdle(c.dev)
 
 	c.destroyImageViews()
@@ -179,7 +181,16 @@ func (c *vkContext) refresh(surf vk.Surface, width, height int) error {
 	if width < minExt.X || maxExt.X < width || height < minExt.Y || maxExt.Y < height {
 		return errOutOfDate
 	}
-	swchain, imgs, format, err := vk.CreateSwapchain(c.physDev, c.dev, surf, width, height, c.swchain)
+	var prefer []vk.PresentMode
+	switch mode {
+	case PresentFIFO:
+		prefer = []vk.PresentMode{vk.PRESENT_MODE_FIFO_KHR}
+	case PresentMailbox:
+		prefer = []vk.PresentMode{vk.PRESENT_MODE_MAILBOX_KHR, vk.PRESENT_MODE_FIFO_KHR}
+	case PresentImmediate:
+		prefer = []vk.PresentMode{vk.PRESENT_MODE_IMMEDIATE_KHR, vk.PRESENT_MODE_FIFO_KHR}
+	}
+	swchain, imgs, format, vkMode, err := vk.CreateSwapchain(c.physDev, c.dev, surf, width, height, c.swchain, prefer...)
 	if c.swchain != 0 {
 		vk.DestroySwapchain(c.dev, c.swchain)
 		c.swchain = 0
@@ -190,6 +201,14 @@ func (c *vkContext) refresh(surf vk.Surface, width, height int) error {
 	c.swchain = swchain
 	c.imgs = imgs
 	c.format = format
+	switch vkMode {
+	case vk.PRESENT_MODE_MAILBOX_KHR:
+		c.mode = PresentMailbox
+	case vk.PRESENT_MODE_IMMEDIATE_KHR:
+		c.mode = PresentImmediate
+	default:
+		c.mode = PresentFIFO
+	}
 	pass, err := vk.CreateRenderPass(
 		c.dev,
 		format,
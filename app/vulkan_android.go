@@ -55,6 +55,14 @@ func (c *wlVkContext) API() gpu.API {
 	return c.ctx.api()
 }
 
+func (c *wlVkContext) swapchainImages() int {
+	return len(c.ctx.imgs)
+}
+
+func (c *wlVkContext) presentMode() PresentMode {
+	return c.ctx.mode
+}
+
 func (c *wlVkContext) Release() {
 	c.ctx.release()
 	if c.surf != 0 {
@@ -86,5 +94,5 @@ func (c *wlVkContext) Refresh() error {
 		return err
 	}
 	c.surf = surf
-	return c.ctx.refresh(c.surf, w, h)
+	return c.ctx.refresh(c.surf, w, h, c.win.callbacks.PresentMode())
 }
@@ -58,6 +58,14 @@ func (c *wlVkContext) API() gpu.API {
 	return c.ctx.api()
 }
 
+func (c *wlVkContext) swapchainImages() int {
+	return len(c.ctx.imgs)
+}
+
+func (c *wlVkContext) presentMode() PresentMode {
+	return c.ctx.mode
+}
+
 func (c *wlVkContext) Release() {
 	c.ctx.release()
 	vk.DestroySurface(c.inst, c.surf)
@@ -77,5 +85,5 @@ func (c *wlVkContext) Unlock() {}
 
 func (c *wlVkContext) Refresh() error {
 	_, w, h := c.win.surface()
-	return c.ctx.refresh(c.surf, w, h)
+	return c.ctx.refresh(c.surf, w, h, c.win.w.PresentMode())
 }
@@ -58,6 +58,14 @@ func (c *x11VkContext) API() gpu.API {
 	return c.ctx.api()
 }
 
+func (c *x11VkContext) swapchainImages() int {
+	return len(c.ctx.imgs)
+}
+
+func (c *x11VkContext) presentMode() PresentMode {
+	return c.ctx.mode
+}
+
 func (c *x11VkContext) Release() {
 	c.ctx.release()
 	vk.DestroySurface(c.inst, c.surf)
@@ -77,5 +85,5 @@ func (c *x11VkContext) Unlock() {}
 
 func (c *x11VkContext) Refresh() error {
 	_, w, h := c.win.window()
-	return c.ctx.refresh(c.surf, w, h)
+	return c.ctx.refresh(c.surf, w, h, c.win.w.PresentMode())
 }
@@ -7,7 +7,12 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
+	"math"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
 	"unicode/utf16"
@@ -17,6 +22,7 @@ import (
 	"gioui.org/font/opentype"
 	"gioui.org/gpu"
 	"gioui.org/internal/ops"
+	"gioui.org/io/clipboard"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
 	"gioui.org/io/pointer"
@@ -25,6 +31,8 @@ import (
 	"gioui.org/io/system"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
@@ -54,12 +62,24 @@ type Window struct {
 	redraws chan struct{}
 	// immediateRedraws is like redraw but doesn't need a wakeup.
 	immediateRedraws chan struct{}
-	// scheduledRedraws is sent the most recent delayed redraw time.
+	// scheduledRedraws is sent the most recent delayed redraw time, or
+	// the zero time to cancel the delayed redraw.
 	scheduledRedraws chan time.Time
 	// options are the options waiting to be applied.
 	options chan []Option
 	// actions are the actions waiting to be performed.
 	actions chan system.Action
+	// tickIntervals is sent the most recent background tick interval.
+	tickIntervals chan time.Duration
+	// ticks is notified when a background tick is due.
+	ticks chan time.Time
+
+	// opsPool holds operation lists released by ReleaseOps.
+	opsPool chan *op.Ops
+	// serveOn is set by the ServeOnCaller option, and serveLoops
+	// receives the driver event loop to be run by ServeOn.
+	serveOn    bool
+	serveLoops chan func()
 
 	out      chan event.Event
 	frames   chan *op.Ops
@@ -68,15 +88,35 @@ type Window struct {
 	// dead is closed when the window is destroyed.
 	dead chan struct{}
 
-	stage        system.Stage
+	stage system.Stage
+	// zeroSized is set while the window is paused because of a zero
+	// size, and sizedStage is the stage to restore once it has a size.
+	zeroSized    bool
+	sizedStage   system.Stage
 	animating    bool
 	hasNextFrame bool
 	nextFrame    time.Time
+	// nextReason is the reason the next frame was scheduled.
+	nextReason RedrawReason
+	// insets are the insets of the latest frame, guarded by insetsMu.
+	insetsMu sync.Mutex
+	insets   Insets
 	// viewport is the latest frame size with insets applied.
 	viewport image.Rectangle
 	// metric is the metric from the most recent frame.
 	metric unit.Metric
 
+	// customCursor is set while the cursor image set by SetCustomCursor
+	// replaces the cursors of the frame.
+	customCursor bool
+	// cursorPos is the most recent mouse position, valid if cursorIn,
+	// and softCursor is set when the program draws the custom cursor.
+	// They are guarded by cursorMu.
+	cursorMu   sync.Mutex
+	cursorPos  f32.Point
+	cursorIn   bool
+	softCursor bool
+
 	queue       queue
 	cursor      pointer.Cursor
 	decorations struct {
@@ -96,6 +136,141 @@ type Window struct {
 	callbacks callbacks
 
 	nocontext bool
+	// glVersion is the requested OpenGL version. See GLVersion.
+	glVersion GLContextVersion
+	// presentMode is the requested present mode.
+	presentMode PresentMode
+	// forcedMetric is the metric set by ForceMetric.
+	forcedMetric unit.Metric
+	// colorSpace is the requested framebuffer color space.
+	colorSpace ColorSpace
+	// refreshContext forces a refresh of the GPU context for the
+	// next frame.
+	refreshContext bool
+	// caps describes the GPU context, guarded by capsMu. See Caps.
+	capsMu sync.Mutex
+	caps   Caps
+	// pendingPresent is set when the present of the most recent frame
+	// is deferred until a transaction commits. See BeginTransaction.
+	pendingPresent bool
+	// transaction is the transaction w participates in, guarded by
+	// transactionMu.
+	transaction *Transaction
+	// presentDur is the duration of the most recent profiled Present.
+	presentDur time.Duration
+	// decoupled is set by SetDecoupledPresent, and presenter presents
+	// frames while it is set.
+	decoupled bool
+	presenter presenter
+	// frameID is the ID of the most recently presented frame.
+	frameID FrameID
+	// captureFrame is the callback set by SetFrameCaptureCallback, and
+	// captured the capture of the frame being presented.
+	captureFrame func(img *image.RGBA, id FrameID)
+	captured     *image.RGBA
+	// readbackReqs are the functions passed to ReadbackAsync for the
+	// next frame, guarded by readbackMu.
+	readbackMu   sync.Mutex
+	readbackReqs []func(img *image.RGBA, id FrameID)
+	// readbacks are the readbacks of drawn frames, in order.
+	readbacks []*frameReadback
+	// screenshots are the pending requests of ScreenshotRect.
+	screenshots []screenshotRequest
+	// idleTimeout is set by SetIdleTimeout, and idleTimer fires after
+	// idleTimeout without input since lastInput. idle is set after an
+	// IdleEvent.
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	lastInput   time.Time
+	idle        bool
+	// visible is the visible fraction of the most recent
+	// VisibilityEvent, plus one to distinguish the zero value.
+	visible float32
+	// touchSlop is the touch slop set by SetTouchSlop.
+	touchSlop unit.Dp
+	// pxPerDp is the PxPerDp of the most recent frame metric, stored as
+	// math.Float32bits and accessed atomically.
+	pxPerDp uint32
+	// tolerance is the tessellation tolerance set by
+	// SetTessellationTolerance.
+	tolerance float32
+	// maxInFlight is the limit set by SetMaxInFlightFrames.
+	maxInFlight int
+	// overlay is the function set by SetOverlay.
+	overlay func(o *op.Ops, size image.Point)
+	// scaled is the state of SetRenderScale and SetResolutionScale. The
+	// frame is drawn into img and painted scaled to the window, while
+	// input are the operations that route input through the scale.
+	scaled struct {
+		size   image.Point
+		filter paint.ImageFilter
+		res    float32
+		img    paint.ImageOp
+		input  op.Ops
+	}
+	// offscreen are the drawings scheduled by DrawTo, textures the
+	// textures created by NewTexture and layers the layers created by
+	// CacheLayer, guarded by gpuMu. texturesChanged is set when textures
+	// is modified.
+	gpuMu           sync.Mutex
+	offscreen       []offscreenDraw
+	textures        map[*Texture]struct{}
+	layers          map[*Layer]struct{}
+	texturesChanged bool
+	// pinned are the textures pinned in the current GPU, or nil if
+	// the GPU is new.
+	pinned map[*Texture]struct{}
+	// timings is the most recent profiling breakdown and frameDurs the
+	// most recent frame durations, both guarded by timingsMu. See
+	// FrameTimings and FrameHistogram.
+	timingsMu sync.Mutex
+	timings   FrameTimings
+	frameDurs frameHistory
+	// latencies are the most recent input latencies, guarded by
+	// timingsMu. See InputLatency.
+	latencies frameHistory
+	// trackLatency is set by SetLatencyTracking. latencyInput is the
+	// time of the first input event handled since the most recent
+	// present, or zero. latencyBase estimates the time base of
+	// pointer.Event.Time.
+	trackLatency bool
+	latencyInput time.Time
+	latencyBase  time.Time
+	// allocProfiling is set by SetAllocProfiling. allocStart and
+	// gcStart are the allocated bytes and garbage collections at the
+	// start of the current frame.
+	allocProfiling bool
+	allocStart     uint64
+	gcStart        uint32
+	// scheduled is a copy of hasNextFrame and nextFrame for
+	// NeedsRedraw and NextFrameTime, and the reason of the most recent
+	// frame for LastRedrawReason, guarded by scheduledMu.
+	scheduledMu sync.Mutex
+	scheduled   struct {
+		ok         bool
+		at         time.Time
+		lastReason RedrawReason
+	}
+	// pressed is a copy of the router's pressed keys for PressedKeys,
+	// guarded by pressedMu.
+	pressedMu sync.Mutex
+	pressed   []string
+	// title is the most recent title set by an option, guarded by
+	// titleMu.
+	titleMu sync.Mutex
+	title   string
+	// refreshRate is the refresh rate of the display in Hz, stored as
+	// math.Float64bits and accessed atomically. Zero means unknown.
+	refreshRate uint64
+	// workArea is the work area of the display, reported by the driver.
+	workAreaMu sync.Mutex
+	workArea   image.Rectangle
+	// appClosed is non-zero when the program requested the window
+	// to close through Perform. Accessed atomically.
+	appClosed int32
+	// loseContext forces the next GPU frame to fail with
+	// gpu.ErrDeviceLost. See ForceContextLoss.
+	loseContext bool
 
 	// semantic data, lazily evaluated if requested by a backend to speed up
 	// the cases where semantic data is not needed.
@@ -109,11 +284,59 @@ type Window struct {
 	}
 
 	imeState editorState
+
+	// rawInput is the handler set by SetRawInputHandler.
+	rawInput func(e event.Event) bool
+	// gpuRelease are the functions added by OnGPURelease.
+	gpuRelease []func()
+	// keepGPU is set by SetKeepGPUOnPause.
+	keepGPU bool
+	// locale is the system locale, and layoutDir the direction set by
+	// SetLayoutDirection if layoutDirSet. They are guarded by localeMu.
+	// hintedDir is the direction most recently hinted to the driver.
+	localeMu     sync.Mutex
+	locale       system.Locale
+	layoutDir    system.TextDirection
+	layoutDirSet bool
+	hintedDir    system.TextDirection
+	hinted       bool
+	// forcedColors is the high contrast mode of the system, guarded
+	// by localeMu.
+	forcedColors ForcedColors
+	// benchmark is the function set by SetBenchmark. benchStart is the
+	// start of the most recently drawn frame, and benchDraw the duration
+	// of its drawing.
+	benchmark  func(BenchmarkFrame)
+	benchStart time.Time
+	benchDraw  time.Duration
+	// trace records the frame timings for StartTrace.
+	trace tracer
+	// panicHandler is the handler set by SetPanicHandler.
+	panicHandler func(v interface{})
+	// middleware is the chain of functions added by Use.
+	middleware []func(e event.Event) event.Event
+	// eventFilter is the set of event types selected by SetEventFilter,
+	// or zero if every type is delivered.
+	eventFilter uint32
+	// animations are the functions added by Animate.
+	animations []func(now time.Time) bool
+	// clock is the state of PauseClock and ResumeClock. Frame times are
+	// offset by the time the clock was paused. While paused, frame times
+	// are frozen at the pause time, pausedAt, of the wall clock.
+	clock struct {
+		paused   bool
+		pausedAt time.Time
+		offset   time.Duration
+	}
+	// preDraw is the function set by SetPreDraw.
+	preDraw func(now time.Time)
 }
 
 type editorState struct {
 	router.EditorState
 	compose key.Range
+	// target is the focused key handler receiving the text input.
+	target event.Tag
 }
 
 type callbacks struct {
@@ -129,6 +352,10 @@ type queue struct {
 	q router.Router
 }
 
+// opsPoolSize is the maximum number of operation lists kept
+// for re-use by AcquireOps, enough for triple buffering.
+const opsPoolSize = 3
+
 // NewWindow creates a new window for a set of window
 // options. The options are hints; the platform is free to
 // ignore or adjust them.
@@ -158,6 +385,8 @@ func NewWindow(options ...Option) *Window {
 		Size(800, 600),
 		Title("Gio"),
 		Decorated(true),
+		Resizable(true),
+		AcceptFirstMouse(runtime.GOOS != "darwin"),
 		decoHeightOpt(decoHeight),
 	}
 	options = append(defaultOptions, options...)
@@ -178,7 +407,19 @@ func NewWindow(options ...Option) *Window {
 		dead:             make(chan struct{}),
 		options:          make(chan []Option, 1),
 		actions:          make(chan system.Action, 1),
+		tickIntervals:    make(chan time.Duration, 1),
+		ticks:            make(chan time.Time, 1),
+		opsPool:          make(chan *op.Ops, opsPoolSize),
 		nocontext:        cnf.CustomRenderer,
+		glVersion:        cnf.GLVersion,
+		presentMode:      cnf.PresentMode,
+		forcedMetric:     cnf.ForcedMetric,
+		colorSpace:       cnf.ColorSpace,
+		serveOn:          cnf.serveOnCaller,
+		serveLoops:       make(chan func(), 1),
+		title:            cnf.Title,
+		locale:           systemLocale(),
+		forcedColors:     systemForcedColors(),
 	}
 	w.decorations.Theme = theme
 	w.decorations.Decorations = deco
@@ -187,7 +428,11 @@ func NewWindow(options ...Option) *Window {
 	w.imeState.compose = key.Range{Start: -1, End: -1}
 	w.semantic.ids = make(map[router.SemanticID]router.SemanticNode)
 	w.callbacks.w = w
+	opens := addWindow(w)
 	go w.run(options)
+	if len(opens) > 0 {
+		go w.sendOpens(opens)
+	}
 	return w
 }
 
@@ -197,11 +442,69 @@ func decoHeightOpt(h unit.Dp) Option {
 	}
 }
 
-// Events returns the channel where events are delivered.
+// Events returns the channel where events are delivered. The final
+// event is a system.DestroyEvent, after which the channel is closed, so a
+// loop ranging over the channel always ends with the DestroyEvent. The
+// channel is closed only after the DestroyEvent has been received.
 func (w *Window) Events() <-chan event.Event {
 	return w.out
 }
 
+// Loop runs the event loop of the window, calling f for every event
+// until the window is destroyed. Loop returns the error of the final
+// system.DestroyEvent.
+//
+// If f returns an error, Loop closes the window and returns the error
+// once the window is destroyed. Events delivered while the window is
+// closing are discarded.
+//
+// Android, iOS and JS don't support closing windows. On those platforms
+// Loop returns the error from f immediately, and the events of the
+// still running window are discarded.
+func (w *Window) Loop(f func(e event.Event) error) error {
+	var ferr error
+	for e := range w.Events() {
+		if ferr == nil {
+			if err := f(e); err != nil {
+				ferr = err
+				if !closeSupported() {
+					go w.discardEvents()
+					return ferr
+				}
+				w.Perform(system.ActionClose)
+			}
+		}
+		if e, ok := e.(system.DestroyEvent); ok {
+			if ferr != nil {
+				return ferr
+			}
+			return e.Err
+		}
+	}
+	return ferr
+}
+
+// newGPU creates the GPU for a context. It is a variable to allow
+// tests to substitute a fake GPU.
+var newGPU = gpu.New
+
+// closeSupported reports whether the platform can close a window with
+// system.ActionClose. It is a variable to allow tests to override it.
+var closeSupported = func() bool {
+	switch runtime.GOOS {
+	case "android", "ios", "js":
+		return false
+	}
+	return true
+}
+
+// discardEvents receives and ignores the remaining events of w, so the
+// window doesn't block on an abandoned event loop.
+func (w *Window) discardEvents() {
+	for range w.Events() {
+	}
+}
+
 // update the window contents, input operations declare input handlers,
 // and so on. The supplied operations list completely replaces the window state
 // from previous calls.
@@ -210,7 +513,17 @@ func (w *Window) update(frame *op.Ops) {
 	<-w.frameAck
 }
 
-func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame *op.Ops, sigChan chan<- struct{}) error {
+func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, start time.Time, frame, input *op.Ops, sigChan chan<- struct{}) error {
+	// A frame deferred by a transaction is presented before the next.
+	w.presentPending(d)
+	if err := w.awaitPresent(); err != nil {
+		w.presentFailed(d, err)
+	}
+	w.benchStart = start
+	if w.refreshContext {
+		w.refreshContext = false
+		sync = true
+	}
 	signal := func() {
 		if sigChan != nil {
 			// We're done with frame, let the client continue.
@@ -244,6 +557,7 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 				}
 				return err
 			}
+			w.updateCaps()
 		}
 		if w.ctx != nil {
 			if err := w.ctx.Lock(); err != nil {
@@ -252,16 +566,25 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 			}
 		}
 		if w.gpu == nil && !w.nocontext {
-			gpu, err := gpu.New(w.ctx.API())
+			gpu, err := newGPU(w.ctx.API())
 			if err != nil {
 				w.ctx.Unlock()
 				w.destroyGPU()
 				return err
 			}
 			w.gpu = gpu
+			w.updateCaps()
 		}
 		if w.gpu != nil {
-			if err := w.frame(frame, size); err != nil {
+			drawStart := time.Now()
+			err := w.frame(frame, size)
+			w.benchDraw = time.Since(drawStart)
+			w.trace.span("Draw", traceWindowThread, drawStart, w.benchDraw, nil)
+			if w.loseContext {
+				w.loseContext = false
+				err = gpu.ErrDeviceLost
+			}
+			if err != nil {
 				w.ctx.Unlock()
 				if errors.Is(err, errOutOfDate) {
 					// GPU surface needs refreshing.
@@ -275,23 +598,302 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 				return err
 			}
 		}
-		w.queue.q.Frame(frame)
+		w.queue.q.Frame(input)
 		// Let the client continue as soon as possible, in particular before
 		// a potentially blocking Present.
 		signal()
-		var err error
-		if w.gpu != nil {
-			err = w.ctx.Present()
+		if w.gpu != nil && deferPresent(w) {
 			w.ctx.Unlock()
+			w.pendingPresent = true
+			return nil
 		}
-		return err
+		if w.gpu != nil && w.decoupled && decouplable(w.ctx) {
+			w.ctx.Unlock()
+			w.presenter.present(w.ctx, w.queue.q.Profiling() || w.trace.active(), w.completePresent)
+			return nil
+		}
+		return w.present()
+	}
+}
+
+// updateCaps updates the description of the refreshed GPU context.
+func (w *Window) updateCaps() {
+	var caps Caps
+	if c, ok := w.ctx.(swapchainContext); ok {
+		caps.SwapchainImages = c.swapchainImages()
+	}
+	if c, ok := w.ctx.(hdrContext); ok {
+		caps.HDR = c.hdr()
+	}
+	if c, ok := w.ctx.(glesContext); ok {
+		major, minor := c.Version()
+		caps.GLVersion = GLContextVersion{Major: major, Minor: minor, ES: true}
+	}
+	if w.gpu != nil {
+		caps.DrawTo = w.gpu.CanDrawTo()
+	}
+	if w.ctx != nil {
+		caps.PresentMode = PresentFIFO
+		if c, ok := w.ctx.(presentModeContext); ok {
+			caps.PresentMode = c.presentMode()
+		}
+		if c, ok := w.ctx.(colorSpaceContext); ok {
+			caps.ColorSpace = c.colorSpace()
+		}
+	}
+	w.capsMu.Lock()
+	w.caps = caps
+	w.capsMu.Unlock()
+}
+
+// present presents the frame drawn into the locked context, and
+// unlocks it.
+func (w *Window) present() error {
+	var err error
+	if w.gpu != nil {
+		var presentStart time.Time
+		if w.queue.q.Profiling() || w.trace.active() {
+			presentStart = time.Now()
+		}
+		err = w.ctx.Present()
+		if !presentStart.IsZero() {
+			w.presentDur = time.Since(presentStart)
+			w.trace.span("Present", traceWindowThread, presentStart, w.presentDur, nil)
+		}
+		w.ctx.Unlock()
+	}
+	return w.presented(err, time.Now())
+}
+
+// presented completes the present of the most recent frame, which
+// finished at t.
+func (w *Window) presented(err error, t time.Time) error {
+	img := w.captured
+	w.captured = nil
+	// Assign the ID to the readbacks of the frame, or retry them with
+	// the next frame if it wasn't presented.
+	n := 0
+	for _, rb := range w.readbacks {
+		if !rb.presented {
+			if err != nil {
+				w.readbackMu.Lock()
+				w.readbackReqs = append(w.readbackReqs, rb.f)
+				w.readbackMu.Unlock()
+				continue
+			}
+			rb.presented = true
+			rb.id = w.frameID + 1
+		}
+		w.readbacks[n] = rb
+		n++
+	}
+	w.readbacks = w.readbacks[:n]
+	if err == nil {
+		w.frameID++
+		if w.benchmark != nil {
+			w.benchmark(BenchmarkFrame{ID: w.frameID, Frame: t.Sub(w.benchStart), Draw: w.benchDraw})
+		}
+		if img != nil {
+			w.captureFrame(img, w.frameID)
+		}
+		if !w.latencyInput.IsZero() {
+			w.timingsMu.Lock()
+			w.latencies.add(t.Sub(w.latencyInput))
+			w.timingsMu.Unlock()
+			w.latencyInput = time.Time{}
+		}
+	}
+	w.deliverReadbacks()
+	return err
+}
+
+// frameReadback is a readback requested by ReadbackAsync.
+type frameReadback struct {
+	f func(img *image.RGBA, id FrameID)
+	// done is set when the GPU completes the readback, with img nil if
+	// it failed.
+	done bool
+	img  *image.RGBA
+	// presented is set when the frame is presented with id.
+	presented bool
+	id        FrameID
+}
+
+// deliverReadbacks calls the functions of the completed readbacks of
+// presented frames. It must not be called with the context locked,
+// because the functions may call back into the window.
+func (w *Window) deliverReadbacks() {
+	n := 0
+	for _, rb := range w.readbacks {
+		if !rb.done || !rb.presented {
+			w.readbacks[n] = rb
+			n++
+			continue
+		}
+		if rb.img != nil {
+			rb.f(rb.img, rb.id)
+		}
+	}
+	// Clear the delivered readbacks.
+	for i := n; i < len(w.readbacks); i++ {
+		w.readbacks[i] = nil
+	}
+	w.readbacks = w.readbacks[:n]
+}
+
+// readbacksPending reports whether readbacks are waiting for the GPU.
+func (w *Window) readbacksPending() bool {
+	for _, rb := range w.readbacks {
+		if !rb.done {
+			return true
+		}
+	}
+	return false
+}
+
+// awaitPresent waits for the present of the presenter, if any, and
+// completes it.
+func (w *Window) awaitPresent() error {
+	res, ok := w.presenter.wait()
+	if !ok {
+		return nil
+	}
+	if res.profiled {
+		w.presentDur = res.dur
+		w.trace.span("Present", tracePresenterThread, res.done.Add(-res.dur), res.dur, nil)
+	}
+	return w.presented(res.err, res.done)
+}
+
+// completePresent is called by the presenter thread when a present
+// completes, and completes it on the window thread without waiting for
+// the next frame.
+func (w *Window) completePresent() {
+	w.driverDefer(func(d driver) {
+		if err := w.awaitPresent(); err != nil {
+			w.presentFailed(d, err)
+		}
+	})
+}
+
+// decouplable reports whether ctx can be presented from the presenter
+// thread. OpenGL contexts are bound to the thread that draws with them
+// and are presented synchronously.
+func decouplable(ctx context) bool {
+	_, gl := ctx.API().(gpu.OpenGL)
+	return !gl
+}
+
+// presentFailed recovers from a failed present by drawing a fresh
+// frame.
+func (w *Window) presentFailed(d driver, err error) {
+	if !errors.Is(err, errOutOfDate) {
+		w.destroyGPU()
+	}
+	w.refreshContext = true
+	w.setNextFrame(time.Time{}, RedrawSystem)
+	w.updateAnimation(d)
+}
+
+// presenter presents frames on a separate thread for decoupled
+// presentation.
+type presenter struct {
+	reqs    chan presentRequest
+	results chan presentResult
+	busy    bool
+}
+
+type presentRequest struct {
+	ctx      context
+	profiled bool
+	// done is called after the result is available.
+	done func()
+}
+
+type presentResult struct {
+	err      error
+	profiled bool
+	dur      time.Duration
+	done     time.Time
+}
+
+// present starts presenting the current frame of ctx. The context must
+// be unlocked.
+func (p *presenter) present(ctx context, profiled bool, done func()) {
+	if p.reqs == nil {
+		p.reqs = make(chan presentRequest)
+		// The result is buffered so that done can wait for the
+		// window thread.
+		p.results = make(chan presentResult, 1)
+		go p.run(p.reqs, p.results)
+	}
+	p.reqs <- presentRequest{ctx: ctx, profiled: profiled, done: done}
+	p.busy = true
+}
+
+func (p *presenter) run(reqs <-chan presentRequest, results chan<- presentResult) {
+	// Contexts are bound to the thread that locks them.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	for req := range reqs {
+		start := time.Now()
+		err := req.ctx.Lock()
+		if err == nil {
+			err = req.ctx.Present()
+			req.ctx.Unlock()
+		}
+		done := time.Now()
+		results <- presentResult{
+			err:      err,
+			profiled: req.profiled,
+			dur:      done.Sub(start),
+			done:     done,
+		}
+		req.done()
+	}
+}
+
+// wait for the pending present, if any.
+func (p *presenter) wait() (presentResult, bool) {
+	if !p.busy {
+		return presentResult{}, false
+	}
+	p.busy = false
+	return <-p.results, true
+}
+
+// stop the presenter thread.
+func (p *presenter) stop() {
+	p.wait()
+	if p.reqs != nil {
+		close(p.reqs)
+		p.reqs = nil
+		p.results = nil
+	}
+}
+
+// presentPending presents the frame deferred by a transaction, if any.
+func (w *Window) presentPending(d driver) {
+	if !w.pendingPresent {
+		return
+	}
+	w.pendingPresent = false
+	if w.ctx == nil {
+		return
+	}
+	err := w.ctx.Lock()
+	if err == nil {
+		err = w.present()
+	}
+	if err != nil {
+		w.presentFailed(d, err)
 	}
 }
 
 func (w *Window) frame(frame *op.Ops, viewport image.Point) error {
-	if runtime.GOOS == "js" {
+	if runtime.GOOS == "js" || w.decorations.Config.Material != MaterialNone {
 		// Use transparent black when Gio is embedded, to allow mixing of Gio and
-		// foreign content below.
+		// foreign content below, and to let the window material show through.
 		w.gpu.Clear(color.NRGBA{A: 0x00, R: 0x00, G: 0x00, B: 0x00})
 	} else {
 		w.gpu.Clear(color.NRGBA{A: 0xff, R: 0xff, G: 0xff, B: 0xff})
@@ -300,46 +902,281 @@ func (w *Window) frame(frame *op.Ops, viewport image.Point) error {
 	if err != nil {
 		return err
 	}
-	return w.gpu.Frame(frame, target, viewport)
+	w.syncTextures()
+	w.gpu.SetTessellationTolerance(w.tolerance)
+	w.gpu.SetMaxInFlightFrames(w.maxInFlight)
+	layers := w.dirtyLayers()
+	for _, l := range layers {
+		w.gpu.DrawTo(l.ops, l.img)
+	}
+	draws := w.takeOffscreen()
+	for _, o := range draws {
+		w.gpu.DrawTo(o.ops, o.img)
+	}
+	w.captured = nil
+	if err := w.gpu.Frame(frame, target, viewport); err != nil {
+		// Draw again when the frame is retried.
+		w.requeueOffscreen(draws)
+		return err
+	}
+	w.cleanLayers(layers)
+	if w.captureFrame != nil {
+		img := image.NewRGBA(image.Rectangle{Max: viewport})
+		// Skip frames that can't be captured.
+		if err := w.gpu.Capture(frame, img); err == nil {
+			w.captured = img
+		}
+	}
+	for _, s := range w.screenshots {
+		s.result <- w.screenshot(frame, viewport, s.rect)
+	}
+	w.screenshots = nil
+	w.readbackMu.Lock()
+	reqs := w.readbackReqs
+	w.readbackReqs = nil
+	w.readbackMu.Unlock()
+	for _, f := range reqs {
+		// The result is delivered once the frame is presented, outside
+		// the locked context.
+		rb := &frameReadback{f: f}
+		w.readbacks = append(w.readbacks, rb)
+		w.gpu.CaptureAsync(frame, viewport, func(img *image.RGBA, err error) {
+			rb.done = true
+			if err == nil {
+				rb.img = img
+			}
+		})
+	}
+	return nil
 }
 
-func (w *Window) processFrame(d driver, frameStart time.Time) {
-	for k := range w.semantic.ids {
-		delete(w.semantic.ids, k)
+// renderSize returns the size and filter for drawing frames of the
+// window size, and whether they differ from the window size. Frames are
+// drawn at the window size until the GPU is known to support DrawTo.
+func (w *Window) renderSize(size image.Point) (image.Point, paint.ImageFilter, bool) {
+	if w.gpu == nil || !w.gpu.CanDrawTo() || size.X <= 0 || size.Y <= 0 {
+		return size, 0, false
 	}
-	w.semantic.uptodate = false
-	q := &w.queue.q
-	switch q.TextInputState() {
+	if s := w.scaled.size; s != (image.Point{}) {
+		return s, w.scaled.filter, true
+	}
+	if r := w.scaled.res; r > 0 && r < 1 {
+		s := image.Point{
+			X: int(float32(size.X)*r + .5),
+			Y: int(float32(size.Y)*r + .5),
+		}
+		if s.X < 1 {
+			s.X = 1
+		}
+		if s.Y < 1 {
+			s.Y = 1
+		}
+		return s, paint.FilterLinear, true
+	}
+	return size, 0, false
+}
+
+// drawScaled schedules the drawing of frame into the top-left area of
+// the render size of an image, and paints the area scaled into o. The
+// image is at least the window size, so changes of the render size don't
+// allocate new images and textures. With linear filtering, the edges of
+// the area blend slightly with the transparent pixels around it.
+func (w *Window) drawScaled(o *op.Ops, frame *op.Ops, size, windowSize image.Point, filter paint.ImageFilter, scale f32.Point) {
+	imgSize := windowSize
+	if size.X > imgSize.X {
+		imgSize.X = size.X
+	}
+	if size.Y > imgSize.Y {
+		imgSize.Y = size.Y
+	}
+	if s := w.scaled.img.Size(); s.X < imgSize.X || s.Y < imgSize.Y {
+		w.scaled.img = paint.NewImageOp(image.NewRGBA(image.Rectangle{Max: imgSize}))
+	}
+	img := w.scaled.img
+	img.Filter = filter
+	w.DrawTo(img, frame)
+	t := op.Affine(f32.Affine2D{}.Scale(f32.Point{}, scale)).Push(o)
+	cl := clip.Rect{Max: size}.Push(o)
+	img.Add(o)
+	paint.PaintOp{}.Add(o)
+	cl.Pop()
+	t.Pop()
+}
+
+// offscreenDraw is a drawing scheduled by Window.DrawTo.
+type offscreenDraw struct {
+	img paint.ImageOp
+	ops *op.Ops
+}
+
+// syncTextures pins the textures created by NewTexture in the GPU and
+// unpins released textures.
+func (w *Window) syncTextures() {
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	if !w.texturesChanged && w.pinned != nil {
+		return
+	}
+	w.texturesChanged = false
+	if w.pinned == nil {
+		w.pinned = make(map[*Texture]struct{})
+		// The drawings of layers are lost with the previous GPU.
+		for l := range w.layers {
+			l.dirty = true
+		}
+	}
+	for t := range w.pinned {
+		if _, live := w.textures[t]; !live {
+			w.gpu.Unpin(t.img)
+			delete(w.pinned, t)
+		}
+	}
+	for t := range w.textures {
+		if _, pinned := w.pinned[t]; !pinned {
+			w.gpu.Pin(t.img)
+			w.pinned[t] = struct{}{}
+		}
+	}
+}
+
+// layerDraw is the drawing of a layer.
+type layerDraw struct {
+	l       *Layer
+	img     paint.ImageOp
+	ops     *op.Ops
+	version int
+}
+
+// dirtyLayers returns the drawings of the layers that need drawing.
+func (w *Window) dirtyLayers() []layerDraw {
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	var dirty []layerDraw
+	for l := range w.layers {
+		if l.dirty {
+			dirty = append(dirty, layerDraw{l: l, img: l.tex.img, ops: l.ops, version: l.version})
+		}
+	}
+	return dirty
+}
+
+// cleanLayers clears the dirty flags of the layers of draws that are
+// unchanged since they were drawn.
+func (w *Window) cleanLayers(draws []layerDraw) {
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	for _, d := range draws {
+		if d.l.version == d.version {
+			d.l.dirty = false
+		}
+	}
+}
+
+// takeOffscreen returns and clears the drawings scheduled by DrawTo.
+func (w *Window) takeOffscreen() []offscreenDraw {
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	draws := w.offscreen
+	w.offscreen = nil
+	return draws
+}
+
+// requeueOffscreen schedules draws before the drawings scheduled since
+// they were taken.
+func (w *Window) requeueOffscreen(draws []offscreenDraw) {
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	w.offscreen = append(draws, w.offscreen...)
+}
+
+// updateTextInput shows or hides the text input as requested by the
+// router.
+func (w *Window) updateTextInput(d driver) {
+	switch w.queue.q.TextInputState() {
 	case router.TextInputOpen:
 		d.ShowTextInput(true)
 	case router.TextInputClose:
 		d.ShowTextInput(false)
 	}
-	if hint, ok := q.TextInputHint(); ok {
-		d.SetInputHint(hint)
+}
+
+func (w *Window) processFrame(d driver, frameStart time.Time) {
+	for k := range w.semantic.ids {
+		delete(w.semantic.ids, k)
+	}
+	w.semantic.uptodate = false
+	q := &w.queue.q
+	w.updateTextInput(d)
+	if hint, opts, ok := q.TextInputHint(); ok {
+		d.SetInputHint(hint, opts)
 	}
 	if txt, ok := q.WriteClipboard(); ok {
-		d.WriteClipboard(txt)
+		d.WriteClipboard(clipboard.SelectionClipboard, txt)
 	}
 	if q.ReadClipboard() {
-		d.ReadClipboard()
+		d.ReadClipboard(clipboard.SelectionClipboard)
 	}
 	oldState := w.imeState
 	newState := oldState
 	newState.EditorState = q.EditorState()
+	newState.target = q.TextInputTarget()
 	if newState != oldState {
 		w.imeState = newState
 		d.EditorStateChanged(oldState, newState)
 	}
+	frameDur := time.Since(frameStart)
+	w.timingsMu.Lock()
+	w.frameDurs.add(frameDur)
+	w.timingsMu.Unlock()
+	if w.benchmark != nil {
+		// Draw the next frame right away.
+		w.setNextFrame(time.Time{}, RedrawAnimation)
+	}
+	var traceArgs map[string]interface{}
+	if w.trace.active() {
+		traceArgs = map[string]interface{}{"id": w.frameID}
+	}
 	if q.Profiling() && w.gpu != nil {
-		frameDur := time.Since(frameStart)
 		frameDur = frameDur.Truncate(100 * time.Microsecond)
 		quantum := 100 * time.Microsecond
-		timings := fmt.Sprintf("tot:%7s %s", frameDur.Round(quantum), w.gpu.Profile())
+		timings := fmt.Sprintf("tot:%7s %s present:%7s", frameDur.Round(quantum), w.gpu.Profile(), w.presentDur.Round(quantum))
+		ft := FrameTimings{Timings: w.gpu.TimingBreakdown(), Present: w.presentDur}
+		if w.allocProfiling && w.allocStart != 0 {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			ft.Alloc = stats.TotalAlloc - w.allocStart
+			ft.GCs = stats.NumGC - w.gcStart
+			timings += fmt.Sprintf(" alloc:%7dB gc:%d", ft.Alloc, ft.GCs)
+		}
 		q.Queue(profile.Event{Timings: timings})
+		w.timingsMu.Lock()
+		w.timings = ft
+		w.timingsMu.Unlock()
+		if traceArgs != nil {
+			// The GPU stages, in milliseconds.
+			traceArgs["tessellate"] = ft.Tessellate.Seconds() * 1000
+			traceArgs["upload"] = ft.Upload.Seconds() * 1000
+			traceArgs["draw"] = ft.Draw.Seconds() * 1000
+			traceArgs["cleanup"] = ft.Cleanup.Seconds() * 1000
+		}
+	}
+	if traceArgs != nil {
+		w.trace.span("Frame", traceWindowThread, frameStart, time.Since(frameStart), traceArgs)
 	}
 	if t, ok := q.WakeupTime(); ok {
-		w.setNextFrame(t)
+		reason := RedrawAnimation
+		if q.Profiling() {
+			reason = RedrawProfiling
+		}
+		// Wakeup times are frame times.
+		t = t.Add(w.clock.offset)
+		if !w.clock.paused || reason == RedrawProfiling {
+			w.setNextFrame(t, reason)
+		}
+	}
+	if t, ok := q.LongPressTime(); ok {
+		// Long presses are timed by the wall clock.
+		w.setNextFrame(t, RedrawInput)
 	}
 	w.updateAnimation(d)
 }
@@ -365,11 +1202,32 @@ func (w *Window) Invalidate() {
 	}
 }
 
+// InvalidateSync is like Invalidate, but the next frame refreshes the
+// GPU context before drawing, as if the window surface changed. Use it
+// when resources shared with the window's GPU context were modified
+// outside of Gio, such as by interoperating graphics code, to avoid
+// drawing with stale state. A refresh is more expensive than a regular
+// frame.
+//
+// InvalidateSync is safe for concurrent use.
+func (w *Window) InvalidateSync() {
+	w.driverDefer(func(d driver) {
+		w.refreshContext = true
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
 // Option applies the options to the window.
 func (w *Window) Option(opts ...Option) {
 	if len(opts) == 0 {
 		return
 	}
+	w.titleMu.Lock()
+	cnf := Config{Title: w.title}
+	cnf.apply(unit.Metric{}, opts)
+	w.title = cnf.Title
+	w.titleMu.Unlock()
 	for {
 		select {
 		case old := <-w.options:
@@ -381,42 +1239,1306 @@ func (w *Window) Option(opts ...Option) {
 	}
 }
 
+// PressedKeys returns the names of the keys currently held down, in
+// sorted order, for programs such as games that poll the keyboard state
+// every frame. The keys are released when the window loses focus.
+func (w *Window) PressedKeys() []string {
+	w.pressedMu.Lock()
+	defer w.pressedMu.Unlock()
+	return append([]string(nil), w.pressed...)
+}
+
+// Title returns the most recent title set by the Title option, or "Gio"
+// if no title was set.
+func (w *Window) Title() string {
+	w.titleMu.Lock()
+	defer w.titleMu.Unlock()
+	return w.title
+}
+
 // ReadClipboard initiates a read of the clipboard in the form
 // of a clipboard.Event. Multiple reads may be coalesced
 // to a single event.
 func (w *Window) ReadClipboard() {
-	w.driverDefer(func(d driver) {
-		d.ReadClipboard()
-	})
+	w.ReadSelection(clipboard.SelectionClipboard)
 }
 
 // WriteClipboard writes a string to the clipboard.
 func (w *Window) WriteClipboard(s string) {
+	w.WriteSelection(clipboard.SelectionClipboard, s)
+}
+
+// ReadClipboardData initiates a read of the clipboard content of
+// a MIME type, in the form of a clipboard.Event with Type and Data set.
+// The event lists the types available on the clipboard, if known.
+// Unlike the events of ReadClipboard, the event is sent to the Events
+// channel of the window, not to the handlers of clipboard.ReadOp.
+// Reads of "text/plain" are equivalent to ReadClipboard.
+//
+// Types other than text are supported on Windows, X11, Wayland and
+// macOS. Elsewhere, the event has no Data.
+func (w *Window) ReadClipboardData(mime string) {
+	w.driverDefer(func(d driver) {
+		if isTextMIME(mime) {
+			d.ReadClipboard(clipboard.SelectionClipboard)
+			return
+		}
+		d.ReadClipboardData(mime)
+	})
+}
+
+// WriteClipboardData writes data of a MIME type to the clipboard.
+// Writes of "text/plain" are equivalent to WriteClipboard.
+//
+// Types other than text are supported on Windows, X11, Wayland and
+// macOS, and ignored elsewhere.
+func (w *Window) WriteClipboardData(mime string, data []byte) {
+	w.driverDefer(func(d driver) {
+		if isTextMIME(mime) {
+			d.WriteClipboard(clipboard.SelectionClipboard, string(data))
+			return
+		}
+		d.WriteClipboardData(mime, data)
+	})
+}
+
+func isTextMIME(mime string) bool {
+	return mime == "text/plain" || strings.HasPrefix(mime, "text/plain;")
+}
+
+// StartDrag starts a native drag operation that offers data in
+// the MIME types to other applications. It must be called while a
+// pointer button is pressed, typically in response to a pointer.Drag
+// event. A DragResultEvent is sent when the drag completes.
+//
+// File URLs offered as text/uri-list are also offered as files, for
+// dropping on file managers.
+//
+// StartDrag is supported on Windows, macOS, X11 and Wayland; on other
+// platforms the drag is reported as canceled.
+func (w *Window) StartDrag(data []byte, types []string) {
+	w.driverDefer(func(d driver) {
+		d.StartDrag(data, types)
+	})
+}
+
+// BeginInteractiveMove starts the platform's interactive window move,
+// as if the user dragged the title bar. Where supported, the move can be
+// completed with the keyboard, which makes it suitable for keyboard
+// shortcuts as well as custom title bars.
+//
+// Platform support:
+//   - Windows and X11 start the move at any time.
+//   - Wayland requires a pointer button to be held, because the
+//     compositor only starts moves from a pointer grab.
+//   - macOS requires the left mouse button to be pressed by the event
+//     being handled, because Cocoa only starts drags from mouse downs.
+//   - Android, iOS and JS ignore BeginInteractiveMove.
+func (w *Window) BeginInteractiveMove() {
+	w.driverDefer(func(d driver) {
+		d.StartMove()
+	})
+}
+
+// BeginInteractiveResize is like BeginInteractiveMove but starts an
+// interactive resize of the window edge.
+//
+// Platform support:
+//   - Windows and X11 start the resize at any time.
+//   - Wayland requires a pointer button to be held, because the
+//     compositor only starts resizes from a pointer grab.
+//   - macOS resizes the window while the left mouse button pressed
+//     by the event being handled is held. The window must be Windowed.
+//   - Android, iOS and JS ignore BeginInteractiveResize.
+func (w *Window) BeginInteractiveResize(edge ResizeEdge) {
+	w.driverDefer(func(d driver) {
+		d.StartResize(edge)
+	})
+}
+
+// SetTextInputState opens or closes the text input, such as the
+// on-screen keyboard, for input handlers that manage their own focus
+// instead of using key.FocusOp and key.SoftKeyboardOp. Note that a later
+// change of focus or key.SoftKeyboardOp overrides the state.
+func (w *Window) SetTextInputState(state key.TextInputState) {
 	w.driverDefer(func(d driver) {
-		d.WriteClipboard(s)
+		// Go through the router, so it tracks the state.
+		w.queue.q.SetTextInputState(state)
+		w.updateTextInput(d)
 	})
 }
 
-// Run f in the same thread as the native window event loop, and wait for f to
-// return or the window to close. Run is guaranteed not to deadlock if it is
-// invoked during the handling of a ViewEvent, system.FrameEvent,
-// system.StageEvent; call Run in a separate goroutine to avoid deadlock in all
-// other cases.
+// ReadSelection is like ReadClipboard, but reads from the
+// clipboard identified by sel.
+func (w *Window) ReadSelection(sel clipboard.Selection) {
+	w.driverDefer(func(d driver) {
+		d.ReadClipboard(sel)
+	})
+}
+
+// WriteSelection is like WriteClipboard, but writes to the
+// clipboard identified by sel.
+func (w *Window) WriteSelection(sel clipboard.Selection, s string) {
+	w.driverDefer(func(d driver) {
+		d.WriteClipboard(sel, s)
+	})
+}
+
+// Run f in the same thread as the native window event loop, and wait for f to
+// return or the window to close. Run is guaranteed not to deadlock if it is
+// invoked during the handling of a ViewEvent, system.FrameEvent,
+// system.StageEvent; call Run in a separate goroutine to avoid deadlock in all
+// other cases.
+//
+// Note that most programs should not call Run; configuring a Window with
+// CustomRenderer is a notable exception.
+func (w *Window) Run(f func()) {
+	done := make(chan struct{})
+	w.driverDefer(func(d driver) {
+		defer close(done)
+		f()
+	})
+	select {
+	case <-done:
+	case <-w.dead:
+	}
+}
+
+// ProcessEvents processes the native events pending for the window on
+// the thread of the native event loop, and returns when they have been
+// processed. The resulting events are delivered in order by Events. Like
+// Run, ProcessEvents must not be called from the goroutine receiving
+// events except during the handling of the events listed in Run.
+//
+// ProcessEvents is meant for tests and external loops; it does nothing
+// on platforms that run their own event loop, such as macOS, iOS,
+// Android and browsers.
+func (w *Window) ProcessEvents() {
+	done := make(chan struct{})
+	w.driverDefer(func(d driver) {
+		defer close(done)
+		d.ProcessEvents()
+	})
+	select {
+	case <-done:
+	case <-w.dead:
+	}
+}
+
+// SetLayoutDirection overrides the layout direction of the window
+// content, which defaults to the direction of the system language. The
+// direction is reported in FrameEvent.Locale for mirroring layouts, and
+// is hinted to the platform for its input methods and native controls.
+//
+// The platform hint is supported on macOS and in browsers.
+func (w *Window) SetLayoutDirection(dir system.TextDirection) {
+	w.localeMu.Lock()
+	w.layoutDir, w.layoutDirSet = dir, true
+	w.localeMu.Unlock()
+	w.Invalidate()
+}
+
+// Locale returns the locale of the window: the language of the system
+// and the layout direction set by SetLayoutDirection, or the direction
+// of the language if none was set. A LocaleEvent is sent when the
+// system language changes.
+func (w *Window) Locale() system.Locale {
+	w.localeMu.Lock()
+	defer w.localeMu.Unlock()
+	l := w.locale
+	if w.layoutDirSet {
+		l.Direction = w.layoutDir
+	}
+	return l
+}
+
+// ForcedColors returns the high contrast mode of the system. Programs
+// should draw with the colors of the mode while it is enabled. A
+// ForcedColorsEvent is sent when the mode changes.
+func (w *Window) ForcedColors() ForcedColors {
+	w.localeMu.Lock()
+	defer w.localeMu.Unlock()
+	return w.forcedColors
+}
+
+// SetBenchmark enables benchmark mode for measuring the throughput of
+// drawing. In benchmark mode, the window draws frames continuously and
+// presents them without waiting for the display, and f is called for
+// every presented frame with its timing. Use a nil function to leave
+// benchmark mode.
+//
+// The function is called from the window's rendering thread and must
+// not block. Where presenting without waiting is not supported, frames
+// are still limited by the display refresh rate; see PresentImmediate.
+// For benchmarks without a display, such as in continuous integration,
+// time the frames of a gioui.org/gpu/headless.Window instead.
+func (w *Window) SetBenchmark(f func(BenchmarkFrame)) {
+	w.driverDefer(func(d driver) {
+		if (f == nil) != (w.benchmark == nil) {
+			// Switch the present mode.
+			w.refreshContext = true
+		}
+		w.benchmark = f
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// OnGPURelease adds a function to be called right before the window
+// releases its GPU resources, such as when the window is paused (unless
+// SetKeepGPUOnPause is set) or destroyed, the context is lost, or
+// InvalidateGPUCache is called. The function is called from the
+// window's rendering thread while the context is current, to release
+// GPU resources created by the program for the context. New resources
+// are created for the next frame.
+func (w *Window) OnGPURelease(f func()) {
+	w.driverDefer(func(d driver) {
+		w.gpuRelease = append(w.gpuRelease, f)
+	})
+}
+
+// InvalidateGPUCache is like InvalidateSync, but also releases the GPU
+// resources of the window, such as uploaded images and cached paths.
+// The next frame recreates them from scratch. Use it after a change that
+// invalidates most cached resources, such as a theme switch, or when
+// the content of images changed without new paint.ImageOps. Recreating
+// the resources is expensive.
+//
+// InvalidateGPUCache is safe for concurrent use.
+func (w *Window) InvalidateGPUCache() {
+	w.driverDefer(func(d driver) {
+		w.releaseGPU()
+		w.refreshContext = true
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// SetDecoupledPresent enables or disables decoupled presentation, where
+// frames are presented to the screen on a separate thread. Presenting
+// may block until the display is ready for a new frame, and decoupling
+// it lets the window process input in the meantime, at the cost of an
+// extra thread. The next frame is drawn after the present completes.
+//
+// OpenGL contexts are bound to a single thread and are always presented
+// synchronously.
+func (w *Window) SetDecoupledPresent(enable bool) {
+	w.driverDefer(func(d driver) {
+		w.decoupled = enable
+	})
+}
+
+// SetKeepGPUOnPause controls whether the window keeps its GPU resources
+// while paused, such as when a mobile app is in the background. Keeping
+// the resources avoids recreating them and uploading images again when
+// the window resumes, at the cost of GPU memory while paused. The
+// platform may still destroy the window surface or the GPU context, in
+// which case the resources are released and recreated as usual.
+func (w *Window) SetKeepGPUOnPause(keep bool) {
+	w.driverDefer(func(d driver) {
+		w.keepGPU = keep
+	})
+}
+
+// SetRawInputHandler sets a handler that receives every input event
+// before it is routed to the input handlers of the most recent frame,
+// including events that no handler would receive. If the handler
+// returns true, the event is consumed and not routed further.
+//
+// The handler is called from the native event loop and must not
+// block. A nil handler removes the current handler.
+func (w *Window) SetRawInputHandler(h func(e event.Event) bool) {
+	w.driverDefer(func(d driver) {
+		w.rawInput = h
+	})
+}
+
+// DrawTo schedules the drawing of ops into the texture of img, for
+// multi-pass effects such as blurs and shadows. The drawing takes place
+// at the next call to FrameEvent.Frame, before the frame itself is
+// drawn, and ops must not change until then. The size of the drawing is
+// the size of img, which is typically created by
+//
+//	paint.NewImageOp(image.NewRGBA(image.Rectangle{Max: size}))
+//
+// Painting img displays the drawing instead of the image content, as
+// long as img is painted in the same frame and every frame after it.
+// An image that stops being painted reverts to its content.
+//
+// DrawTo has no effect for windows with a custom renderer and for GPUs
+// that use the compute renderer, as reported by Caps.DrawTo. Programs
+// should paint the content of img themselves where DrawTo is not
+// supported.
+func (w *Window) DrawTo(img paint.ImageOp, ops *op.Ops) {
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	w.offscreen = append(w.offscreen, offscreenDraw{img: img, ops: ops})
+}
+
+// Texture is an image that stays in GPU memory until released. Use a
+// Texture to paint a large, static image in many frames without relying
+// on the implicit caching of images that are painted every frame.
+type Texture struct {
+	w   *Window
+	img paint.ImageOp
+}
+
+// NewTexture returns a Texture of img. The image is uploaded the first
+// time the texture is painted, and stays in GPU memory until Release,
+// even for frames that don't paint it. The GPU memory is restored after
+// the window recreates its GPU context.
+//
+// Textures are not kept in GPU memory for windows with a custom renderer
+// and for GPUs that use the compute renderer, but can still be painted.
+func (w *Window) NewTexture(img image.Image) *Texture {
+	t := &Texture{w: w, img: paint.NewImageOp(img)}
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	if w.textures == nil {
+		w.textures = make(map[*Texture]struct{})
+	}
+	w.textures[t] = struct{}{}
+	w.texturesChanged = true
+	return t
+}
+
+// Op returns the operation for painting the texture.
+func (t *Texture) Op() paint.ImageOp {
+	return t.img
+}
+
+// Release frees the GPU memory of the texture at the next frame that
+// doesn't paint it.
+func (t *Texture) Release() {
+	w := t.w
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	delete(w.textures, t)
+	w.texturesChanged = true
+}
+
+// Layer is a drawing cached in a texture across frames, for content that
+// is expensive to draw but rarely changes. Painting the image of a layer
+// is much cheaper than drawing its operations every frame.
+type Layer struct {
+	tex *Texture
+	// ops, dirty and version are guarded by the window's gpuMu. dirty
+	// is set when ops must be drawn at the next frame, and version is
+	// incremented by every Update.
+	ops     *op.Ops
+	dirty   bool
+	version int
+}
+
+// CacheLayer returns a layer of the given size in pixels with the
+// drawing of ops. The drawing takes place at the next frame and is
+// redrawn from ops only when needed, such as after the GPU context is
+// lost, so ops must not change until Update or Release. Use Update to
+// change the content of the layer.
+//
+// Layers are built on DrawTo and have its restrictions.
+func (w *Window) CacheLayer(size image.Point, ops *op.Ops) *Layer {
+	l := &Layer{
+		tex: w.NewTexture(image.NewRGBA(image.Rectangle{Max: size})),
+	}
+	l.Update(ops)
+	return l
+}
+
+// Update replaces the operations of the layer, which are drawn at the
+// next frame.
+func (l *Layer) Update(ops *op.Ops) {
+	w := l.tex.w
+	w.gpuMu.Lock()
+	defer w.gpuMu.Unlock()
+	if w.layers == nil {
+		w.layers = make(map[*Layer]struct{})
+	}
+	w.layers[l] = struct{}{}
+	l.ops = ops
+	l.dirty = true
+	l.version++
+}
+
+// Op returns the operation for painting the layer.
+func (l *Layer) Op() paint.ImageOp {
+	return l.tex.Op()
+}
+
+// Release frees the GPU memory of the layer at the next frame that
+// doesn't paint it. A released layer is no longer redrawn.
+func (l *Layer) Release() {
+	w := l.tex.w
+	w.gpuMu.Lock()
+	delete(w.layers, l)
+	l.ops = nil
+	w.gpuMu.Unlock()
+	l.tex.Release()
+}
+
+// SetFrameCaptureCallback sets a function to receive the content of
+// every frame presented by the window, such as for screen recording. The
+// function is called from the window's rendering thread right after the
+// frame is presented, and owns img. Use a nil function to stop
+// capturing; frames are only read back while a function is set.
+//
+// Capturing draws every frame twice and waits for the GPU, so it is
+// significantly slower than regular drawing. Frames that fail to be
+// captured are skipped.
+func (w *Window) SetFrameCaptureCallback(f func(img *image.RGBA, id FrameID)) {
+	w.driverDefer(func(d driver) {
+		w.captureFrame = f
+		w.captured = nil
+	})
+}
+
+// ReadbackAsync reads back the content of the next frame presented by
+// the window without waiting for the GPU, and calls f with the pixels
+// and the frame ID once the GPU has finished drawing, typically a frame
+// or two later. A readback of a frame that fails to present is retried
+// with the next frame. Call ReadbackAsync from f to capture frames
+// continuously. ReadbackAsync is safe for concurrent use. f is called
+// from the window's rendering thread and owns img. Readbacks that fail,
+// or are pending when the GPU context is lost, are skipped.
+//
+// Like SetFrameCaptureCallback, ReadbackAsync draws the frame twice,
+// but the pixels are copied to memory the CPU reads without stalling
+// the rendering. Where the GPU API doesn't support asynchronous
+// readback, such as for Metal, Vulkan and WebGL, ReadbackAsync waits
+// for the GPU.
+func (w *Window) ReadbackAsync(f func(img *image.RGBA, id FrameID)) {
+	w.readbackMu.Lock()
+	w.readbackReqs = append(w.readbackReqs, f)
+	w.readbackMu.Unlock()
+	w.Invalidate()
+}
+
+// screenshotRequest is a request for the pixels of rect from the next
+// frame, delivered to result.
+type screenshotRequest struct {
+	rect   image.Rectangle
+	result chan<- screenshotResult
+}
+
+type screenshotResult struct {
+	img *image.RGBA
+	err error
+}
+
+// ScreenshotRect reads back the pixels of the rectangle r of the next
+// frame drawn by the window, such as for a magnifier. The rectangle is
+// in window coordinates and is clamped to the window. Only the pixels
+// of r are drawn and copied from the GPU, so ScreenshotRect is much
+// cheaper than capturing the whole frame for small rectangles.
+//
+// ScreenshotRect returns an error if the window has no GPU, such as
+// before its first frame or for windows with a custom renderer. It
+// waits for the next frame, so it must not be called from the goroutine
+// that handles the window events.
+func (w *Window) ScreenshotRect(r image.Rectangle) (*image.RGBA, error) {
+	result := make(chan screenshotResult, 1)
+	w.driverDefer(func(d driver) {
+		if w.gpu == nil {
+			result <- screenshotResult{err: errors.New("app: GPU not initialized")}
+			return
+		}
+		w.screenshots = append(w.screenshots, screenshotRequest{rect: r, result: result})
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+	select {
+	case res := <-result:
+		return res.img, res.err
+	case <-w.dead:
+		return nil, errors.New("app: window closed")
+	}
+}
+
+// screenshot reads back the pixels of r from frame.
+func (w *Window) screenshot(frame *op.Ops, viewport image.Point, r image.Rectangle) screenshotResult {
+	r = r.Intersect(image.Rectangle{Max: viewport})
+	if r.Empty() {
+		return screenshotResult{err: errors.New("app: screenshot rectangle outside window")}
+	}
+	img := image.NewRGBA(r)
+	if err := w.gpu.Capture(frame, img); err != nil {
+		return screenshotResult{err: err}
+	}
+	return screenshotResult{img: img}
+}
+
+// failScreenshots completes the pending screenshots with err.
+func (w *Window) failScreenshots(err error) {
+	for _, s := range w.screenshots {
+		s.result <- screenshotResult{err: err}
+	}
+	w.screenshots = nil
+}
+
+// SetOverlay sets a function that adds operations drawn on top of
+// every frame, such as a debug or frame rate display. The function is
+// called from the window's rendering thread after the application's
+// frame is received, with the window size in pixels, and its operations
+// are drawn in the same frame. Use a nil function to remove the overlay.
+//
+// The overlay is independent of the application's frames; use
+// Invalidate to redraw it while the application is idle.
+func (w *Window) SetOverlay(f func(o *op.Ops, size image.Point)) {
+	w.driverDefer(func(d driver) {
+		w.overlay = f
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// SetRenderScale makes the window draw its frames at the fixed logical
+// size and scale them to the window size with filter, such as for
+// crisp pixel art with paint.FilterNearest. FrameEvent.Size is the
+// logical size, the metric is scaled accordingly and pointer positions
+// are in logical coordinates. The decorations and overlay are drawn at
+// the window resolution. A zero size restores drawing at the window
+// size.
+//
+// SetRenderScale has no effect for windows with a custom renderer, and
+// for GPUs that don't support DrawTo, such as the compute renderer; see
+// Caps.DrawTo. Frames are then drawn at the window size.
+func (w *Window) SetRenderScale(logical image.Point, filter paint.ImageFilter) {
+	w.driverDefer(func(d driver) {
+		w.scaled.size = logical
+		w.scaled.filter = filter
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// SetResolutionScale makes the window draw its frames at a fraction of
+// the window size and scale them up to the window size, trading
+// sharpness for speed. A scale outside the range (0, 1) draws frames at
+// the window size. Like SetRenderScale, FrameEvent.Size
+// and the metric are scaled accordingly. Unlike SetRenderScale, the
+// scale may be adjusted every frame, for example to maintain a frame
+// rate. SetRenderScale takes precedence over SetResolutionScale.
+//
+// SetResolutionScale has no effect for windows with a custom renderer,
+// and for GPUs that don't support DrawTo, like SetRenderScale.
+func (w *Window) SetResolutionScale(scale float32) {
+	w.driverDefer(func(d driver) {
+		w.scaled.res = scale
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// SetCursorPos moves the mouse cursor to pos in window coordinates,
+// for example to recenter the cursor for mouse-look controls. The move
+// may be reported as a pointer.Move event.
+//
+// SetCursorPos is supported on Windows, X11 and macOS; it is ignored on
+// other platforms.
+func (w *Window) SetCursorPos(pos image.Point) {
+	w.driverDefer(func(d driver) {
+		d.SetCursorPos(pos)
+	})
+}
+
+// SetCustomCursor replaces the cursor of the window with img, until
+// SetCustomCursor is called with a nil or empty img, which restores the
+// cursors set by pointer.CursorOp. The hotspot is the point of img at the mouse
+// position, relative to the top left corner of img. The image is shown
+// at its size in pixels.
+//
+// Where the platform doesn't support images as cursors, the cursor is
+// hidden instead and the program is expected to draw img itself at the
+// mouse position; see SoftwareCursor.
+func (w *Window) SetCustomCursor(img image.Image, hotspot image.Point) {
+	var nrgba *image.NRGBA
+	if img != nil && !img.Bounds().Empty() {
+		b := img.Bounds()
+		nrgba = image.NewNRGBA(image.Rectangle{Max: b.Size()})
+		draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+	}
+	w.driverDefer(func(d driver) {
+		soft := false
+		if nrgba != nil {
+			w.customCursor = true
+			if !d.SetCustomCursor(nrgba, hotspot) {
+				soft = true
+				d.SetCursor(pointer.CursorNone)
+			}
+		} else if w.customCursor {
+			w.customCursor = false
+			d.SetCustomCursor(nil, image.Point{})
+			w.cursor = w.queue.q.Cursor()
+			d.SetCursor(w.cursor)
+		}
+		w.cursorMu.Lock()
+		w.softCursor = soft
+		w.cursorMu.Unlock()
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// SoftwareCursor reports whether the program is expected to draw the
+// image set by SetCustomCursor, because the platform doesn't support it.
+// While true, the window redraws when the mouse moves.
+func (w *Window) SoftwareCursor() bool {
+	w.cursorMu.Lock()
+	defer w.cursorMu.Unlock()
+	return w.softCursor
+}
+
+// CursorPosition returns the most recent position of the mouse in window
+// pixels, or false if the position is not known, such as before the
+// mouse moved over the window.
+func (w *Window) CursorPosition() (f32.Point, bool) {
+	w.cursorMu.Lock()
+	defer w.cursorMu.Unlock()
+	return w.cursorPos, w.cursorIn
+}
+
+// trackCursor updates the mouse position from e and reports whether
+// the position of a software cursor changed.
+func (w *Window) trackCursor(e pointer.Event) bool {
+	w.cursorMu.Lock()
+	defer w.cursorMu.Unlock()
+	in := e.Type != pointer.Leave
+	changed := in != w.cursorIn || (in && e.Position != w.cursorPos)
+	w.cursorIn = in
+	if in {
+		w.cursorPos = e.Position
+	}
+	return changed && w.softCursor
+}
+
+// SetRelativeMouse enables or disables relative mouse mode, for
+// example for first-person camera controls. While enabled, the cursor is
+// hidden and confined to the window, pointer.Move events report the
+// motion of the mouse in their Delta field, and the Position of pointer
+// events stays at the position where the mode was enabled. The cursor
+// is restored to that position when the mode is disabled.
+//
+// SetRelativeMouse is supported on Windows, X11, macOS and in browsers,
+// where the pointer lock may be exited by the user and may require a
+// preceding user gesture. It is ignored on other platforms.
+func (w *Window) SetRelativeMouse(enable bool) {
+	w.driverDefer(func(d driver) {
+		d.SetRelativeMouse(enable)
+	})
+}
+
+// SetCursorConfined confines the cursor to the window while enabled, for
+// example for kiosk programs or games. Unlike SetRelativeMouse, the
+// cursor stays visible and pointer events report absolute positions.
+// The cursor is released while the window doesn't have the focus and is
+// confined again when the window regains it.
+//
+// SetCursorConfined is supported on Windows and X11. It is ignored on
+// other platforms.
+func (w *Window) SetCursorConfined(confine bool) {
+	w.driverDefer(func(d driver) {
+		d.SetCursorConfined(confine)
+	})
+}
+
+// SetInputShape restricts pointer input to the union of the rectangles
+// in shape, in window coordinates, for windows that are not
+// rectangular such as round widgets drawn with a transparent
+// background. Clicks outside the shape pass through to the windows
+// behind. A nil shape restores input to the whole window, while an
+// empty shape lets all clicks pass through.
+//
+// SetInputShape is supported on Windows, X11, Wayland and macOS. On
+// Windows, the shape clips the window content as well, so an empty
+// shape hides the window entirely. On macOS, the window tracks the
+// mouse to ignore mouse events outside the shape.
+func (w *Window) SetInputShape(shape []image.Rectangle) {
+	if shape != nil {
+		shape = append([]image.Rectangle{}, shape...)
+	}
+	w.driverDefer(func(d driver) {
+		d.SetInputShape(shape)
+	})
+}
+
+// SetIdleTimeout requests an IdleEvent when the window receives no
+// pointer or key input for d, and an ActiveEvent when input resumes, for
+// example to start a screen saver. A zero d disables the events.
+func (w *Window) SetIdleTimeout(d time.Duration) {
+	w.driverDefer(func(drv driver) {
+		w.idleTimeout = d
+		w.idle = false
+		if w.idleTimer != nil {
+			w.idleTimer.Stop()
+			w.idleTimer = nil
+		}
+		w.resetIdle(drv)
+	})
+}
+
+// resetIdle records input activity and restarts the idle timer.
+func (w *Window) resetIdle(d driver) {
+	w.lastInput = time.Now()
+	if w.idleTimeout == 0 {
+		return
+	}
+	if w.idle {
+		w.idle = false
+		w.processEvent(d, ActiveEvent{})
+	}
+	if w.idleTimer != nil {
+		w.idleTimer.Reset(w.idleTimeout)
+		return
+	}
+	w.idleTimer = time.AfterFunc(w.idleTimeout, func() {
+		w.driverDefer(func(d driver) {
+			w.processEvent(d, IdleEvent{})
+		})
+	})
+}
+
+// defaultTouchSlop is the default touch slop, following the platform
+// conventions of Android and iOS.
+const defaultTouchSlop = unit.Dp(8)
+
+// The default double click time and distance, for platforms that don't
+// expose them. They match the defaults of Windows.
+const (
+	defaultDoubleClickTime     = 500 * time.Millisecond
+	defaultDoubleClickDistance = unit.Dp(2)
+)
+
+// DoubleClickTime returns the maximum time between the clicks of a
+// double click according to the platform, for programs that detect
+// multiple clicks themselves.
+func (w *Window) DoubleClickTime() time.Duration {
+	t, _ := systemDoubleClick()
+	return t
+}
+
+// DoubleClickDistance returns the maximum distance in pixels between
+// the clicks of a double click according to the platform, for the
+// metric of the most recent frame.
+func (w *Window) DoubleClickDistance() int {
+	_, dist := systemDoubleClick()
+	m := unit.Metric{PxPerDp: math.Float32frombits(atomic.LoadUint32(&w.pxPerDp))}
+	if m.PxPerDp == 0 {
+		m.PxPerDp = 1
+	}
+	return m.Dp(dist)
+}
+
+// SetTouchSlop sets the distance a touch pointer may move from where it
+// was pressed without canceling a long press. Zero restores the default
+// of 8 Dp. See pointer.LongPressEvent.
+func (w *Window) SetTouchSlop(slop unit.Dp) {
+	w.driverDefer(func(d driver) {
+		w.touchSlop = slop
+	})
+}
+
+// SetPointerCoalescing controls whether consecutive pointer.Move and
+// pointer.Drag events are coalesced, so that handlers receive at most
+// one move event of a pointer per frame, with the positions of the
+// merged events in pointer.Event.History. Coalescing avoids flooding
+// handlers with the events of high polling rate devices; programs that
+// need every position, such as for drawing, can use History.
+func (w *Window) SetPointerCoalescing(enable bool) {
+	w.driverDefer(func(d driver) {
+		w.queue.q.SetPointerCoalescing(enable)
+	})
+}
+
+// SetLongPressTime sets the time a touch pointer must be held without
+// moving for a pointer.LongPressEvent. Zero restores the default of
+// 500 milliseconds.
+func (w *Window) SetLongPressTime(t time.Duration) {
+	w.driverDefer(func(d driver) {
+		w.queue.q.SetLongPressTime(t)
+	})
+}
+
+// SetTessellationTolerance sets the maximum distance in pixels between
+// the curves of clip paths and their approximations. A higher tolerance
+// draws complex paths with fewer vertices at the cost of smoothness, for
+// example when zoomed out. The default, zero, preserves the quality of
+// curves at any size.
+//
+// The tolerance is ignored by the compute renderer.
+func (w *Window) SetTessellationTolerance(px float32) {
+	w.driverDefer(func(d driver) {
+		w.tolerance = px
+		w.setNextFrame(time.Time{}, RedrawExternal)
+		w.updateAnimation(d)
+	})
+}
+
+// SetMaxInFlightFrames limits the number of frames drawn by the window
+// but not yet completed by the GPU. Drawing a frame blocks until the GPU
+// has caught up to within n frames. A limit of 1 gives the lowest input
+// latency at some cost of throughput. The default, zero, leaves the
+// number of frames to the GPU driver.
+//
+// The limit has no effect for Metal and Vulkan, whose frames are already
+// bounded by the window, and for WebGL.
+func (w *Window) SetMaxInFlightFrames(n int) {
+	w.driverDefer(func(d driver) {
+		w.maxInFlight = n
+	})
+}
+
+// SetAspectRatio constrains interactive resizes of the window to the
+// w:h ratio. It is equivalent to Option(AspectRatio(w, h)); use 0, 0
+// to remove the constraint.
+func (w *Window) SetAspectRatio(width, height int) {
+	w.Option(AspectRatio(width, height))
+}
+
+// SetResizeIncrements constrains interactive resizes of the window to
+// multiples of dx by dy pixels, such as the cell size of a terminal. It
+// is equivalent to Option(ResizeIncrements(dx, dy)); use 0, 0 to remove
+// the constraint.
+func (w *Window) SetResizeIncrements(dx, dy int) {
+	w.Option(ResizeIncrements(dx, dy))
+}
+
+// SetDocumentEdited marks the window document as having unsaved
+// changes. It is equivalent to Option(DocumentEdited(edited)).
+func (w *Window) SetDocumentEdited(edited bool) {
+	w.Option(DocumentEdited(edited))
+}
+
+// SetMaterial requests the background material behind the window
+// content. It is equivalent to Option(m.Option()).
+func (w *Window) SetMaterial(m Material) {
+	w.Option(m.Option())
+}
+
+// SetResizable controls whether the user can resize the window. It is
+// equivalent to Option(Resizable(enable)).
+func (w *Window) SetResizable(enable bool) {
+	w.Option(Resizable(enable))
+}
+
+// SetShadow requests the native drop shadow for an undecorated window.
+// It is equivalent to Option(Shadow(enable)).
+func (w *Window) SetShadow(enable bool) {
+	w.Option(Shadow(enable))
+}
+
+// SetSkipTaskbar controls whether the window is left out of the taskbar
+// and the window switcher. It is equivalent to
+// Option(SkipTaskbar(skip)).
+func (w *Window) SetSkipTaskbar(skip bool) {
+	w.Option(SkipTaskbar(skip))
+}
+
+// SetTaskbarProgress displays the progress of a background operation
+// on the taskbar button or dock icon of the window. The fraction is
+// clamped to [0,1]; a negative fraction or ProgressNone hides the
+// progress.
+//
+// SetTaskbarProgress is supported on Windows, and on macOS where the
+// progress is displayed as a dock badge. It is ignored on other platforms.
+func (w *Window) SetTaskbarProgress(fraction float64, state ProgressState) {
+	if fraction < 0 {
+		state = ProgressNone
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	if state == ProgressNone {
+		fraction = 0
+	}
+	w.driverDefer(func(d driver) {
+		d.SetProgress(fraction, state)
+	})
+}
+
+// SetModal restricts input to the pointer handler for tag and the
+// handlers inside its area, such as a dialog blocking interaction with
+// the rest of the window. Presses outside the area are delivered to tag
+// as pointer.Dismiss events, if requested by its pointer.InputOp.
+// Use a nil tag to lift the restriction.
+func (w *Window) SetModal(tag event.Tag) {
+	w.driverDefer(func(d driver) {
+		w.queue.q.SetModal(tag)
+	})
+}
+
+// InputAreas returns the pointer input areas of the most recent frame,
+// topmost first, for debugging input routing. Like Run, InputAreas
+// must be called during the handling of a ViewEvent, system.FrameEvent
+// or system.StageEvent, or from a separate goroutine.
+func (w *Window) InputAreas() []router.InputArea {
+	var areas []router.InputArea
+	w.Run(func() {
+		areas = w.queue.q.AppendInputAreas(nil)
+	})
+	return areas
+}
+
+// RefreshRate returns the refresh rate in Hz of the display showing the
+// window, or 60 if the rate is unknown. The rate is updated when the
+// window moves to another display.
+//
+// RefreshRate is safe for concurrent use.
+func (w *Window) RefreshRate() float64 {
+	if hz := math.Float64frombits(atomic.LoadUint64(&w.refreshRate)); hz > 0 {
+		return hz
+	}
+	return 60
+}
+
+// CancelScheduledFrame cancels the frame scheduled by an animation or by
+// op.InvalidateOp, for example when the program stops an animation early.
+// Frames requested by Invalidate are not affected.
+func (w *Window) CancelScheduledFrame() {
+	w.driverDefer(func(d driver) {
+		w.cancelNextFrame(d)
+	})
+}
+
+// NeedsRedraw reports whether the window has a frame pending, because
+// of Invalidate, a due InvalidateOp or an animation. It is a snapshot
+// meant for external loops that want to sleep while the window is idle.
+func (w *Window) NeedsRedraw() bool {
+	if len(w.redraws) > 0 {
+		return true
+	}
+	t, ok := w.NextFrameTime()
+	return ok && !t.After(time.Now())
+}
+
+// NextFrameTime returns the time of the next scheduled frame, if any.
+// A time in the past means the window is animating.
+func (w *Window) NextFrameTime() (time.Time, bool) {
+	w.scheduledMu.Lock()
+	defer w.scheduledMu.Unlock()
+	return w.scheduled.at, w.scheduled.ok
+}
+
+// LastRedrawReason returns the reason the most recent frame was drawn,
+// for finding the cause of unexpected redraws.
+func (w *Window) LastRedrawReason() RedrawReason {
+	w.scheduledMu.Lock()
+	defer w.scheduledMu.Unlock()
+	return w.scheduled.lastReason
+}
+
+// Caps returns the description of the window's GPU context, as of the
+// most recent frame. Together with the present mode, Caps tells the
+// buffering depth of the window, for reasoning about input latency.
+func (w *Window) Caps() Caps {
+	w.capsMu.Lock()
+	defer w.capsMu.Unlock()
+	return w.caps
+}
+
+// WorkArea returns the area in pixels of the display showing the window
+// that is not covered by taskbars, docks or panels, in the coordinates
+// of the desktop. Use it to place and size a window such that it isn't
+// obscured. WorkArea returns the empty rectangle if the work area is
+// unknown.
+//
+// WorkArea is supported on Windows, X11, macOS and in browsers. It is
+// safe for concurrent use.
+func (w *Window) WorkArea() image.Rectangle {
+	w.workAreaMu.Lock()
+	defer w.workAreaMu.Unlock()
+	return w.workArea
+}
+
+// FrameTimings returns the most recent breakdown of the time spent
+// rendering a frame. Like profile.Event, timings are only collected for
+// frames that contain a profile.Op, and are zero until available.
+func (w *Window) FrameTimings() FrameTimings {
+	w.timingsMu.Lock()
+	defer w.timingsMu.Unlock()
+	return w.timings
+}
+
+// FrameHistogram returns the durations of the most recent frames, up to
+// a fixed limit. The duration of a frame is the time from the start of
+// its FrameEvent until the frame is presented.
+func (w *Window) FrameHistogram() FrameHistogram {
+	w.timingsMu.Lock()
+	defer w.timingsMu.Unlock()
+	return w.frameDurs.histogram()
+}
+
+// ResetFrameHistogram discards the frame durations collected so far.
+func (w *Window) ResetFrameHistogram() {
+	w.timingsMu.Lock()
+	defer w.timingsMu.Unlock()
+	w.frameDurs = frameHistory{}
+}
+
+// SetAllocProfiling enables or disables the measurement of memory
+// allocations per frame. When enabled, profile.Event timings and
+// FrameTimings include the bytes allocated and the garbage collections
+// completed from the start of a frame until it is presented, to
+// correlate garbage collection with slow frames. Like other profiling
+// information, allocations are only reported for frames that contain
+// a profile.Op.
+//
+// Allocation profiling is disabled by default, because measuring it
+// briefly stops the program twice per frame.
+func (w *Window) SetAllocProfiling(enable bool) {
+	w.driverDefer(func(d driver) {
+		w.allocProfiling = enable
+		// Measure from the start of the next frame.
+		w.allocStart, w.gcStart = 0, 0
+	})
+}
+
+// SetLatencyTracking enables or disables the measurement of input
+// latency. When enabled, the window measures for every presented frame
+// the time from the platform timestamp of the first input event handled
+// since the previous frame until the frame is presented. Events without
+// timestamps, such as key.Event, are measured from when they're received
+// from the platform.
+func (w *Window) SetLatencyTracking(enable bool) {
+	w.driverDefer(func(d driver) {
+		w.trackLatency = enable
+		w.latencyInput = time.Time{}
+	})
+}
+
+// InputLatency returns the most recent input latencies measured while
+// latency tracking is enabled, up to a fixed limit. See
+// SetLatencyTracking.
+func (w *Window) InputLatency() FrameHistogram {
+	w.timingsMu.Lock()
+	defer w.timingsMu.Unlock()
+	return w.latencies.histogram()
+}
+
+// trackInput records the time of an input event for measuring the
+// latency of the next frame.
+func (w *Window) trackInput(e event.Event) {
+	if !w.trackLatency || !w.latencyInput.IsZero() {
+		return
+	}
+	now := time.Now()
+	pe, ok := e.(pointer.Event)
+	if !ok || pe.Time == 0 {
+		w.latencyInput = now
+		return
+	}
+	// Timestamps are relative to an undefined base. Delivery is never
+	// instant, so the earliest base seen is the closest estimate.
+	if base := now.Add(-pe.Time); w.latencyBase.IsZero() || base.Before(w.latencyBase) {
+		w.latencyBase = base
+	}
+	w.latencyInput = w.latencyBase.Add(pe.Time)
+}
+
+// SetPanicHandler sets a handler for panics that occur while the window
+// processes events and draws frames. When set, such a panic is recovered
+// and passed to the handler, after which the window is destroyed and a
+// system.DestroyEvent describing the panic is sent, instead of the panic
+// crashing the program. A nil handler restores the default behavior.
+//
+// The handler is called from the native event loop. Panics in the
+// program's own goroutines, including the one that receives events,
+// are not recovered.
+func (w *Window) SetPanicHandler(h func(v interface{})) {
+	w.driverDefer(func(d driver) {
+		w.panicHandler = h
+	})
+}
+
+// recoverPanic recovers a panic during event processing, reports it to
+// the panic handler and destroys the window.
+func (w *Window) recoverPanic(d driver) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	w.callbacks.busy = false
+	w.callbacks.waitEvents = w.callbacks.waitEvents[:0]
+	w.panicHandler(v)
+	select {
+	case <-w.dead:
+		return
+	default:
+	}
+	w.destroyGPU()
+	// The events caused by closing the platform window are ignored,
+	// because the window is dead.
+	w.destroyWindow(system.DestroyEvent{Err: fmt.Errorf("app: panic: %v", v), Reason: system.DestroyError})
+	d.Perform(system.ActionClose)
+}
+
+// ServeOn runs the platform event loop of a window created with the
+// ServeOnCaller option on the calling goroutine, and returns when the
+// window is destroyed. Use runtime.LockOSThread before calling ServeOn
+// to run the event loop on a particular thread.
+//
+// On platforms where the event loop runs on the main thread, such as
+// macOS, iOS and Android, ServeOn only waits for the window to be
+// destroyed.
+func (w *Window) ServeOn() {
+	if !w.serveOn {
+		panic("app: ServeOn requires the ServeOnCaller option")
+	}
+	select {
+	case loop := <-w.serveLoops:
+		loop()
+	case <-w.dead:
+	}
+	<-w.dead
+}
+
+// Use appends a middleware function to the chain that observes every
+// event before the window processes it. Functions run in the order they
+// were added, each receiving the event returned by the previous one. A
+// function may return the event unchanged, return a replacement event,
+// or return nil to drop the event.
+//
+// Events that are part of the window life cycle and await an
+// acknowledgement from the window, namely system.StageEvent,
+// system.DestroyEvent, ViewEvent, ConfigEvent, SaveStateEvent and frame
+// requests, bypass the chain. Middleware must not return such events,
+// and the window panics if it does. Changes to a LocaleEvent or a
+// ForcedColorsEvent are reflected by Window.Locale and
+// Window.ForcedColors.
+//
+// Middleware is called from the native event loop and must not block.
+func (w *Window) Use(m func(e event.Event) event.Event) {
+	w.driverDefer(func(d driver) {
+		w.middleware = append(w.middleware, m)
+	})
+}
+
+// SetEventFilter restricts the events sent by the window to the
+// specified types. Events of other types are dropped before they are
+// sent, except system.StageEvent and system.DestroyEvent which are
+// always sent. Calling SetEventFilter with no types removes the filter.
+//
+// Input events such as pointer and key events are delivered through
+// the frame event queue and are not affected by the filter.
+func (w *Window) SetEventFilter(types ...EventType) {
+	var filter uint32
+	for _, t := range types {
+		filter |= 1 << t
+	}
+	w.driverDefer(func(d driver) {
+		w.eventFilter = filter
+	})
+}
+
+// wants reports whether the event filter selects events of type t.
+func (w *Window) wants(t EventType) bool {
+	return w.eventFilter == 0 || w.eventFilter&(1<<t) != 0
+}
+
+// applyMiddleware runs e through the middleware chain and returns the
+// resulting event, or nil if the event was dropped.
+func (w *Window) applyMiddleware(e event.Event) event.Event {
+	if isLifecycleEvent(e) {
+		return e
+	}
+	for _, m := range w.middleware {
+		if e = m(e); e == nil {
+			break
+		}
+		if isLifecycleEvent(e) {
+			panic(fmt.Errorf("app: middleware returned life cycle event %T", e))
+		}
+	}
+	return e
+}
+
+// isLifecycleEvent reports whether e is part of the window life cycle
+// or internal to the window, and bypasses middleware. These are the
+// events that carry or await an acknowledgement from the window.
+func isLifecycleEvent(e event.Event) bool {
+	switch e.(type) {
+	case wakeupEvent, frameEvent, system.StageEvent, system.DestroyEvent, ViewEvent,
+		ConfigEvent, SaveStateEvent:
+		return true
+	}
+	return false
+}
+
+// SendEvent injects an input event into the window as if it came from
+// the platform. The event passes through the raw input handler, if any,
+// before it is routed. SendEvent is useful for replaying input recorded
+// by SetRawInputHandler.
+//
+// Only pointer.Event, key.Event and key.EditEvent can be sent;
+// SendEvent panics for other events, in particular for events of the
+// window life cycle such as system.FrameEvent and system.StageEvent.
+//
+// SendEvent is safe for concurrent use.
+func (w *Window) SendEvent(e event.Event) {
+	if !isInputEvent(e) {
+		panic(fmt.Errorf("app: SendEvent of non-input event %T", e))
+	}
+	w.driverDefer(func(d driver) {
+		w.callbacks.Event(e)
+	})
+}
+
+// SetBackgroundTick requests a TickEvent to be sent every interval,
+// even when the window is not visible and no frames are drawn. Use it to
+// service periodic non-graphical work in step with the window lifecycle.
+// An interval of zero or less disables the ticks.
+//
+// SetBackgroundTick is safe for concurrent use.
+func (w *Window) SetBackgroundTick(interval time.Duration) {
+	for {
+		select {
+		case <-w.tickIntervals:
+		case w.tickIntervals <- interval:
+			return
+		case <-w.dead:
+			return
+		}
+	}
+}
+
+// AcquireOps returns an empty operation list, re-using a list
+// previously released by ReleaseOps if possible. Because
+// FrameEvent.Frame doesn't retain its operations, a list may be
+// released as soon as Frame returns.
+//
+// AcquireOps is safe for concurrent use.
+func (w *Window) AcquireOps() *op.Ops {
+	select {
+	case o := <-w.opsPool:
+		return o
+	default:
+		return new(op.Ops)
+	}
+}
+
+// ReleaseOps resets o and makes it available for re-use by AcquireOps.
+// The caller must not use o after calling ReleaseOps.
 //
-// Note that most programs should not call Run; configuring a Window with
-// CustomRenderer is a notable exception.
-func (w *Window) Run(f func()) {
-	done := make(chan struct{})
-	w.driverDefer(func(d driver) {
-		defer close(done)
-		f()
-	})
+// ReleaseOps is safe for concurrent use.
+func (w *Window) ReleaseOps(o *op.Ops) {
+	o.Reset()
 	select {
-	case <-done:
-	case <-w.dead:
+	case w.opsPool <- o:
+	default:
+		// The pool is full; leave o to the garbage collector.
 	}
 }
 
+// Show makes a hidden window visible. It is equivalent to
+// w.Option(Hidden(false)).
+func (w *Window) Show() {
+	w.Option(Hidden(false))
+}
+
+// Hide hides the window. It is equivalent to w.Option(Hidden(true)).
+func (w *Window) Hide() {
+	w.Option(Hidden(true))
+}
+
 // driverDefer is like Run but can be run from any context. It doesn't wait
 // for f to return.
 func (w *Window) driverDefer(f func(d driver)) {
@@ -427,6 +2549,92 @@ func (w *Window) driverDefer(f func(d driver)) {
 	}
 }
 
+// Animate schedules f to be called at the start of every frame, with the
+// frame time, for as long as f returns true. Frames are scheduled
+// automatically while animations are pending, so a forgotten Invalidate
+// does not stall an animation.
+//
+// Animate is safe for concurrent use. The calls to f happen before the
+// corresponding system.FrameEvent is delivered.
+func (w *Window) Animate(f func(now time.Time) (keepGoing bool)) {
+	w.driverDefer(func(d driver) {
+		w.animations = append(w.animations, f)
+		w.setNextFrame(time.Time{}, RedrawAnimation)
+		w.updateAnimation(d)
+	})
+}
+
+// SetPreDraw sets a function to be called with the frame time right
+// before every frame is drawn, after the program has completed the frame
+// with FrameEvent.Frame. Use it to update state that is read during
+// drawing, such as a Layer or a drawing passed to DrawTo, at a
+// consistent point in the frame. A nil function removes it.
+//
+// The function is called from the window's rendering thread and must not
+// block. It may call Invalidate to request another frame.
+func (w *Window) SetPreDraw(f func(now time.Time)) {
+	w.driverDefer(func(d driver) {
+		w.preDraw = f
+	})
+}
+
+// runAnimations calls the functions added by Animate, drops those that
+// have finished and schedules a frame for the rest.
+func (w *Window) runAnimations(now time.Time) {
+	anims := w.animations[:0]
+	for _, f := range w.animations {
+		if f(now) {
+			anims = append(anims, f)
+		}
+	}
+	for i := len(anims); i < len(w.animations); i++ {
+		w.animations[i] = nil
+	}
+	w.animations = anims
+	// Animations are frozen with the clock.
+	if len(anims) > 0 && !w.clock.paused {
+		w.setNextFrame(time.Time{}, RedrawAnimation)
+	}
+}
+
+// clockTime converts a wall clock time to a frame time.
+func (w *Window) clockTime(t time.Time) time.Time {
+	if w.clock.paused {
+		t = w.clock.pausedAt
+	}
+	return t.Add(-w.clock.offset)
+}
+
+// PauseClock freezes the frame time reported by FrameEvent.Now and
+// passed to animations, such as when the window loses focus. Animation
+// frames requested with op.InvalidateOp and Animate are held back while
+// the clock is paused, but other frames, such as for input and long
+// presses, are drawn as usual.
+func (w *Window) PauseClock() {
+	w.driverDefer(func(d driver) {
+		if w.clock.paused {
+			return
+		}
+		w.clock.paused = true
+		w.clock.pausedAt = time.Now()
+	})
+}
+
+// ResumeClock resumes the clock paused by PauseClock. Frame times
+// continue from the time of the pause, skipping the time the clock was
+// paused, so animations resume where they left off.
+func (w *Window) ResumeClock() {
+	w.driverDefer(func(d driver) {
+		if !w.clock.paused {
+			return
+		}
+		w.clock.paused = false
+		w.clock.offset += time.Since(w.clock.pausedAt)
+		w.setNextFrame(time.Time{}, RedrawAnimation)
+		w.updateAnimation(d)
+	})
+}
+
 func (w *Window) updateAnimation(d driver) {
 	animate := false
 	if w.stage >= system.StageInactive && w.hasNextFrame {
@@ -445,6 +2653,21 @@ func (w *Window) updateAnimation(d driver) {
 		w.animating = animate
 		d.SetAnimating(animate)
 	}
+	w.scheduledMu.Lock()
+	w.scheduled.ok = w.stage >= system.StageInactive && w.hasNextFrame
+	w.scheduled.at = w.nextFrame
+	w.scheduledMu.Unlock()
+}
+
+// cancelNextFrame discards the scheduled frame, if any.
+func (w *Window) cancelNextFrame(d driver) {
+	w.hasNextFrame = false
+	select {
+	case <-w.scheduledRedraws:
+	default:
+	}
+	w.scheduledRedraws <- time.Time{}
+	w.updateAnimation(d)
 }
 
 func (w *Window) wakeup() {
@@ -454,11 +2677,22 @@ func (w *Window) wakeup() {
 	}
 }
 
-func (w *Window) setNextFrame(at time.Time) {
+func (w *Window) setNextFrame(at time.Time, reason RedrawReason) {
 	if !w.hasNextFrame || at.Before(w.nextFrame) {
 		w.hasNextFrame = true
 		w.nextFrame = at
+		w.nextReason = reason
+	}
+}
+
+// Spawn runs the driver event loop, on a new goroutine or by
+// Window.ServeOn.
+func (c *callbacks) Spawn(loop func()) {
+	if !c.w.serveOn {
+		go loop()
+		return
 	}
+	c.w.serveLoops <- loop
 }
 
 func (c *callbacks) SetDriver(d driver) {
@@ -470,6 +2704,52 @@ func (c *callbacks) SetDriver(d driver) {
 	c.w.wakeupFuncs <- wakeup
 }
 
+// nextEvent removes and returns the next event to process from the
+// waiting events. Events wait only when the driver delivers them while
+// another event is processed, such as during a frame; other events are
+// processed as they arrive. Waiting input events are moved ahead of
+// waiting frames, and consecutive waiting frames are coalesced into the
+// most recent.
+func (c *callbacks) nextEvent() event.Event {
+	frames := 0
+	for frames < len(c.waitEvents) {
+		if _, ok := c.waitEvents[frames].(frameEvent); !ok {
+			break
+		}
+		frames++
+	}
+	var e event.Event
+	switch {
+	case frames > 0 && frames < len(c.waitEvents) && isInputEvent(c.waitEvents[frames]):
+		e = c.waitEvents[frames]
+		copy(c.waitEvents[1:], c.waitEvents[:frames])
+	case frames > 1:
+		// Only the last frame needs drawing, but it must refresh
+		// the context if any of the dropped frames did.
+		last := c.waitEvents[frames-1].(frameEvent)
+		for _, f := range c.waitEvents[:frames-1] {
+			last.Sync = last.Sync || f.(frameEvent).Sync
+		}
+		e = last
+		c.waitEvents = c.waitEvents[frames-1:]
+	default:
+		e = c.waitEvents[0]
+	}
+	copy(c.waitEvents, c.waitEvents[1:])
+	c.waitEvents = c.waitEvents[:len(c.waitEvents)-1]
+	return e
+}
+
+// isInputEvent reports whether e is pointer or keyboard input from the
+// user.
+func isInputEvent(e event.Event) bool {
+	switch e.(type) {
+	case pointer.Event, key.Event, key.EditEvent:
+		return true
+	}
+	return false
+}
+
 func (c *callbacks) Event(e event.Event) bool {
 	if c.d == nil {
 		panic("event while no driver active")
@@ -478,13 +2758,13 @@ func (c *callbacks) Event(e event.Event) bool {
 	if c.busy {
 		return true
 	}
+	if c.w.panicHandler != nil {
+		defer c.w.recoverPanic(c.d)
+	}
 	c.busy = true
 	var handled bool
 	for len(c.waitEvents) > 0 {
-		e := c.waitEvents[0]
-		copy(c.waitEvents, c.waitEvents[1:])
-		c.waitEvents = c.waitEvents[:len(c.waitEvents)-1]
-		handled = c.w.processEvent(c.d, e)
+		handled = c.w.processEvent(c.d, c.nextEvent())
 	}
 	c.busy = false
 	select {
@@ -496,11 +2776,26 @@ func (c *callbacks) Event(e event.Event) bool {
 	if _, ok := e.(wakeupEvent); ok {
 		select {
 		case opts := <-c.w.options:
-			cnf := Config{Decorated: c.w.decorations.enabled}
+			cnf := Config{Decorated: c.w.decorations.enabled, PresentMode: c.w.presentMode, ColorSpace: c.w.colorSpace, ForcedMetric: c.w.forcedMetric}
 			for _, opt := range opts {
 				opt(c.w.metric, &cnf)
 			}
 			c.w.decorations.enabled = cnf.Decorated
+			if cnf.ForcedMetric != c.w.forcedMetric {
+				c.w.forcedMetric = cnf.ForcedMetric
+				c.w.setNextFrame(time.Time{}, RedrawExternal)
+			}
+			if cnf.PresentMode != c.w.presentMode {
+				c.w.presentMode = cnf.PresentMode
+				c.w.refreshContext = true
+			}
+			if cnf.ColorSpace != c.w.colorSpace {
+				c.w.colorSpace = cnf.ColorSpace
+				// The framebuffer format is fixed for the lifetime
+				// of a context.
+				c.w.destroyGPU()
+				c.w.setNextFrame(time.Time{}, RedrawExternal)
+			}
 			decoHeight := c.w.decorations.height
 			if !c.w.decorations.enabled {
 				decoHeight = 0
@@ -518,6 +2813,53 @@ func (c *callbacks) Event(e event.Event) bool {
 	return handled
 }
 
+// SetRefreshRate records the refresh rate in Hz of the display showing
+// the window. A zero rate means unknown.
+func (c *callbacks) SetRefreshRate(hz float64) {
+	atomic.StoreUint64(&c.w.refreshRate, math.Float64bits(hz))
+}
+
+// SetWorkArea records the work area of the display showing the window.
+func (c *callbacks) SetWorkArea(r image.Rectangle) {
+	c.w.workAreaMu.Lock()
+	c.w.workArea = r
+	c.w.workAreaMu.Unlock()
+}
+
+// PresentMode returns the present mode requested for the window.
+func (c *callbacks) PresentMode() PresentMode {
+	if c.w.benchmark != nil {
+		return PresentImmediate
+	}
+	return c.w.presentMode
+}
+
+// WantsCloseRequest reports whether the program receives
+// CloseRequestEvent. Drivers close edited windows without confirmation
+// otherwise.
+func (c *callbacks) WantsCloseRequest() bool {
+	return c.w.wants(EventCloseRequest)
+}
+
+// ColorSpace returns the framebuffer color space requested for the window.
+func (c *callbacks) ColorSpace() ColorSpace {
+	return c.w.colorSpace
+}
+
+// GLESVersion returns the OpenGL ES version requested for the window,
+// or zeros if none was requested. It returns an error if a desktop
+// OpenGL version was requested, which EGL contexts can't provide.
+func (c *callbacks) GLESVersion() (major, minor int, err error) {
+	v := c.w.glVersion
+	if v == (GLContextVersion{}) {
+		return 0, 0, nil
+	}
+	if !v.ES {
+		return 0, 0, fmt.Errorf("app: OpenGL %d.%d requested, but only OpenGL ES is supported", v.Major, v.Minor)
+	}
+	return v.Major, v.Minor, nil
+}
+
 // SemanticRoot returns the ID of the semantic root.
 func (c *callbacks) SemanticRoot() router.SemanticID {
 	c.w.updateSemantics()
@@ -604,13 +2946,13 @@ func (w *Window) moveFocus(dir router.FocusDirection, d driver) {
 		dist := v.Mul(int(w.metric.Dp(scrollABit)))
 		w.queue.q.ScrollFocus(dist)
 	}
-	w.setNextFrame(time.Time{})
+	w.setNextFrame(time.Time{}, RedrawInput)
 	w.updateAnimation(d)
 }
 
 func (c *callbacks) ClickFocus() {
 	c.w.queue.q.ClickFocus()
-	c.w.setNextFrame(time.Time{})
+	c.w.setNextFrame(time.Time{}, RedrawInput)
 	c.w.updateAnimation(c.d)
 }
 
@@ -731,25 +3073,52 @@ func (w *Window) waitAck(d driver) {
 			return
 		case <-w.immediateRedraws:
 			// Invalidate was called during frame processing.
-			w.setNextFrame(time.Time{})
+			w.setNextFrame(time.Time{}, RedrawExternal)
 			w.updateAnimation(d)
 		}
 	}
 }
 
 func (w *Window) destroyGPU() {
-	if w.gpu != nil {
-		w.ctx.Lock()
-		w.gpu.Release()
-		w.ctx.Unlock()
-		w.gpu = nil
-	}
+	w.pendingPresent = false
+	w.capsMu.Lock()
+	w.caps = Caps{}
+	w.capsMu.Unlock()
+	w.releaseGPU()
+	w.presenter.stop()
 	if w.ctx != nil {
 		w.ctx.Release()
 		w.ctx = nil
 	}
 }
 
+// releaseGPU releases the GPU and its resources, but keeps the context.
+func (w *Window) releaseGPU() {
+	// The context may still be in use by the presenter.
+	w.awaitPresent()
+	w.pinned = nil
+	if w.gpu == nil {
+		return
+	}
+	w.ctx.Lock()
+	for _, f := range w.gpuRelease {
+		f()
+	}
+	w.gpu.Release()
+	w.ctx.Unlock()
+	w.gpu = nil
+	// Pending readbacks are discarded with the GPU.
+	n := 0
+	for _, rb := range w.readbacks {
+		if rb.done {
+			w.readbacks[n] = rb
+			n++
+		}
+	}
+	w.readbacks = w.readbacks[:n]
+	w.failScreenshots(errors.New("app: GPU released"))
+}
+
 // waitFrame waits for the client to either call FrameEvent.Frame
 // or to continue event handling.
 func (w *Window) waitFrame(d driver) *op.Ops {
@@ -766,7 +3135,7 @@ func (w *Window) waitFrame(d driver) *op.Ops {
 			return nil
 		case <-w.immediateRedraws:
 			// Invalidate was called during frame processing.
-			w.setNextFrame(time.Time{})
+			w.setNextFrame(time.Time{}, RedrawExternal)
 		}
 	}
 }
@@ -813,8 +3182,10 @@ func (w *Window) updateState(d driver) {
 		case f := <-w.driverFuncs:
 			f(d)
 		case <-w.redraws:
-			w.setNextFrame(time.Time{})
+			w.setNextFrame(time.Time{}, RedrawExternal)
 			w.updateAnimation(d)
+		case t := <-w.ticks:
+			w.processEvent(d, TickEvent{Now: t})
 		default:
 			return
 		}
@@ -827,15 +3198,31 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 		return false
 	default:
 	}
+	// Complete the notifications of system changes, so middleware can
+	// change them.
+	switch e2 := e.(type) {
+	case LocaleEvent:
+		e2.Locale = systemLocale()
+		e = e2
+	case ForcedColorsEvent:
+		e2.ForcedColors = systemForcedColors()
+		e = e2
+	}
+	if e = w.applyMiddleware(e); e == nil {
+		return false
+	}
 	switch e2 := e.(type) {
 	case system.StageEvent:
-		if e2.Stage < system.StageInactive {
-			if w.gpu != nil {
-				w.ctx.Lock()
-				w.gpu.Release()
-				w.gpu = nil
-				w.ctx.Unlock()
+		if w.zeroSized {
+			if e2.Stage >= system.StageInactive {
+				// Stay paused until the window has a size.
+				w.sizedStage = e2.Stage
+				break
 			}
+			w.zeroSized = false
+		}
+		if e2.Stage < system.StageInactive && !w.keepGPU {
+			w.releaseGPU()
 		}
 		w.stage = e2.Stage
 		w.updateAnimation(d)
@@ -843,20 +3230,57 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 		w.waitAck(d)
 	case frameEvent:
 		if e2.Size == (image.Point{}) {
-			panic(errors.New("internal error: zero-sized Draw"))
+			// A zero-sized window can't be drawn and is treated as
+			// paused until it has a size.
+			if !w.zeroSized && w.stage >= system.StageInactive {
+				stage := w.stage
+				w.processEvent(d, system.StageEvent{Stage: system.StagePaused})
+				w.zeroSized, w.sizedStage = true, stage
+			}
+			break
+		}
+		if w.zeroSized {
+			w.zeroSized = false
+			w.processEvent(d, system.StageEvent{Stage: w.sizedStage})
 		}
 		if w.stage < system.StageInactive {
 			// No drawing if not visible.
 			break
 		}
+		w.updateInsets(e2)
+		if w.forcedMetric != (unit.Metric{}) {
+			e2.Metric = w.forcedMetric
+		}
 		w.metric = e2.Metric
-		var frameStart time.Time
-		if w.queue.q.Profiling() {
-			frameStart = time.Now()
+		atomic.StoreUint32(&w.pxPerDp, math.Float32bits(e2.Metric.PxPerDp))
+		slop := w.touchSlop
+		if slop == 0 {
+			slop = defaultTouchSlop
+		}
+		w.queue.q.SetTouchSlop(float32(e2.Metric.Dp(slop)))
+		frameStart := time.Now()
+		if w.allocProfiling {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			w.allocStart, w.gcStart = stats.TotalAlloc, stats.NumGC
 		}
+		reason := RedrawSystem
+		if w.hasNextFrame && !w.nextFrame.After(e2.Now) {
+			reason = w.nextReason
+		}
+		w.scheduledMu.Lock()
+		w.scheduled.lastReason = reason
+		w.scheduledMu.Unlock()
 		w.hasNextFrame = false
+		e2.Now = w.clockTime(e2.Now)
+		w.runAnimations(e2.Now)
 		e2.Frame = w.update
 		e2.Queue = &w.queue
+		e2.Locale = w.Locale()
+		if !w.hinted || e2.Locale.Direction != w.hintedDir {
+			w.hinted, w.hintedDir = true, e2.Locale.Direction
+			d.SetLayoutDirection(e2.Locale.Direction)
+		}
 
 		// Prepare the decorations and update the frame insets.
 		wrapper := &w.decorations.Ops
@@ -880,42 +3304,209 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 		m := op.Record(wrapper)
 		size, offset := w.decorate(d, e2.FrameEvent, wrapper)
 		e2.FrameEvent.Size = size
+		renderSize, filter, scaled := w.renderSize(size)
+		var scale f32.Point
+		if scaled {
+			scale = f32.Pt(float32(size.X)/float32(renderSize.X), float32(size.Y)/float32(renderSize.Y))
+			e2.FrameEvent.Size = renderSize
+			e2.FrameEvent.Metric.PxPerDp /= scale.X
+			e2.FrameEvent.Metric.PxPerSp /= scale.X
+		}
 		deco := m.Stop()
-		w.out <- e2.FrameEvent
-		frame := w.waitFrame(d)
+		var frame *op.Ops
+		if w.wants(EventFrame) {
+			layoutStart := time.Now()
+			w.out <- e2.FrameEvent
+			frame = w.waitFrame(d)
+			w.trace.span("Layout", traceWindowThread, layoutStart, time.Since(layoutStart), nil)
+		}
 		var signal chan<- struct{}
+		input := wrapper
 		if frame != nil {
 			signal = w.frameAck
 			off := op.Offset(offset).Push(wrapper)
-			ops.AddCall(&wrapper.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+			if scaled {
+				w.drawScaled(wrapper, frame, renderSize, size, filter, scale)
+			} else {
+				ops.AddCall(&wrapper.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+			}
 			off.Pop()
 		}
 		deco.Add(wrapper)
-		if err := w.validateAndProcess(d, viewSize, e2.Sync, wrapper, signal); err != nil {
+		if w.overlay != nil {
+			w.overlay(wrapper, viewSize)
+		}
+		if scaled && frame != nil {
+			// Route input to the frame through the scale, below the
+			// decorations and overlay.
+			input = &w.scaled.input
+			input.Reset()
+			t := op.Affine(f32.Affine2D{}.Scale(f32.Point{}, scale).Offset(layout.FPt(offset))).Push(input)
+			ops.AddCall(&input.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+			t.Pop()
+			ops.AddCall(&input.Internal, &wrapper.Internal, ops.PC{}, ops.PCFor(&wrapper.Internal))
+		}
+		if w.preDraw != nil {
+			preDrawStart := time.Now()
+			w.preDraw(e2.Now)
+			w.trace.span("PreDraw", traceWindowThread, preDrawStart, time.Since(preDrawStart), nil)
+		}
+		err := w.validateAndProcess(d, viewSize, e2.Sync, frameStart, wrapper, input, signal)
+		// Drop the reference to the client frame; see FrameEvent.Frame.
+		wrapper.Reset()
+		w.scaled.input.Reset()
+		if w.nocontext {
+			// Drop drawings that can't be drawn. Drawings of skipped
+			// frames are kept for the next frame.
+			w.takeOffscreen()
+		}
+		if err != nil {
 			w.destroyGPU()
-			w.out <- system.DestroyEvent{Err: err}
-			close(w.out)
-			w.destroy <- struct{}{}
+			w.destroyWindow(system.DestroyEvent{Err: err, Reason: system.DestroyError})
 			break
 		}
 		w.processFrame(d, frameStart)
+		if _, _, s := w.renderSize(size); s != scaled {
+			// The support for scaling changed with the GPU.
+			w.setNextFrame(time.Time{}, RedrawExternal)
+			w.updateAnimation(d)
+		}
+		// Deliver the readbacks completed while drawing.
+		w.deliverReadbacks()
+		if w.readbacksPending() {
+			// Readbacks complete during later frames.
+			w.setNextFrame(time.Time{}, RedrawExternal)
+			w.updateAnimation(d)
+		}
 		w.updateCursor(d)
 	case system.DestroyEvent:
 		w.destroyGPU()
-		w.out <- e2
-		close(w.out)
-		w.destroy <- struct{}{}
+		switch {
+		case e2.Err != nil:
+			e2.Reason = system.DestroyError
+		case e2.Reason == system.DestroyUserClosed && atomic.LoadInt32(&w.appClosed) != 0:
+			e2.Reason = system.DestroyAppClosed
+		}
+		w.destroyWindow(e2)
 	case ViewEvent:
-		w.out <- e2
-		w.waitAck(d)
+		if w.wants(EventView) {
+			w.out <- e2
+			w.waitAck(d)
+		}
+	case SaveStateEvent:
+		if w.wants(EventSaveState) {
+			w.out <- e2
+			w.waitAck(d)
+		}
+	case TickEvent:
+		if w.wants(EventTick) {
+			w.out <- e2
+		}
+	case InstanceEvent:
+		if w.wants(EventInstance) {
+			w.out <- e2
+		}
+	case OpenURLEvent, OpenFileEvent:
+		if w.wants(EventOpen) {
+			w.out <- e2
+		}
+	case ForcedColorsEvent:
+		w.localeMu.Lock()
+		w.forcedColors = e2.ForcedColors
+		w.localeMu.Unlock()
+		if w.wants(EventForcedColors) {
+			w.out <- e2
+		}
+		w.setNextFrame(time.Time{}, RedrawSystem)
+		w.updateAnimation(d)
+	case LocaleEvent:
+		w.localeMu.Lock()
+		w.locale = e2.Locale
+		w.localeMu.Unlock()
+		// Apply the direction set by SetLayoutDirection.
+		e2.Locale = w.Locale()
+		if w.wants(EventLocale) {
+			w.out <- e2
+		}
+		w.setNextFrame(time.Time{}, RedrawSystem)
+		w.updateAnimation(d)
+	case ActiveEvent:
+		if w.wants(EventIdle) {
+			w.out <- e2
+		}
+	case IdleEvent:
+		if w.idle || w.idleTimeout == 0 || time.Since(w.lastInput) < w.idleTimeout {
+			// Input arrived after the timer fired.
+			break
+		}
+		w.idle = true
+		if w.wants(EventIdle) {
+			w.out <- e2
+		}
+	case DragResultEvent:
+		if w.wants(EventDragResult) {
+			w.out <- e2
+		}
+	case GestureEvent:
+		if w.wants(EventGesture) {
+			w.out <- e2
+		}
+	case CloseRequestEvent:
+		if w.wants(EventCloseRequest) {
+			w.out <- e2
+		}
+	case VisibilityEvent:
+		if e2.Visible+1 == w.visible {
+			break
+		}
+		w.visible = e2.Visible + 1
+		if w.wants(EventVisibility) {
+			w.out <- e2
+		}
 	case ConfigEvent:
+		if e2.Config.Material != w.decorations.Config.Material {
+			// The context may present differently behind a material.
+			w.refreshContext = true
+			w.setNextFrame(time.Time{}, RedrawSystem)
+			w.updateAnimation(d)
+		}
 		w.decorations.Config = e2.Config
 		e2.Config = w.effectiveConfig()
-		w.out <- e2
+		if w.wants(EventConfig) {
+			w.out <- e2
+		}
 	case event.Event:
+		if e, ok := e2.(clipboard.Event); ok && e.Type != "" {
+			// Replies to ReadClipboardData are for the program, not for
+			// the handlers waiting for text.
+			if w.wants(EventClipboardData) {
+				w.out <- e
+			}
+			break
+		}
+		if isInputEvent(e2) {
+			w.resetIdle(d)
+		}
+		if e, ok := e2.(pointer.Event); ok && e.Source == pointer.Mouse {
+			if w.trackCursor(e) {
+				// Redraw the custom cursor drawn by the program.
+				w.setNextFrame(time.Time{}, RedrawInput)
+				w.updateAnimation(d)
+			}
+		}
+		if _, wakeup := e.(wakeupEvent); !wakeup && w.rawInput != nil && w.rawInput(e2) {
+			return true
+		}
 		handled := w.queue.q.Queue(e2)
+		switch e2.(type) {
+		case key.Event, key.FocusEvent:
+			w.pressedMu.Lock()
+			w.pressed = w.queue.q.PressedKeys()
+			w.pressedMu.Unlock()
+		}
 		if handled {
-			w.setNextFrame(time.Time{})
+			w.trackInput(e2)
+			w.setNextFrame(time.Time{}, RedrawInput)
 			w.updateAnimation(d)
 		} else if e, ok := e.(key.Event); ok && e.State == key.Press {
 			handled = true
@@ -948,34 +3539,121 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 	return true
 }
 
+// updateInsets updates the insets from the platform insets of e, and
+// sends an InsetsEvent if they changed.
+func (w *Window) updateInsets(e frameEvent) {
+	m := e.Metric
+	in := Insets{
+		SystemTop:    m.Dp(e.Insets.Top),
+		SystemBottom: m.Dp(e.Insets.Bottom),
+		SystemLeft:   m.Dp(e.Insets.Left),
+		SystemRight:  m.Dp(e.Insets.Right),
+	}
+	if e.Keyboard < 0 {
+		e.Keyboard = 0
+	}
+	bottom := in.SystemBottom
+	if e.Keyboard > bottom {
+		bottom = e.Keyboard
+	}
+	// The platform insets include the keyboard.
+	in.SystemBottom = bottom - e.Keyboard
+	in.Keyboard = e.Keyboard
+	in.SafeArea = image.Rectangle{
+		Min: image.Pt(in.SystemLeft, in.SystemTop),
+		Max: image.Pt(e.Size.X-in.SystemRight, e.Size.Y-bottom),
+	}.Intersect(image.Rectangle{Max: e.Size})
+	w.insetsMu.Lock()
+	changed := in != w.insets
+	w.insets = in
+	w.insetsMu.Unlock()
+	if changed && w.wants(EventInsets) {
+		w.out <- InsetsEvent{Insets: in}
+	}
+}
+
+// Insets returns the parts of the window obscured by the system bars,
+// controls and the on-screen keyboard, as of the latest frame. An
+// InsetsEvent is sent when they change.
+//
+// The on-screen keyboard is reported on Android 11 and later and in
+// browsers. On iOS, the window shrinks instead of being covered by the
+// keyboard.
+func (w *Window) Insets() Insets {
+	w.insetsMu.Lock()
+	defer w.insetsMu.Unlock()
+	return w.insets
+}
+
+// destroyWindow sends the final event, e, closes the Events channel and
+// marks the window dead. Because the channel is unbuffered, it is closed
+// only after e is received, and no events are sent after e, because
+// processEvent ignores events once the window is dead.
+func (w *Window) destroyWindow(e system.DestroyEvent) {
+	leaveTransaction(w)
+	w.out <- e
+	close(w.out)
+	w.destroy <- struct{}{}
+	<-w.dead
+}
+
 func (w *Window) run(options []Option) {
 	if err := newWindow(&w.callbacks, options); err != nil {
-		w.out <- system.DestroyEvent{Err: err}
+		w.out <- system.DestroyEvent{Err: err, Reason: system.DestroyError}
 		close(w.out)
-		w.destroy <- struct{}{}
+		leaveTransaction(w)
+		removeWindow(w)
+		// There is no event loop to receive from w.destroy.
+		close(w.dead)
 		return
 	}
 	var wakeup func()
 	var timer *time.Timer
+	var ticker *time.Ticker
 	for {
 		var (
 			wakeups <-chan struct{}
 			timeC   <-chan time.Time
+			tickC   <-chan time.Time
 		)
 		if wakeup != nil {
 			wakeups = w.wakeups
 			if timer != nil {
 				timeC = timer.C
 			}
+			if ticker != nil {
+				tickC = ticker.C
+			}
 		}
 		select {
+		case d := <-w.tickIntervals:
+			if ticker != nil {
+				ticker.Stop()
+				ticker = nil
+			}
+			if d > 0 {
+				ticker = time.NewTicker(d)
+			}
+		case t := <-tickC:
+			select {
+			case w.ticks <- t:
+				wakeup()
+			default:
+			}
 		case t := <-w.scheduledRedraws:
 			if timer != nil {
 				timer.Stop()
+				timer = nil
+			}
+			if !t.IsZero() {
+				timer = time.NewTimer(time.Until(t))
 			}
-			timer = time.NewTimer(time.Until(t))
 		case <-w.destroy:
+			if ticker != nil {
+				ticker.Stop()
+			}
 			close(w.dead)
+			removeWindow(w)
 			return
 		case <-timeC:
 			select {
@@ -991,6 +3669,9 @@ func (w *Window) run(options []Option) {
 }
 
 func (w *Window) updateCursor(d driver) {
+	if w.customCursor {
+		return
+	}
 	if c := w.queue.q.Cursor(); c != w.cursor {
 		w.cursor = c
 		d.SetCursor(c)
@@ -1010,6 +3691,9 @@ func (w *Window) decorate(d driver, e system.FrameEvent, o *op.Ops) (size, offse
 	deco := w.decorations.Decorations
 	allActions := system.ActionMinimize | system.ActionMaximize | system.ActionUnmaximize |
 		system.ActionClose | system.ActionMove
+	if !w.decorations.Config.Resizable {
+		allActions &^= system.ActionMaximize | system.ActionUnmaximize
+	}
 	style := material.Decorations(w.decorations.Theme, deco, allActions, w.decorations.Config.Title)
 	// Update the decorations based on the current window mode.
 	var actions system.Action
@@ -1035,12 +3719,20 @@ func (w *Window) decorate(d driver, e system.FrameEvent, o *op.Ops) (size, offse
 	}
 	style.Layout(gtx)
 	// Update the window based on the actions on the decorations.
-	w.Perform(deco.Actions())
+	acts := deco.Actions()
+	if acts&system.ActionClose != 0 && w.decorations.Config.DocumentEdited && w.wants(EventCloseRequest) {
+		// Let the program confirm the close.
+		acts &^= system.ActionClose
+		w.processEvent(d, CloseRequestEvent{})
+	}
+	w.perform(acts)
 	// Offset to place the frame content below the decorations.
 	decoHeight := gtx.Dp(w.decorations.Config.decoHeight)
 	if w.decorations.currentHeight != decoHeight {
 		w.decorations.currentHeight = decoHeight
-		w.out <- ConfigEvent{Config: w.effectiveConfig()}
+		if w.wants(EventConfig) {
+			w.out <- ConfigEvent{Config: w.effectiveConfig()}
+		}
 	}
 	e.Size.Y -= w.decorations.currentHeight
 	return e.Size, image.Pt(0, decoHeight)
@@ -1053,8 +3745,28 @@ func (w *Window) effectiveConfig() Config {
 	return cnf
 }
 
+// Raise brings the window to the front of other windows and gives it the
+// keyboard focus, restoring it if minimized, for example when a second
+// instance of the program asks the first to show itself. It is
+// equivalent to Perform(system.ActionRaise).
+//
+// Raise is supported on Windows, X11 and macOS. Platforms may refuse to
+// activate a window of a program that is not in the foreground.
+func (w *Window) Raise() {
+	w.Perform(system.ActionRaise)
+}
+
 // Perform the actions on the window.
 func (w *Window) Perform(actions system.Action) {
+	if actions&system.ActionClose != 0 {
+		atomic.StoreInt32(&w.appClosed, 1)
+	}
+	w.perform(actions)
+}
+
+// perform is like Perform but doesn't consider ActionClose a request
+// from the program, as is the case for the fallback decorations.
+func (w *Window) perform(actions system.Action) {
 	walkActions(actions, func(action system.Action) {
 		switch action {
 		case system.ActionMinimize:
@@ -1142,6 +3854,36 @@ func MinSize(w, h unit.Dp) Option {
 	}
 }
 
+// AspectRatio constrains interactive resizes of the window to a width
+// to height ratio. A zero width or height removes the constraint.
+//
+// AspectRatio is supported on Windows, X11, macOS and Wayland.
+func AspectRatio(w, h int) Option {
+	if w < 0 || h < 0 {
+		panic("aspect ratio must be larger than or equal to 0")
+	}
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.AspectRatio = image.Pt(w, h)
+		if w == 0 || h == 0 {
+			cnf.AspectRatio = image.Point{}
+		}
+	}
+}
+
+// ResizeIncrements constrains interactive resizes of the window to
+// multiples of dx by dy pixels. A zero increment removes the constraint
+// in that direction.
+//
+// ResizeIncrements is supported on Windows, X11, macOS and Wayland.
+func ResizeIncrements(dx, dy int) Option {
+	if dx < 0 || dy < 0 {
+		panic("resize increments must be larger than or equal to 0")
+	}
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.ResizeIncrements = image.Pt(dx, dy)
+	}
+}
+
 // StatusColor sets the color of the Android status bar.
 func StatusColor(color color.NRGBA) Option {
 	return func(_ unit.Metric, cnf *Config) {
@@ -1156,6 +3898,22 @@ func NavigationColor(color color.NRGBA) Option {
 	}
 }
 
+// GLVersion requests an OpenGL context of a specific version, for
+// drivers that misbehave with the version chosen by default. If the
+// requested version can't be created, the default version is used;
+// Caps.GLVersion reports the version of the context. GLVersion only
+// applies to windows created with it.
+//
+// GLVersion is supported for OpenGL ES contexts created through EGL,
+// that is on Android, Wayland, X11 and Windows with ANGLE. Other
+// contexts use their default version. Desktop OpenGL versions, with es
+// false, are not supported and make the creation of EGL contexts fail.
+func GLVersion(major, minor int, es bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.GLVersion = GLContextVersion{Major: major, Minor: minor, ES: es}
+	}
+}
+
 // CustomRenderer controls whether the window contents is
 // rendered by the client. If true, no GPU context is created.
 //
@@ -1168,6 +3926,133 @@ func CustomRenderer(custom bool) Option {
 	}
 }
 
+// ServeOnCaller defers the event loop of the window until Window.ServeOn
+// is called, instead of running it on a goroutine owned by the window.
+// The window doesn't deliver events before ServeOn is called.
+func ServeOnCaller() Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.serveOnCaller = true
+	}
+}
+
+// Hidden controls whether the window is hidden from view. A hidden
+// window is in the system.StagePaused stage and receives no FrameEvents
+// until it is shown again. Create a window with Hidden(true) to configure
+// it before it is shown, avoiding a flash of unfinished content.
+//
+// Hidden is supported on Windows, X11 and macOS. Wayland, Android, iOS
+// and JS have no means to hide a window, so Hidden is ignored there and
+// Config.Hidden stays false.
+func Hidden(hidden bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Hidden = hidden
+	}
+}
+
+// Shadow requests the native drop shadow for windows without
+// decorations. Decorated windows always have the platform's shadow.
+// The Config.Shadow field of ConfigEvent reports whether the window has
+// a shadow.
+//
+// Shadow is supported on Windows. The shadow is automatic on macOS and
+// unsupported on other platforms.
+func Shadow(enable bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Shadow = enable
+	}
+}
+
+// SplashColor fills the window with c as soon as it is created, until
+// the first frame is drawn. It avoids the flash of the default
+// background while the GPU initializes. The alpha channel of c is
+// ignored, except that a transparent c disables the splash.
+//
+// SplashColor only has an effect when passed to NewWindow, and is
+// supported on Windows, X11, macOS and in browsers.
+func SplashColor(c color.NRGBA) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.SplashColor = c
+	}
+}
+
+// Resizable controls whether the user can resize the window by dragging
+// its edges, independent of the MinSize and MaxSize constraints. A
+// window that is not resizable can't be maximized by the user either.
+// Windows are resizable by default.
+//
+// Resizable is supported on Windows, X11, macOS and Wayland.
+func Resizable(enabled bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Resizable = enabled
+	}
+}
+
+// MinimizeToTray controls whether minimizing the window hides it
+// rather than minimizing it to the taskbar, as if by Hide. Use Show to
+// restore the window, for example from a tray icon.
+//
+// MinimizeToTray is supported on Windows, X11 and macOS. On Windows, the
+// minimize button of the system decorations is intercepted as well.
+func MinimizeToTray(enabled bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.MinimizeToTray = enabled
+	}
+}
+
+// ForceMetric replaces the metric reported by the platform with m, for
+// deterministic rendering regardless of the screen, such as for golden
+// image tests, or for previewing the program at other scales. The
+// window size is not affected. The zero Metric restores the metric of
+// the platform.
+func ForceMetric(m unit.Metric) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.ForcedMetric = m
+	}
+}
+
+// SkipTaskbar controls whether the window is left out of the taskbar
+// and the window switcher, such as for palettes, HUDs and utilities
+// controlled from a tray icon.
+//
+// SkipTaskbar is supported on Windows, X11 and macOS. On Windows, the
+// window gets the smaller title bar of tool windows. On macOS, the Dock
+// icon belongs to the application, and is hidden while every window
+// skips the taskbar.
+func SkipTaskbar(skip bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.SkipTaskbar = skip
+	}
+}
+
+// DocumentEdited marks the window document as having unsaved changes.
+// While edited, closing the window by the user sends a
+// CloseRequestEvent instead, to let the program ask whether to save the
+// changes. Programs that don't receive CloseRequestEvent, because of
+// SetEventFilter, are closed without confirmation. On macOS, the close
+// button of the window shows the unsaved indicator, but the program
+// must show its own confirmation: there is no native save sheet for
+// windows that are not NSDocument based.
+//
+// DocumentEdited is supported on macOS, Windows, X11 and Wayland.
+func DocumentEdited(edited bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.DocumentEdited = edited
+	}
+}
+
+// AcceptFirstMouse controls whether the click that activates an
+// inactive window is also delivered to the program as a pointer event.
+// When disabled, the click only activates the window. The default is
+// the convention of the platform: disabled on macOS and enabled
+// elsewhere.
+//
+// AcceptFirstMouse is supported on macOS and Windows.
+func AcceptFirstMouse(accept bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.AcceptFirstMouse = accept
+	}
+}
+
 // Decorated controls whether Gio and/or the platform are responsible
 // for drawing window decorations. Providing false indicates that
 // the application will either be undecorated or will draw its own decorations.
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"gioui.org/gpu"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+)
+
+func TestNextEvent(t *testing.T) {
+	frame := func(n int, sync bool) frameEvent {
+		return frameEvent{FrameEvent: system.FrameEvent{Size: image.Pt(n, n)}, Sync: sync}
+	}
+	press := pointer.Event{Type: pointer.Press}
+	edit := key.EditEvent{Text: "a"}
+	c := &callbacks{
+		waitEvents: []event.Event{
+			frame(1, true),
+			frame(2, false),
+			press,
+			frame(3, false),
+			edit,
+		},
+	}
+	var got []event.Event
+	for len(c.waitEvents) > 0 {
+		got = append(got, c.nextEvent())
+	}
+	// Input moves ahead of the frames, which are coalesced into the
+	// last frame, keeping the refresh of the first.
+	want := []event.Event{press, edit, frame(3, true)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+type fakeGPU struct {
+	gpu.GPU
+	released bool
+}
+
+func (g *fakeGPU) Release() {
+	g.released = true
+}
+
+type fakeContext struct {
+	context
+}
+
+func (c *fakeContext) Lock() error { return nil }
+func (c *fakeContext) Unlock()     {}
+
+func TestGPUReleaseOnPause(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		g := new(fakeGPU)
+		w := &Window{
+			out:     make(chan event.Event, 2),
+			dead:    make(chan struct{}),
+			gpu:     g,
+			ctx:     new(fakeContext),
+			stage:   system.StageRunning,
+			keepGPU: keep,
+		}
+		hooked := false
+		w.gpuRelease = append(w.gpuRelease, func() {
+			if g.released {
+				t.Error("OnGPURelease function called after the GPU was released")
+			}
+			hooked = true
+		})
+		w.processEvent(nil, system.StageEvent{Stage: system.StagePaused})
+		if release := !keep; hooked != release || g.released != release {
+			t.Errorf("keep %v: got hook %v, release %v; want %v", keep, hooked, g.released, release)
+		}
+	}
+}
+
+func TestSendEventRejectsLifecycle(t *testing.T) {
+	w := new(Window)
+	for _, e := range []event.Event{
+		system.StageEvent{Stage: system.StageRunning},
+		system.DestroyEvent{},
+		frameEvent{},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SendEvent(%T) didn't panic", e)
+				}
+			}()
+			w.SendEvent(e)
+		}()
+	}
+}
+
+func TestLoopWithoutClose(t *testing.T) {
+	defer func(f func() bool) { closeSupported = f }(closeSupported)
+	closeSupported = func() bool { return false }
+	w := &Window{out: make(chan event.Event)}
+	errFail := errors.New("fail")
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Loop(func(e event.Event) error {
+			return errFail
+		})
+	}()
+	w.out <- system.StageEvent{Stage: system.StageRunning}
+	if err := <-done; err != errFail {
+		t.Fatalf("Loop returned %v, want %v", err, errFail)
+	}
+	// Later events must not block the window.
+	w.out <- system.StageEvent{Stage: system.StagePaused}
+	close(w.out)
+}
+
+func TestMiddlewareEvents(t *testing.T) {
+	w := &Window{
+		out:         make(chan event.Event, 10),
+		dead:        make(chan struct{}),
+		idleTimeout: time.Hour,
+	}
+	var seen []event.Event
+	w.middleware = append(w.middleware, func(e event.Event) event.Event {
+		seen = append(seen, e)
+		return e
+	})
+	tick := TickEvent{Now: time.Unix(1, 0)}
+	w.processEvent(nil, tick)
+	w.processEvent(nil, VisibilityEvent{Visible: 1})
+	// An idle window becomes active on input.
+	w.idle = true
+	w.resetIdle(nil)
+	w.idleTimer.Stop()
+	want := []event.Event{tick, VisibilityEvent{Visible: 1}, ActiveEvent{}}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("middleware saw %v, want %v", seen, want)
+	}
+}
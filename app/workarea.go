@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import "image"
+
+// strut is the space reserved by a panel along the edges of the screen,
+// in the layout of the X11 _NET_WM_STRUT_PARTIAL property: the widths
+// reserved from the left, right, top and bottom edges, followed by the
+// first and last coordinate covered along each edge.
+type strut [12]int
+
+// workArea returns the part of a monitor not covered by struts, where
+// screen is the size of the screen containing every monitor.
+func workArea(monitor image.Rectangle, screen image.Point, struts []strut) image.Rectangle {
+	area := monitor
+	// covers reports whether the range [start, end] overlaps [min, max).
+	covers := func(start, end, min, max int) bool {
+		return start < max && end >= min
+	}
+	for _, s := range struts {
+		left, right, top, bottom := s[0], s[1], s[2], s[3]
+		if left > 0 && covers(s[4], s[5], monitor.Min.Y, monitor.Max.Y) && left > area.Min.X {
+			area.Min.X = left
+		}
+		if right > 0 && covers(s[6], s[7], monitor.Min.Y, monitor.Max.Y) && screen.X-right < area.Max.X {
+			area.Max.X = screen.X - right
+		}
+		if top > 0 && covers(s[8], s[9], monitor.Min.X, monitor.Max.X) && top > area.Min.Y {
+			area.Min.Y = top
+		}
+		if bottom > 0 && covers(s[10], s[11], monitor.Min.X, monitor.Max.X) && screen.Y-bottom < area.Max.Y {
+			area.Max.Y = screen.Y - bottom
+		}
+	}
+	if area.Empty() {
+		// Ignore struts that cover the whole monitor.
+		return monitor
+	}
+	return area
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"testing"
+)
+
+func TestWorkArea(t *testing.T) {
+	// Two monitors side by side, with a taskbar along the bottom of the
+	// left monitor and a panel along the top of the right monitor.
+	screen := image.Pt(3840, 1080)
+	left := image.Rect(0, 0, 1920, 1080)
+	right := image.Rect(1920, 0, 3840, 1080)
+	struts := []strut{
+		{0, 0, 0, 40, 0, 0, 0, 0, 0, 0, 0, 1919},
+		{0, 0, 30, 0, 0, 0, 0, 0, 1920, 3839, 0, 0},
+	}
+	if got, want := workArea(left, screen, struts), image.Rect(0, 0, 1920, 1040); got != want {
+		t.Errorf("left monitor: got %v, want %v", got, want)
+	}
+	if got, want := workArea(right, screen, struts), image.Rect(1920, 30, 3840, 1080); got != want {
+		t.Errorf("right monitor: got %v, want %v", got, want)
+	}
+	// A dock along the left edge of the screen only touches the left
+	// monitor.
+	dock := []strut{{64, 0, 0, 0, 0, 1079, 0, 0, 0, 0, 0, 0}}
+	if got, want := workArea(left, screen, dock), image.Rect(64, 0, 1920, 1080); got != want {
+		t.Errorf("left monitor: got %v, want %v", got, want)
+	}
+	if got := workArea(right, screen, dock); got != right {
+		t.Errorf("right monitor: got %v, want %v", got, right)
+	}
+}
@@ -45,6 +45,7 @@
                   xorg.libX11
                   xorg.libXcursor
                   xorg.libXfixes
+                  xorg.libXrandr
                   libGL
                   pkgconfig
                 ] else if stdenv.isDarwin then [
@@ -15,3 +15,5 @@ require (
 )
 
 require golang.org/x/text v0.7.0
+
+require golang.org/x/net v0.0.0-20210510120150-4163338589ed // indirect
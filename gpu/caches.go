@@ -10,6 +10,8 @@ import (
 
 type resourceCache struct {
 	res map[interface{}]resourceCacheValue
+	// pinned are the keys that are kept even when unused.
+	pinned map[interface{}]struct{}
 }
 
 type resourceCacheValue struct {
@@ -65,12 +67,27 @@ func (r *resourceCache) put(key interface{}, val resource) {
 	r.res[key] = v
 }
 
+// pin keeps the resource of key in the cache until unpinned, even
+// when it is not used.
+func (r *resourceCache) pin(key interface{}) {
+	if r.pinned == nil {
+		r.pinned = make(map[interface{}]struct{})
+	}
+	r.pinned[key] = struct{}{}
+}
+
+// unpin undoes pin. The resource is released at the end of the next
+// frame it is not used.
+func (r *resourceCache) unpin(key interface{}) {
+	delete(r.pinned, key)
+}
+
 func (r *resourceCache) frame() {
 	for k, v := range r.res {
 		if v.used {
 			v.used = false
 			r.res[k] = v
-		} else {
+		} else if _, pinned := r.pinned[k]; !pinned {
 			delete(r.res, k)
 			v.resource.release()
 		}
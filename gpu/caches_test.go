@@ -19,6 +19,41 @@ func BenchmarkResourceCache(b *testing.B) {
 	}
 }
 
+func TestResourceCachePin(t *testing.T) {
+	cache := newResourceCache()
+	var released []int
+	cache.put(1, &countResource{id: 1, released: &released})
+	cache.put(2, &countResource{id: 2, released: &released})
+	cache.pin(1)
+	// Two frames without use evict unpinned resources.
+	cache.frame()
+	cache.frame()
+	if _, ok := cache.get(1); !ok {
+		t.Error("pinned resource was evicted")
+	}
+	if _, ok := cache.get(2); ok {
+		t.Error("unpinned resource was not evicted")
+	}
+	cache.unpin(1)
+	cache.frame()
+	cache.frame()
+	if _, ok := cache.get(1); ok {
+		t.Error("unpinned resource was not evicted")
+	}
+	if len(released) != 2 {
+		t.Errorf("released %v, want both resources", released)
+	}
+}
+
+type countResource struct {
+	id       int
+	released *[]int
+}
+
+func (r *countResource) release() {
+	*r.released = append(*r.released, r.id)
+}
+
 type nullResource struct{}
 
 func (nullResource) release() {}
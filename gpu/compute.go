@@ -29,6 +29,7 @@ import (
 	"gioui.org/internal/scene"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/paint"
 	"gioui.org/shader"
 	"gioui.org/shader/gio"
 	"gioui.org/shader/piet"
@@ -46,6 +47,12 @@ type compute struct {
 	atlases       []*textureAtlas
 	frameCount    uint
 	moves         []atlasMove
+	// capture is the texture drawn into by Capture.
+	capture captureTarget
+	// readbacks are the captures of CaptureAsync.
+	readbacks readbacks
+	// inFlight bounds the frames in flight.
+	inFlight inFlightFrames
 
 	programs struct {
 		elements   computeProgram
@@ -94,7 +101,9 @@ type compute struct {
 	}
 	timers struct {
 		profile string
+		timings Timings
 		t       *timers
+		upload  *timer
 		compact *timer
 		render  *timer
 		blit    *timer
@@ -574,8 +583,18 @@ func newShaders(ctx driver.Device, vsrc, fsrc shader.Sources) (vert driver.Verte
 
 func (g *compute) Frame(frameOps *op.Ops, target RenderTarget, viewport image.Point) error {
 	g.frameCount++
+	g.readbacks.poll(g.ctx)
+	g.inFlight.wait()
 	g.collect(viewport, frameOps)
-	return g.frame(target)
+	if err := g.frame(target); err != nil {
+		return err
+	}
+	g.inFlight.add(g.ctx)
+	return nil
+}
+
+func (g *compute) SetMaxInFlightFrames(n int) {
+	g.inFlight.setMax(n)
 }
 
 func (g *compute) collect(viewport image.Point, ops *op.Ops) {
@@ -586,6 +605,8 @@ func (g *compute) collect(viewport image.Point, ops *op.Ops) {
 	g.collector.collect(ops, viewport, &g.texOps)
 }
 
+func (g *compute) SetTessellationTolerance(px float32) {}
+
 func (g *compute) Clear(col color.NRGBA) {
 	g.collector.clear = true
 	g.collector.clearColor = f32color.LinearFromSRGB(col)
@@ -599,17 +620,20 @@ func (g *compute) frame(target RenderTarget) error {
 	t := &g.timers
 	if g.collector.profile && t.t == nil && g.ctx.Caps().Features.Has(driver.FeatureTimers) {
 		t.t = newTimers(g.ctx)
+		t.upload = t.t.newTimer()
 		t.compact = t.t.newTimer()
 		t.render = t.t.newTimer()
 		t.blit = t.t.newTimer()
 	}
 
+	t.upload.begin()
 	if err := g.uploadImages(); err != nil {
 		return err
 	}
 	if err := g.renderMaterials(); err != nil {
 		return err
 	}
+	t.upload.end()
 	g.layer(viewport, g.texOps)
 	t.render.begin()
 	if err := g.renderLayers(viewport); err != nil {
@@ -633,6 +657,7 @@ func (g *compute) frame(target RenderTarget) error {
 	t.compact.end()
 	if g.collector.profile && t.t.ready() {
 		com, ren, blit := t.compact.Elapsed, t.render.Elapsed, t.blit.Elapsed
+		t.timings = Timings{Tessellate: ren, Upload: t.upload.Elapsed, Draw: blit, Cleanup: com}
 		ft := com + ren + blit
 		q := 100 * time.Microsecond
 		ft = ft.Round(q)
@@ -661,10 +686,66 @@ func (g *compute) dumpAtlases() {
 	}
 }
 
+func (g *compute) DrawTo(frame *op.Ops, img paint.ImageOp) {}
+
+func (g *compute) CanDrawTo() bool {
+	return false
+}
+
+func (g *compute) Capture(frame *op.Ops, img *image.RGBA) error {
+	g.readbacks.poll(g.ctx)
+	r := img.Bounds()
+	// The compute renderer can't offset frames, so draw the area from
+	// the origin.
+	size := r.Max
+	tex, err := g.capture.texture(g.ctx, size)
+	if err != nil {
+		return err
+	}
+	g.collector.clear = true
+	g.collect(size, frame)
+	if err := g.frame(tex); err != nil {
+		return err
+	}
+	if r.Min == (image.Point{}) {
+		return driver.DownloadImage(g.ctx, tex, img)
+	}
+	full := image.NewRGBA(image.Rectangle{Max: size})
+	if err := driver.DownloadImage(g.ctx, tex, full); err != nil {
+		return err
+	}
+	draw.Draw(img, r, full, r.Min, draw.Src)
+	return nil
+}
+
+func (g *compute) CaptureAsync(frame *op.Ops, size image.Point, done func(img *image.RGBA, err error)) {
+	g.readbacks.poll(g.ctx)
+	tex, err := g.readbacks.texture(g.ctx, size)
+	if err != nil {
+		done(nil, err)
+		return
+	}
+	g.collector.clear = true
+	g.collect(size, frame)
+	if err := g.frame(tex.tex); err != nil {
+		tex.tex.Release()
+		done(nil, err)
+		return
+	}
+	g.readbacks.add(g.ctx, tex, done)
+}
+
+func (g *compute) Pin(img paint.ImageOp)   {}
+func (g *compute) Unpin(img paint.ImageOp) {}
+
 func (g *compute) Profile() string {
 	return g.timers.profile
 }
 
+func (g *compute) TimingBreakdown() Timings {
+	return g.timers.timings
+}
+
 func (g *compute) compactAllocs() error {
 	const (
 		maxAllocAge = 3
@@ -1499,6 +1580,9 @@ func (g *compute) Release() {
 		&g.materials.buffer,
 		g.materials.uniforms.buf,
 		g.timers.t,
+		&g.capture,
+		&g.readbacks,
+		&g.inFlight,
 	}
 	for _, r := range res {
 		if r != nil {
@@ -27,6 +27,7 @@ import (
 	"gioui.org/internal/stroke"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/paint"
 	"gioui.org/shader"
 	"gioui.org/shader/gio"
 
@@ -48,18 +49,144 @@ type GPU interface {
 	// information is requested when Frame sees an io/profile.Op, and the result
 	// is available through Profile at some later time.
 	Profile() string
+	// DrawTo schedules the drawing of the graphics operations from frame
+	// into the texture of img during the next call to Frame, before the
+	// frame itself is drawn. Painting img in the frame displays the
+	// drawing instead of the image content, for as long as img is
+	// painted in every frame. The frame operations must not change until
+	// Frame returns. Images that are uniform colors are ignored.
+	//
+	// DrawTo is not supported by the compute renderer; see CanDrawTo.
+	DrawTo(frame *op.Ops, img paint.ImageOp)
+	// CanDrawTo reports whether DrawTo is supported.
+	CanDrawTo() bool
+	// Pin keeps the texture of img in GPU memory until Unpin, even for
+	// frames that don't paint img. The texture is uploaded the first
+	// time img is painted. Images that are uniform colors are ignored.
+	//
+	// Pin has no effect for the compute renderer.
+	Pin(img paint.ImageOp)
+	// Unpin undoes Pin.
+	Unpin(img paint.ImageOp)
+	// Capture draws the graphics operations from frame like Frame, but
+	// into a texture whose pixels are then copied to img. The viewport
+	// is the bounds of img, and the clear color is the color set by
+	// Clear. Capture is more expensive than Frame, because it waits for
+	// the GPU to finish drawing.
+	//
+	// Only the area of the frame covered by the bounds of img is drawn
+	// and copied, except for the compute renderer that draws the area
+	// from the origin.
+	Capture(frame *op.Ops, img *image.RGBA) error
+	// CaptureAsync is like Capture, but doesn't wait for the GPU. The
+	// pixels are read back by a later call to Frame, Capture or
+	// CaptureAsync once the GPU has finished drawing, and done is called
+	// from that call with the image of the size, or an error. Pending
+	// captures are discarded by Release.
+	//
+	// Where the GPU API doesn't support fences, such as for Metal and
+	// Vulkan, CaptureAsync waits for the GPU like Capture.
+	CaptureAsync(frame *op.Ops, size image.Point, done func(img *image.RGBA, err error))
+	// SetTessellationTolerance sets the maximum distance in pixels
+	// between curves and their approximations. A higher tolerance
+	// results in fewer vertices at the cost of smoothness. The default,
+	// zero, selects a tolerance relative to the size of every curve.
+	//
+	// The tolerance is ignored by the compute renderer.
+	SetTessellationTolerance(px float32)
+	// TimingBreakdown is like Profile, but returns the GPU time of the
+	// individual rendering stages. The timings are zero until profiling
+	// information is available.
+	TimingBreakdown() Timings
+	// SetMaxInFlightFrames limits the number of frames submitted to the
+	// GPU but not yet completed. Frame blocks until the GPU is within n
+	// frames of completion before submitting another. A lower limit
+	// reduces latency at the cost of throughput. The default, zero,
+	// leaves the number of frames to the GPU driver. Where the GPU API
+	// bounds the frames itself, such as for Metal and Vulkan, the limit
+	// has no effect.
+	SetMaxInFlightFrames(n int)
+}
+
+// Timings is the GPU time spent on the stages of rendering a frame.
+type Timings struct {
+	// Tessellate is the time spent converting paths and clip shapes
+	// into coverage.
+	Tessellate time.Duration
+	// Upload is the time spent uploading images and preparing
+	// drawing data.
+	Upload time.Duration
+	// Draw is the time spent drawing the frame into its target.
+	Draw time.Duration
+	// Cleanup is the time spent releasing and compacting resources
+	// after drawing.
+	Cleanup time.Duration
 }
 
 type gpu struct {
 	cache *resourceCache
 
-	profile                                string
-	timers                                 *timers
-	frameStart                             time.Time
-	stencilTimer, coverTimer, cleanupTimer *timer
-	drawOps                                drawOps
-	ctx                                    driver.Device
-	renderer                               *renderer
+	profile                                             string
+	timings                                             Timings
+	timers                                              *timers
+	frameStart                                          time.Time
+	stencilTimer, uploadTimer, coverTimer, cleanupTimer *timer
+	drawOps                                             drawOps
+	ctx                                                 driver.Device
+	renderer                                            *renderer
+
+	// offscreen are the drawings scheduled by DrawTo.
+	offscreen []offscreenDraw
+	// scratch is used for decoding image operations.
+	scratch op.Ops
+	// capture is the texture drawn into by Capture.
+	capture captureTarget
+	// readbacks are the captures of CaptureAsync.
+	readbacks readbacks
+	// inFlight bounds the frames in flight.
+	inFlight inFlightFrames
+}
+
+// readbacks tracks the textures drawn by CaptureAsync until the GPU
+// completes them.
+type readbacks struct {
+	pending []readback
+	// free are textures of completed readbacks, for reuse.
+	free []readbackTexture
+}
+
+type readback struct {
+	// tex is the texture of the readback, or nil if its pixels are
+	// staged.
+	tex     readbackTexture
+	staging driver.Staging
+	size    image.Point
+	fence   driver.Fence
+	done    func(img *image.RGBA, err error)
+}
+
+type readbackTexture struct {
+	tex  driver.Texture
+	size image.Point
+}
+
+// inFlightFrames tracks the frames submitted to the GPU with a fence
+// per frame.
+type inFlightFrames struct {
+	max    int
+	fences []driver.Fence
+}
+
+// captureTarget is a texture for capturing frames.
+type captureTarget struct {
+	tex  driver.Texture
+	size image.Point
+}
+
+// offscreenDraw is a drawing into the texture of an image.
+type offscreenDraw struct {
+	frame *op.Ops
+	img   imageOpData
 }
 
 type renderer struct {
@@ -71,7 +198,9 @@ type renderer struct {
 }
 
 type drawOps struct {
-	profile     bool
+	profile bool
+	// root is the transformation applied to all operations.
+	root        f32.Affine2D
 	reader      ops.Reader
 	states      []f32.Affine2D
 	transStack  []f32.Affine2D
@@ -84,6 +213,9 @@ type drawOps struct {
 	pathOpCache []pathOp
 	qs          quadSplitter
 	pathCache   *opCache
+	// tolerance is the tessellation tolerance in pixels, or zero for
+	// the default.
+	tolerance float32
 }
 
 type drawState struct {
@@ -165,6 +297,7 @@ type material struct {
 type imageOpData struct {
 	src    *image.RGBA
 	handle interface{}
+	filter paint.ImageFilter
 }
 
 type linearGradientOpData struct {
@@ -182,6 +315,7 @@ func decodeImageOp(data []byte, refs []interface{}) imageOpData {
 	return imageOpData{
 		src:    refs[0].(*image.RGBA),
 		handle: handle,
+		filter: paint.ImageFilter(data[1]),
 	}
 }
 
@@ -231,6 +365,13 @@ type resource interface {
 type texture struct {
 	src *image.RGBA
 	tex driver.Texture
+	// target is set for textures drawn into by DrawTo.
+	target bool
+	// filter is the filter the texture was created with.
+	filter paint.ImageFilter
+	// other is the texture of src with the other filter, for images
+	// drawn with both filters.
+	other driver.Texture
 }
 
 type blitter struct {
@@ -344,7 +485,23 @@ func (g *gpu) Clear(col color.NRGBA) {
 	g.drawOps.clearColor = f32color.LinearFromSRGB(col)
 }
 
+func (g *gpu) SetTessellationTolerance(px float32) {
+	if px < 0 {
+		px = 0
+	}
+	if px == g.drawOps.tolerance {
+		return
+	}
+	g.drawOps.tolerance = px
+	// Discard the vertices built with the previous tolerance.
+	g.drawOps.pathCache.release()
+	g.drawOps.pathCache = newOpCache()
+}
+
 func (g *gpu) Release() {
+	g.inFlight.Release()
+	g.capture.Release()
+	g.readbacks.Release()
 	g.renderer.release()
 	g.drawOps.pathCache.release()
 	g.cache.release()
@@ -355,8 +512,331 @@ func (g *gpu) Release() {
 }
 
 func (g *gpu) Frame(frameOps *op.Ops, target RenderTarget, viewport image.Point) error {
+	if len(g.offscreen) > 0 {
+		if err := g.drawOffscreen(); err != nil {
+			return err
+		}
+	}
+	g.readbacks.poll(g.ctx)
+	g.inFlight.wait()
 	g.collect(viewport, frameOps)
-	return g.frame(target)
+	if err := g.frame(target); err != nil {
+		return err
+	}
+	g.inFlight.add(g.ctx)
+	return nil
+}
+
+func (g *gpu) SetMaxInFlightFrames(n int) {
+	g.inFlight.setMax(n)
+}
+
+func (f *inFlightFrames) setMax(n int) {
+	if n < 0 {
+		n = 0
+	}
+	f.max = n
+}
+
+// wait blocks until fewer than the maximum frames are in flight.
+func (f *inFlightFrames) wait() {
+	if f.max == 0 {
+		// The fences of a previous limit are no longer needed.
+		f.Release()
+		return
+	}
+	n := 0
+	for len(f.fences)-n >= f.max {
+		f.fences[n].Wait()
+		f.fences[n].Release()
+		n++
+	}
+	f.fences = append(f.fences[:0], f.fences[n:]...)
+}
+
+// add a fence for the most recently submitted frame.
+func (f *inFlightFrames) add(ctx driver.Device) {
+	if f.max == 0 {
+		return
+	}
+	if fence := ctx.NewFence(); fence != nil {
+		f.fences = append(f.fences, fence)
+	}
+}
+
+func (f *inFlightFrames) Release() {
+	for _, fence := range f.fences {
+		fence.Release()
+	}
+	f.fences = nil
+}
+
+func (g *gpu) Capture(frame *op.Ops, img *image.RGBA) error {
+	g.readbacks.poll(g.ctx)
+	r := img.Bounds()
+	size := r.Size()
+	tex, err := g.capture.texture(g.ctx, size)
+	if err != nil {
+		return err
+	}
+	// Draw the area of img at the texture origin.
+	g.drawOps.root = f32.Affine2D{}.Offset(f32.Point{X: -float32(r.Min.X), Y: -float32(r.Min.Y)})
+	g.drawCapture(frame, tex, size)
+	g.drawOps.root = f32.Affine2D{}
+	return driver.DownloadImage(g.ctx, tex, originImage(img))
+}
+
+// originImage returns an image with the pixels of img, but with its
+// bounds moved to the origin.
+func originImage(img *image.RGBA) *image.RGBA {
+	if img.Rect.Min == (image.Point{}) {
+		return img
+	}
+	return &image.RGBA{
+		Pix:    img.Pix,
+		Stride: img.Stride,
+		Rect:   image.Rectangle{Max: img.Rect.Size()},
+	}
+}
+
+func (g *gpu) CaptureAsync(frame *op.Ops, size image.Point, done func(img *image.RGBA, err error)) {
+	g.readbacks.poll(g.ctx)
+	tex, err := g.readbacks.texture(g.ctx, size)
+	if err != nil {
+		done(nil, err)
+		return
+	}
+	g.drawCapture(frame, tex.tex, size)
+	g.readbacks.add(g.ctx, tex, done)
+}
+
+// drawCapture draws frame into tex.
+func (g *gpu) drawCapture(frame *op.Ops, tex driver.Texture, size image.Point) {
+	fbo := g.ctx.BeginFrame(tex, true, size)
+	g.collect(size, frame)
+	g.prepare()
+	g.stencil()
+	g.upload()
+	g.draw(fbo, size, driver.LoadDesc{
+		Action:     driver.LoadActionClear,
+		ClearColor: g.drawOps.clearColor,
+	})
+	g.ctx.EndFrame()
+}
+
+// texture returns a texture of the size for a readback.
+func (r *readbacks) texture(ctx driver.Device, size image.Point) (readbackTexture, error) {
+	for i, t := range r.free {
+		if t.size == size {
+			r.free = append(r.free[:i], r.free[i+1:]...)
+			return t, nil
+		}
+	}
+	tex, err := ctx.NewTexture(driver.TextureFormatSRGBA, size.X, size.Y, driver.FilterNearest, driver.FilterNearest, driver.BufferBindingFramebuffer)
+	if err != nil {
+		return readbackTexture{}, err
+	}
+	return readbackTexture{tex: tex, size: size}, nil
+}
+
+// add a readback of tex, drawn by the commands submitted so far.
+func (r *readbacks) add(ctx driver.Device, tex readbackTexture, done func(img *image.RGBA, err error)) {
+	rb := readback{tex: tex, size: tex.size, done: done}
+	// Stage the pixels if possible, so completing the readback doesn't
+	// wait for the GPU. The texture is then free for the next readback.
+	if st, ok := tex.tex.(driver.StagingTexture); ok {
+		if s, err := st.StagePixels(image.Rectangle{Max: tex.size}); err == nil {
+			rb.staging = s
+			rb.tex = readbackTexture{}
+			r.recycle(tex)
+		}
+	}
+	fence := ctx.NewFence()
+	rb.fence = fence
+	if fence == nil {
+		r.complete(ctx, rb)
+		return
+	}
+	r.pending = append(r.pending, rb)
+}
+
+// poll completes the readbacks finished by the GPU, in order.
+func (r *readbacks) poll(ctx driver.Device) {
+	n := 0
+	for _, rb := range r.pending {
+		if !rb.fence.Done() {
+			break
+		}
+		rb.fence.Release()
+		r.complete(ctx, rb)
+		n++
+	}
+	r.pending = append(r.pending[:0], r.pending[n:]...)
+}
+
+// complete reads back the pixels of rb and calls its function.
+func (r *readbacks) complete(ctx driver.Device, rb readback) {
+	img := image.NewRGBA(image.Rectangle{Max: rb.size})
+	var err error
+	if rb.staging != nil {
+		err = driver.ReadStaging(ctx, rb.staging, img)
+		rb.staging.Release()
+	} else {
+		err = driver.DownloadImage(ctx, rb.tex.tex, img)
+		r.recycle(rb.tex)
+	}
+	if err != nil {
+		img = nil
+	}
+	rb.done(img, err)
+}
+
+// recycle tex for later readbacks.
+func (r *readbacks) recycle(tex readbackTexture) {
+	// Keep only a few textures for reuse.
+	const maxFree = 3
+	if len(r.free) < maxFree {
+		r.free = append(r.free, tex)
+	} else {
+		tex.tex.Release()
+	}
+}
+
+func (r *readbacks) Release() {
+	for _, rb := range r.pending {
+		rb.fence.Release()
+		if rb.staging != nil {
+			rb.staging.Release()
+		} else {
+			rb.tex.tex.Release()
+		}
+	}
+	for _, t := range r.free {
+		t.tex.Release()
+	}
+	*r = readbacks{}
+}
+
+// texture returns a texture of the size, suitable for drawing into and
+// reading back.
+func (c *captureTarget) texture(ctx driver.Device, size image.Point) (driver.Texture, error) {
+	if c.tex != nil && c.size == size {
+		return c.tex, nil
+	}
+	c.Release()
+	tex, err := ctx.NewTexture(driver.TextureFormatSRGBA, size.X, size.Y, driver.FilterNearest, driver.FilterNearest, driver.BufferBindingFramebuffer)
+	if err != nil {
+		return nil, err
+	}
+	c.tex, c.size = tex, size
+	return tex, nil
+}
+
+func (c *captureTarget) Release() {
+	if c.tex != nil {
+		c.tex.Release()
+	}
+	*c = captureTarget{}
+}
+
+func (g *gpu) DrawTo(frame *op.Ops, img paint.ImageOp) {
+	if data, ok := g.decodeImage(img); ok {
+		g.offscreen = append(g.offscreen, offscreenDraw{frame: frame, img: data})
+	}
+}
+
+func (g *gpu) CanDrawTo() bool {
+	return true
+}
+
+func (g *gpu) Pin(img paint.ImageOp) {
+	if data, ok := g.decodeImage(img); ok {
+		g.cache.pin(data.handle)
+	}
+}
+
+func (g *gpu) Unpin(img paint.ImageOp) {
+	if data, ok := g.decodeImage(img); ok {
+		g.cache.unpin(data.handle)
+	}
+}
+
+// decodeImage returns the image data of img, if img is not a uniform
+// color.
+func (g *gpu) decodeImage(img paint.ImageOp) (imageOpData, bool) {
+	g.scratch.Reset()
+	defer g.scratch.Reset()
+	img.Add(&g.scratch)
+	var r ops.Reader
+	r.Reset(&g.scratch.Internal)
+	encOp, ok := r.Decode()
+	if !ok || ops.OpType(encOp.Data[0]) != ops.TypeImage {
+		return imageOpData{}, false
+	}
+	data := decodeImageOp(encOp.Data, encOp.Refs)
+	return data, data.handle != nil
+}
+
+// drawOffscreen draws the frames scheduled by DrawTo into the
+// textures of their images.
+func (g *gpu) drawOffscreen() error {
+	g.ctx.BeginFrame(nil, false, image.Point{})
+	defer g.ctx.EndFrame()
+	defer func() {
+		for i := range g.offscreen {
+			g.offscreen[i] = offscreenDraw{}
+		}
+		g.offscreen = g.offscreen[:0]
+	}()
+	for _, o := range g.offscreen {
+		size := o.img.src.Bounds().Size()
+		tex, err := g.renderTexture(o.img)
+		if err != nil {
+			return err
+		}
+		if g.ctx.Caps().BottomLeftOrigin {
+			// Flip the drawing so the texture content is top-down
+			// like uploaded images.
+			g.drawOps.root = f32.NewAffine2D(1, 0, 0, 0, -1, float32(size.Y))
+		}
+		g.collect(size, o.frame)
+		g.drawOps.root = f32.Affine2D{}
+		g.prepare()
+		g.stencil()
+		g.upload()
+		g.draw(tex, size, driver.LoadDesc{Action: driver.LoadActionClear})
+	}
+	return nil
+}
+
+// renderTexture returns the texture of the image, suitable for drawing
+// into.
+func (g *gpu) renderTexture(img imageOpData) (driver.Texture, error) {
+	var tex *texture
+	if t, exists := g.cache.get(img.handle); exists {
+		tex = t.(*texture)
+	} else {
+		tex = &texture{src: img.src}
+		g.cache.put(img.handle, tex)
+	}
+	if tex.tex != nil && tex.target && tex.filter == img.filter {
+		return tex.tex, nil
+	}
+	tex.release()
+	size := img.src.Bounds().Size()
+	filter := driver.FilterLinear
+	if img.filter == paint.FilterNearest {
+		filter = driver.FilterNearest
+	}
+	t, err := g.ctx.NewTexture(driver.TextureFormatSRGBA, size.X, size.Y, filter, filter, driver.BufferBindingTexture|driver.BufferBindingFramebuffer)
+	if err != nil {
+		tex.tex = nil
+		return nil, err
+	}
+	tex.tex = t
+	tex.target = true
+	tex.filter = img.filter
+	return t, nil
 }
 
 func (g *gpu) collect(viewport image.Point, frameOps *op.Ops) {
@@ -368,6 +848,7 @@ func (g *gpu) collect(viewport image.Point, frameOps *op.Ops) {
 		g.frameStart = time.Now()
 		g.timers = newTimers(g.ctx)
 		g.stencilTimer = g.timers.newTimer()
+		g.uploadTimer = g.timers.newTimer()
 		g.coverTimer = g.timers.newTimer()
 		g.cleanupTimer = g.timers.newTimer()
 	}
@@ -377,20 +858,13 @@ func (g *gpu) frame(target RenderTarget) error {
 	viewport := g.renderer.blitter.viewport
 	defFBO := g.ctx.BeginFrame(target, g.drawOps.clear, viewport)
 	defer g.ctx.EndFrame()
-	g.drawOps.buildPaths(g.ctx)
-	for _, img := range g.drawOps.imageOps {
-		expandPathOp(img.path, img.clip)
-	}
+	g.prepare()
 	g.stencilTimer.begin()
-	g.renderer.packStencils(&g.drawOps.pathOps)
-	g.renderer.stencilClips(g.drawOps.pathCache, g.drawOps.pathOps)
-	g.renderer.packIntersections(g.drawOps.imageOps)
-	g.renderer.prepareIntersections(g.drawOps.imageOps)
-	g.renderer.intersect(g.drawOps.imageOps)
+	g.stencil()
 	g.stencilTimer.end()
-	g.coverTimer.begin()
-	g.renderer.uploadImages(g.cache, g.drawOps.imageOps)
-	g.renderer.prepareDrawOps(g.cache, g.drawOps.imageOps)
+	g.uploadTimer.begin()
+	g.upload()
+	g.uploadTimer.end()
 	d := driver.LoadDesc{
 		ClearColor: g.drawOps.clearColor,
 	}
@@ -398,17 +872,17 @@ func (g *gpu) frame(target RenderTarget) error {
 		g.drawOps.clear = false
 		d.Action = driver.LoadActionClear
 	}
-	g.ctx.BeginRenderPass(defFBO, d)
-	g.ctx.Viewport(0, 0, viewport.X, viewport.Y)
-	g.renderer.drawOps(g.cache, g.drawOps.imageOps)
+	g.coverTimer.begin()
+	g.draw(defFBO, viewport, d)
 	g.coverTimer.end()
-	g.ctx.EndRenderPass()
 	g.cleanupTimer.begin()
 	g.cache.frame()
 	g.drawOps.pathCache.frame()
 	g.cleanupTimer.end()
 	if g.drawOps.profile && g.timers.ready() {
-		st, covt, cleant := g.stencilTimer.Elapsed, g.coverTimer.Elapsed, g.cleanupTimer.Elapsed
+		st, upt, drawt, cleant := g.stencilTimer.Elapsed, g.uploadTimer.Elapsed, g.coverTimer.Elapsed, g.cleanupTimer.Elapsed
+		g.timings = Timings{Tessellate: st, Upload: upt, Draw: drawt, Cleanup: cleant}
+		covt := upt + drawt
 		ft := st + covt + cleant
 		q := 100 * time.Microsecond
 		st, covt = st.Round(q), covt.Round(q)
@@ -419,10 +893,45 @@ func (g *gpu) frame(target RenderTarget) error {
 	return nil
 }
 
+// prepare builds the paths of the collected operations.
+func (g *gpu) prepare() {
+	g.drawOps.buildPaths(g.ctx)
+	for _, img := range g.drawOps.imageOps {
+		expandPathOp(img.path, img.clip)
+	}
+}
+
+// stencil renders the path coverage and clip intersections.
+func (g *gpu) stencil() {
+	g.renderer.packStencils(&g.drawOps.pathOps)
+	g.renderer.stencilClips(g.drawOps.pathCache, g.drawOps.pathOps)
+	g.renderer.packIntersections(g.drawOps.imageOps)
+	g.renderer.prepareIntersections(g.drawOps.imageOps)
+	g.renderer.intersect(g.drawOps.imageOps)
+}
+
+// upload uploads images and prepares the drawing data.
+func (g *gpu) upload() {
+	g.renderer.uploadImages(g.cache, g.drawOps.imageOps)
+	g.renderer.prepareDrawOps(g.cache, g.drawOps.imageOps)
+}
+
+// draw draws the collected operations into fbo.
+func (g *gpu) draw(fbo driver.Texture, viewport image.Point, d driver.LoadDesc) {
+	g.ctx.BeginRenderPass(fbo, d)
+	g.ctx.Viewport(0, 0, viewport.X, viewport.Y)
+	g.renderer.drawOps(g.cache, g.drawOps.imageOps)
+	g.ctx.EndRenderPass()
+}
+
 func (g *gpu) Profile() string {
 	return g.profile
 }
 
+func (g *gpu) TimingBreakdown() Timings {
+	return g.timings
+}
+
 func (r *renderer) texHandle(cache *resourceCache, data imageOpData) driver.Texture {
 	var tex *texture
 	t, exists := cache.get(data.handle)
@@ -433,22 +942,46 @@ func (r *renderer) texHandle(cache *resourceCache, data imageOpData) driver.Text
 		cache.put(data.handle, t)
 	}
 	tex = t.(*texture)
-	if tex.tex != nil {
+	// Drawings keep their filter, because recreating their texture
+	// would lose the drawing.
+	if tex.tex != nil && (tex.target || tex.filter == data.filter) {
 		return tex.tex
 	}
-	handle, err := r.ctx.NewTexture(driver.TextureFormatSRGBA, data.src.Bounds().Dx(), data.src.Bounds().Dy(), driver.FilterLinearMipmapLinear, driver.FilterLinear, driver.BufferBindingTexture)
+	if tex.tex != nil {
+		// Keep a texture for each filter, instead of uploading the
+		// image every time it is drawn with a different filter.
+		if tex.other == nil {
+			tex.other = r.uploadTexture(data)
+		}
+		return tex.other
+	}
+	tex.tex = r.uploadTexture(data)
+	tex.filter = data.filter
+	return tex.tex
+}
+
+// uploadTexture returns a new texture of the image filtered by its filter.
+func (r *renderer) uploadTexture(data imageOpData) driver.Texture {
+	minFilter, magFilter := driver.FilterLinearMipmapLinear, driver.FilterLinear
+	if data.filter == paint.FilterNearest {
+		minFilter, magFilter = driver.FilterNearest, driver.FilterNearest
+	}
+	handle, err := r.ctx.NewTexture(driver.TextureFormatSRGBA, data.src.Bounds().Dx(), data.src.Bounds().Dy(), minFilter, magFilter, driver.BufferBindingTexture)
 	if err != nil {
 		panic(err)
 	}
 	driver.UploadImage(handle, image.Pt(0, 0), data.src)
-	tex.tex = handle
-	return tex.tex
+	return handle
 }
 
 func (t *texture) release() {
 	if t.tex != nil {
 		t.tex.Release()
 	}
+	if t.other != nil {
+		t.other.Release()
+		t.other = nil
+	}
 }
 
 func newRenderer(ctx driver.Device) *renderer {
@@ -846,6 +1379,7 @@ func (d *drawOps) collectOps(r *ops.Reader, viewport f32.Rectangle) {
 	)
 	reset := func() {
 		state = drawState{
+			t:     d.root,
 			color: color.NRGBA{A: 0xff},
 		}
 	}
@@ -1279,12 +1813,22 @@ func (d *drawOps) buildVerts(pathData []byte, tr f32.Affine2D, outline bool, str
 	}
 	d.qs.d = d
 	startLength := len(d.vertCache)
+	// Convert the tolerance to the coordinate space of the path.
+	var tol float32
+	if d.tolerance > 0 {
+		sx, hx, _, hy, sy, _ := tr.Elems()
+		scale := float32(math.Max(math.Hypot(float64(sx), float64(hy)), math.Hypot(float64(hx), float64(sy))))
+		if scale > 0 {
+			tol = d.tolerance / scale
+		}
+	}
 
 	switch {
 	case strWidth > 0:
 		// Stroke path.
 		ss := stroke.StrokeStyle{
-			Width: strWidth,
+			Width:     strWidth,
+			Tolerance: tol,
 		}
 		quads := stroke.StrokePathCommands(ss, pathData)
 		for _, quad := range quads {
@@ -1295,7 +1839,7 @@ func (d *drawOps) buildVerts(pathData []byte, tr f32.Affine2D, outline bool, str
 		}
 
 	case outline:
-		decodeToOutlineQuads(&d.qs, tr, pathData)
+		decodeToOutlineQuads(&d.qs, tr, pathData, tol)
 	}
 
 	fillMaxY(d.vertCache[startLength:])
@@ -1304,7 +1848,7 @@ func (d *drawOps) buildVerts(pathData []byte, tr f32.Affine2D, outline bool, str
 
 // decodeOutlineQuads decodes scene commands, splits them into quadratic béziers
 // as needed and feeds them to the supplied splitter.
-func decodeToOutlineQuads(qs *quadSplitter, tr f32.Affine2D, pathData []byte) {
+func decodeToOutlineQuads(qs *quadSplitter, tr f32.Affine2D, pathData []byte, tolerance float32) {
 	for len(pathData) >= scene.CommandSize+4 {
 		qs.contour = bo.Uint32(pathData)
 		cmd := ops.DecodeCommand(pathData[4:])
@@ -1327,7 +1871,8 @@ func decodeToOutlineQuads(qs *quadSplitter, tr f32.Affine2D, pathData []byte) {
 			q = q.Transform(tr)
 			qs.splitAndEncode(q)
 		case scene.OpCubic:
-			for _, q := range stroke.SplitCubic(scene.DecodeCubic(cmd)) {
+			from, ctrl0, ctrl1, to := scene.DecodeCubic(cmd)
+			for _, q := range stroke.SplitCubic(from, ctrl0, ctrl1, to, tolerance) {
 				q = q.Transform(tr)
 				qs.splitAndEncode(q)
 			}
@@ -8,6 +8,7 @@ import (
 	"image"
 	"math"
 	"math/bits"
+	"runtime"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -47,6 +48,11 @@ type Pipeline struct {
 	topology driver.Topology
 }
 
+type Fence struct {
+	backend *Backend
+	query   *d3d11.Query
+}
+
 type Texture struct {
 	backend      *Backend
 	format       uint32
@@ -200,6 +206,15 @@ func (b *Backend) NewTimer() driver.Timer {
 	panic("timers not supported")
 }
 
+func (b *Backend) NewFence() driver.Fence {
+	q, err := b.dev.CreateQuery(&d3d11.QUERY_DESC{Query: d3d11.QUERY_EVENT})
+	if err != nil {
+		return nil
+	}
+	b.ctx.End(q)
+	return &Fence{backend: b, query: q}
+}
+
 func (b *Backend) IsTimeContinuous() bool {
 	panic("timers not supported")
 }
@@ -758,6 +773,25 @@ func (b *Buffer) Release() {
 }
 
 func (t *Texture) ReadPixels(src image.Rectangle, pixels []byte, stride int) error {
+	s, err := t.StagePixels(src)
+	if err != nil {
+		return fmt.Errorf("ReadPixels: %v", err)
+	}
+	defer s.Release()
+	if err := s.ReadPixels(pixels, stride); err != nil {
+		return fmt.Errorf("ReadPixels: %v", err)
+	}
+	return nil
+}
+
+// staging is a copy of texture pixels in a staging texture.
+type staging struct {
+	backend *Backend
+	tex     *d3d11.Texture2D
+	size    image.Point
+}
+
+func (t *Texture) StagePixels(src image.Rectangle) (driver.Staging, error) {
 	w, h := src.Dx(), src.Dy()
 	tex, err := t.backend.dev.CreateTexture2D(&d3d11.TEXTURE2D_DESC{
 		Width:     uint32(w),
@@ -773,12 +807,10 @@ func (t *Texture) ReadPixels(src image.Rectangle, pixels []byte, stride int) err
 		CPUAccessFlags: d3d11.CPU_ACCESS_READ,
 	})
 	if err != nil {
-		return fmt.Errorf("ReadPixels: %v", err)
+		return nil, err
 	}
-	defer d3d11.IUnknownRelease(unsafe.Pointer(tex), tex.Vtbl.Release)
-	res := (*d3d11.Resource)(unsafe.Pointer(tex))
 	t.backend.ctx.CopySubresourceRegion(
-		res,
+		(*d3d11.Resource)(unsafe.Pointer(tex)),
 		0,       // Destination subresource.
 		0, 0, 0, // Destination coordinates (x, y, z).
 		(*d3d11.Resource)(t.tex),
@@ -792,23 +824,33 @@ func (t *Texture) ReadPixels(src image.Rectangle, pixels []byte, stride int) err
 			Back:   1,
 		},
 	)
-	resMap, err := t.backend.ctx.Map(res, 0, d3d11.MAP_READ, 0)
+	return &staging{backend: t.backend, tex: tex, size: src.Size()}, nil
+}
+
+func (s *staging) ReadPixels(pixels []byte, stride int) error {
+	res := (*d3d11.Resource)(unsafe.Pointer(s.tex))
+	resMap, err := s.backend.ctx.Map(res, 0, d3d11.MAP_READ, 0)
 	if err != nil {
-		return fmt.Errorf("ReadPixels: %v", err)
+		return err
 	}
-	defer t.backend.ctx.Unmap(res, 0)
+	defer s.backend.ctx.Unmap(res, 0)
 	srcPitch := stride
 	dstPitch := int(resMap.RowPitch)
-	mapSize := dstPitch * h
+	mapSize := dstPitch * s.size.Y
 	data := sliceOf(resMap.PData, mapSize)
-	width := w * 4
-	for r := 0; r < h; r++ {
+	width := s.size.X * 4
+	for r := 0; r < s.size.Y; r++ {
 		pixels := pixels[r*srcPitch:]
 		copy(pixels[:width], data[r*dstPitch:])
 	}
 	return nil
 }
 
+func (s *staging) Release() {
+	d3d11.IUnknownRelease(unsafe.Pointer(s.tex), s.tex.Vtbl.Release)
+	*s = staging{}
+}
+
 func (b *Backend) BeginCompute() {
 }
 
@@ -874,3 +916,25 @@ func toBlendFactor(f driver.BlendFactor) (uint32, uint32) {
 func sliceOf(ptr uintptr, cap int) []byte {
 	return unsafe.Slice((*byte)(unsafe.Pointer(ptr)), cap)
 }
+
+func (f *Fence) Wait() {
+	var done uint32
+	for {
+		ok, err := f.backend.ctx.GetData(f.query, unsafe.Pointer(&done), uint32(unsafe.Sizeof(done)), 0)
+		if ok || err != nil {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func (f *Fence) Done() bool {
+	var done uint32
+	ok, err := f.backend.ctx.GetData(f.query, unsafe.Pointer(&done), uint32(unsafe.Sizeof(done)), 0)
+	return ok || err != nil
+}
+
+func (f *Fence) Release() {
+	d3d11.IUnknownRelease(unsafe.Pointer(f.query), f.query.Vtbl.Release)
+	f.query = nil
+}
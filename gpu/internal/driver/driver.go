@@ -19,6 +19,9 @@ type Device interface {
 	EndFrame()
 	Caps() Caps
 	NewTimer() Timer
+	// NewFence inserts a fence after the commands submitted so far. It
+	// returns nil if fences are not supported.
+	NewFence() Fence
 	// IsContinuousTime reports whether all timer measurements
 	// are valid at the point of call.
 	IsTimeContinuous() bool
@@ -138,6 +141,17 @@ type Timer interface {
 	Release()
 }
 
+// Fence is a point in the stream of submitted commands.
+type Fence interface {
+	// Wait blocks until the GPU has completed the commands submitted
+	// before the fence.
+	Wait()
+	// Done reports whether the GPU has completed the commands submitted
+	// before the fence, without blocking.
+	Done() bool
+	Release()
+}
+
 type Texture interface {
 	RenderTarget
 	Upload(offset, size image.Point, pixels []byte, stride int)
@@ -145,6 +159,23 @@ type Texture interface {
 	Release()
 }
 
+// StagingTexture is implemented by textures that can copy their pixels
+// to memory readable by the CPU without waiting for the GPU.
+type StagingTexture interface {
+	// StagePixels starts a copy of the pixels of src. The copy can be
+	// read without waiting once a fence created after StagePixels
+	// completes.
+	StagePixels(src image.Rectangle) (Staging, error)
+}
+
+// Staging is a copy of texture pixels started by StagePixels.
+type Staging interface {
+	// ReadPixels copies the pixels into pixels, with rows stride bytes
+	// apart.
+	ReadPixels(pixels []byte, stride int) error
+	Release()
+}
+
 const (
 	BufferBindingIndices BufferBinding = 1 << iota
 	BufferBindingVertices
@@ -213,6 +244,19 @@ func DownloadImage(d Device, t Texture, img *image.RGBA) error {
 	return nil
 }
 
+// ReadStaging is like DownloadImage, but reads the pixels of a staging
+// copy.
+func ReadStaging(d Device, s Staging, img *image.RGBA) error {
+	r := img.Bounds()
+	if err := s.ReadPixels(img.Pix, img.Stride); err != nil {
+		return err
+	}
+	if d.Caps().BottomLeftOrigin {
+		flipImageY(r.Dx()*4, r.Dy(), img.Pix)
+	}
+	return nil
+}
+
 func flipImageY(stride, height int, pixels []byte) {
 	// Flip image in y-direction. OpenGL's origin is in the lower
 	// left corner.
@@ -560,6 +560,11 @@ func (b *Backend) NewTimer() driver.Timer {
 	panic("timers not supported")
 }
 
+// NewFence returns nil, because BeginFrame waits for the previous frame.
+func (b *Backend) NewFence() driver.Fence {
+	return nil
+}
+
 func (b *Backend) IsTimeContinuous() bool {
 	panic("timers not supported")
 }
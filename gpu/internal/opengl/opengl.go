@@ -29,6 +29,8 @@ type Backend struct {
 
 	glver [2]int
 	gles  bool
+	// pbo is set if pixels can be read into buffers and mapped.
+	pbo   bool
 	feats driver.Caps
 	// floatTriple holds the settings for floating point
 	// textures.
@@ -70,6 +72,7 @@ type glState struct {
 	uniBufs   [2]gl.Buffer
 	storeBuf  gl.Buffer
 	storeBufs [4]gl.Buffer
+	packBuf   gl.Buffer
 	vertArray gl.VertexArray
 	srgb      bool
 	blend     struct {
@@ -98,6 +101,11 @@ type timer struct {
 	obj   gl.Query
 }
 
+type fence struct {
+	funcs *gl.Functions
+	obj   gl.Sync
+}
+
 type texture struct {
 	backend  *Backend
 	obj      gl.Texture
@@ -201,6 +209,8 @@ func newOpenGLDevice(api driver.OpenGL) (driver.Device, error) {
 		srgbaTriple: srgbaTriple,
 		sharedCtx:   api.Shared,
 	}
+	// MapBufferRange is not implemented for WebGL nor on Windows.
+	b.pbo = ver[0] >= 3 && runtime.GOOS != "js" && runtime.GOOS != "windows"
 	b.feats.BottomLeftOrigin = true
 	if srgbErr == nil {
 		b.feats.Features |= driver.FeatureSRGB
@@ -467,6 +477,9 @@ func (s *glState) deleteBuffer(f *gl.Functions, b gl.Buffer) {
 	if b.Equal(s.storeBuf) {
 		s.uniBuf = gl.Buffer{}
 	}
+	if b.Equal(s.packBuf) {
+		s.packBuf = gl.Buffer{}
+	}
 	for i, b2 := range s.storeBufs {
 		if b.Equal(b2) {
 			s.storeBufs[i] = gl.Buffer{}
@@ -576,6 +589,11 @@ func (s *glState) bindBuffer(f *gl.Functions, target gl.Enum, buf gl.Buffer) {
 			return
 		}
 		s.storeBuf = buf
+	case gl.PIXEL_PACK_BUFFER:
+		if buf.Equal(s.packBuf) {
+			return
+		}
+		s.packBuf = buf
 	default:
 		panic("unknown buffer target")
 	}
@@ -659,6 +677,14 @@ func (b *Backend) NewTimer() driver.Timer {
 	}
 }
 
+func (b *Backend) NewFence() driver.Fence {
+	s := b.funcs.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	if !s.Valid() {
+		return nil
+	}
+	return &fence{funcs: b.funcs, obj: s}
+}
+
 func (b *Backend) IsTimeContinuous() bool {
 	return b.funcs.GetInteger(gl.GPU_DISJOINT_EXT) == gl.FALSE
 }
@@ -1182,6 +1208,59 @@ func (t *texture) ReadPixels(src image.Rectangle, pixels []byte, stride int) err
 	return glErr(t.backend.funcs)
 }
 
+// staging is a copy of texture pixels in a pixel pack buffer.
+type staging struct {
+	backend *Backend
+	obj     gl.Buffer
+	size    image.Point
+}
+
+func (t *texture) StagePixels(src image.Rectangle) (driver.Staging, error) {
+	b := t.backend
+	if !b.pbo {
+		return nil, errors.New("pixel pack buffers not supported")
+	}
+	glErr(b.funcs)
+	w, h := src.Dx(), src.Dy()
+	obj := b.funcs.CreateBuffer()
+	b.glstate.bindBuffer(b.funcs, gl.PIXEL_PACK_BUFFER, obj)
+	b.funcs.BufferData(gl.PIXEL_PACK_BUFFER, w*h*4, gl.STREAM_READ, nil)
+	b.glstate.bindFramebuffer(b.funcs, gl.FRAMEBUFFER, t.ensureFBO())
+	b.glstate.pixelStorei(b.funcs, gl.PACK_ROW_LENGTH, 0)
+	// With a pack buffer bound, ReadPixels copies into the buffer
+	// and returns without waiting for the GPU.
+	b.funcs.ReadPixels(src.Min.X, src.Min.Y, w, h, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	b.glstate.bindBuffer(b.funcs, gl.PIXEL_PACK_BUFFER, gl.Buffer{})
+	if err := glErr(b.funcs); err != nil {
+		b.glstate.deleteBuffer(b.funcs, obj)
+		return nil, err
+	}
+	return &staging{backend: b, obj: obj, size: src.Size()}, nil
+}
+
+func (s *staging) ReadPixels(pixels []byte, stride int) error {
+	b := s.backend
+	w, h := s.size.X, s.size.Y
+	b.glstate.bindBuffer(b.funcs, gl.PIXEL_PACK_BUFFER, s.obj)
+	defer b.glstate.bindBuffer(b.funcs, gl.PIXEL_PACK_BUFFER, gl.Buffer{})
+	data := b.funcs.MapBufferRange(gl.PIXEL_PACK_BUFFER, 0, w*h*4, gl.MAP_READ_BIT)
+	if data == nil {
+		return fmt.Errorf("MapBufferRange: error %#x", b.funcs.GetError())
+	}
+	for y := 0; y < h; y++ {
+		copy(pixels[y*stride:y*stride+w*4], data[y*w*4:])
+	}
+	if !b.funcs.UnmapBuffer(gl.PIXEL_PACK_BUFFER) {
+		return driver.ErrContentLost
+	}
+	return nil
+}
+
+func (s *staging) Release() {
+	s.backend.glstate.deleteBuffer(s.backend.funcs, s.obj)
+	*s = staging{}
+}
+
 func (b *Backend) BindPipeline(pl driver.Pipeline) {
 	p := pl.(*pipeline)
 	b.state.pipeline = p
@@ -1281,6 +1360,21 @@ func (t *timer) Release() {
 	t.funcs.DeleteQuery(t.obj)
 }
 
+func (f *fence) Wait() {
+	const timeout = uint64(time.Second)
+	for f.funcs.ClientWaitSync(f.obj, gl.SYNC_FLUSH_COMMANDS_BIT, timeout) == gl.TIMEOUT_EXPIRED {
+	}
+}
+
+func (f *fence) Done() bool {
+	// Report failures as completed to avoid waiting forever.
+	return f.funcs.ClientWaitSync(f.obj, gl.SYNC_FLUSH_COMMANDS_BIT, 0) != gl.TIMEOUT_EXPIRED
+}
+
+func (f *fence) Release() {
+	f.funcs.DeleteSync(f.obj)
+}
+
 func (t *timer) Duration() (time.Duration, bool) {
 	if !t.ready() {
 		return 0, false
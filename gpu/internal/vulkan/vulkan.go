@@ -259,6 +259,12 @@ func (b *Backend) NewTimer() driver.Timer {
 	panic("timers not supported")
 }
 
+// NewFence returns nil, because the frames in flight are bounded by the
+// fences of the swapchain images.
+func (b *Backend) NewFence() driver.Fence {
+	return nil
+}
+
 func (b *Backend) IsTimeContinuous() bool {
 	panic("timers not supported")
 }
@@ -24,6 +24,31 @@ type DXGI_SWAP_CHAIN_DESC struct {
 	Flags        uint32
 }
 
+type DXGI_SWAP_CHAIN_DESC1 struct {
+	Width       uint32
+	Height      uint32
+	Format      uint32
+	Stereo      uint32
+	SampleDesc  DXGI_SAMPLE_DESC
+	BufferUsage uint32
+	BufferCount uint32
+	Scaling     uint32
+	SwapEffect  uint32
+	AlphaMode   uint32
+	Flags       uint32
+}
+
+type RENDER_TARGET_VIEW_DESC struct {
+	Format        uint32
+	ViewDimension uint32
+	// Texture2D is the MipSlice of the TEX2D_RTV union member; the
+	// padding covers the largest member.
+	Texture2D struct {
+		MipSlice uint32
+		_        [2]uint32
+	}
+}
+
 type DXGI_SAMPLE_DESC struct {
 	Count   uint32
 	Quality uint32
@@ -69,6 +94,11 @@ type SAMPLER_DESC struct {
 	MaxLOD         float32
 }
 
+type QUERY_DESC struct {
+	Query     uint32
+	MiscFlags uint32
+}
+
 type SHADER_RESOURCE_VIEW_DESC_TEX2D struct {
 	SHADER_RESOURCE_VIEW_DESC
 	Texture2D TEX2D_SRV
@@ -360,6 +390,12 @@ type SamplerState struct {
 	}
 }
 
+type Query struct {
+	Vtbl *struct {
+		_IUnknownVTbl
+	}
+}
+
 type PixelShader struct {
 	Vtbl *struct {
 		_IUnknownVTbl
@@ -507,6 +543,80 @@ type IDXGIFactory struct {
 	}
 }
 
+type IDXGIFactory2 struct {
+	Vtbl *struct {
+		_IUnknownVTbl
+		SetPrivateData                uintptr
+		SetPrivateDataInterface       uintptr
+		GetPrivateData                uintptr
+		GetParent                     uintptr
+		EnumAdapters                  uintptr
+		MakeWindowAssociation         uintptr
+		GetWindowAssociation          uintptr
+		CreateSwapChain               uintptr
+		CreateSoftwareAdapter         uintptr
+		EnumAdapters1                 uintptr
+		IsCurrent                     uintptr
+		IsWindowedStereoEnabled       uintptr
+		CreateSwapChainForHwnd        uintptr
+		CreateSwapChainForCoreWindow  uintptr
+		GetSharedResourceAdapterLuid  uintptr
+		RegisterStereoStatusWindow    uintptr
+		RegisterStereoStatusEvent     uintptr
+		UnregisterStereoStatus        uintptr
+		RegisterOcclusionStatusWindow uintptr
+		RegisterOcclusionStatusEvent  uintptr
+		UnregisterOcclusionStatus     uintptr
+		CreateSwapChainForComposition uintptr
+	}
+}
+
+type IDCompositionDevice struct {
+	Vtbl *struct {
+		_IUnknownVTbl
+		Commit                  uintptr
+		WaitForCommitCompletion uintptr
+		GetFrameStatistics      uintptr
+		CreateTargetForHwnd     uintptr
+		CreateVisual            uintptr
+	}
+}
+
+type IDCompositionTarget struct {
+	Vtbl *struct {
+		_IUnknownVTbl
+		SetRoot uintptr
+	}
+}
+
+type IDCompositionVisual struct {
+	Vtbl *struct {
+		_IUnknownVTbl
+		// The overloaded methods are in reverse order of declaration.
+		SetOffsetXFloat            uintptr
+		SetOffsetXAnimation        uintptr
+		SetOffsetYFloat            uintptr
+		SetOffsetYAnimation        uintptr
+		SetTransformMatrix         uintptr
+		SetTransform               uintptr
+		SetTransformParent         uintptr
+		SetEffect                  uintptr
+		SetBitmapInterpolationMode uintptr
+		SetBorderMode              uintptr
+		SetClipRect                uintptr
+		SetClip                    uintptr
+		SetContent                 uintptr
+	}
+}
+
+// Composition is the DirectComposition tree that displays a swap chain
+// created by CreateCompositionSwapChain.
+type Composition struct {
+	dev    *IDCompositionDevice
+	target *IDCompositionTarget
+	visual *IDCompositionVisual
+}
+
 type IDXGIDebug struct {
 	Vtbl *struct {
 		_IUnknownVTbl
@@ -611,11 +721,13 @@ type RASTERIZER_DESC struct {
 }
 
 var (
-	IID_Texture2D    = GUID{0x6f15aaf2, 0xd208, 0x4e89, 0x9a, 0xb4, 0x48, 0x95, 0x35, 0xd3, 0x4f, 0x9c}
-	IID_IDXGIDebug   = GUID{0x119E7452, 0xDE9E, 0x40fe, 0x88, 0x06, 0x88, 0xF9, 0x0C, 0x12, 0xB4, 0x41}
-	IID_IDXGIDevice  = GUID{0x54ec77fa, 0x1377, 0x44e6, 0x8c, 0x32, 0x88, 0xfd, 0x5f, 0x44, 0xc8, 0x4c}
-	IID_IDXGIFactory = GUID{0x7b7166ec, 0x21c7, 0x44ae, 0xb2, 0x1a, 0xc9, 0xae, 0x32, 0x1a, 0xe3, 0x69}
-	IID_ID3D11Debug  = GUID{0x79cf2233, 0x7536, 0x4948, 0x9d, 0x36, 0x1e, 0x46, 0x92, 0xdc, 0x57, 0x60}
+	IID_Texture2D           = GUID{0x6f15aaf2, 0xd208, 0x4e89, 0x9a, 0xb4, 0x48, 0x95, 0x35, 0xd3, 0x4f, 0x9c}
+	IID_IDXGIDebug          = GUID{0x119E7452, 0xDE9E, 0x40fe, 0x88, 0x06, 0x88, 0xF9, 0x0C, 0x12, 0xB4, 0x41}
+	IID_IDXGIDevice         = GUID{0x54ec77fa, 0x1377, 0x44e6, 0x8c, 0x32, 0x88, 0xfd, 0x5f, 0x44, 0xc8, 0x4c}
+	IID_IDXGIFactory        = GUID{0x7b7166ec, 0x21c7, 0x44ae, 0xb2, 0x1a, 0xc9, 0xae, 0x32, 0x1a, 0xe3, 0x69}
+	IID_IDXGIFactory2       = GUID{0x50c83a1c, 0xe072, 0x4c48, 0x87, 0xb0, 0x36, 0x30, 0xfa, 0x36, 0xa6, 0xd0}
+	IID_IDCompositionDevice = GUID{0xc37ea93a, 0xe7aa, 0x450d, 0xb1, 0x6f, 0x97, 0x46, 0xcb, 0x04, 0x07, 0xf3}
+	IID_ID3D11Debug         = GUID{0x79cf2233, 0x7536, 0x4948, 0x9d, 0x36, 0x1e, 0x46, 0x92, 0xdc, 0x57, 0x60}
 
 	DXGI_DEBUG_ALL = GUID{0xe48ae283, 0xda80, 0x490b, 0x87, 0xe6, 0x43, 0xe9, 0xa9, 0xcf, 0xda, 0x8}
 )
@@ -629,6 +741,10 @@ var (
 	dxgi = windows.NewLazySystemDLL("dxgi.dll")
 
 	_DXGIGetDebugInterface1 = dxgi.NewProc("DXGIGetDebugInterface1")
+
+	dcomp = windows.NewLazySystemDLL("dcomp.dll")
+
+	_DCompositionCreateDevice = dcomp.NewProc("DCompositionCreateDevice")
 )
 
 const (
@@ -664,7 +780,18 @@ const (
 
 	MAP_READ = 1
 
-	DXGI_SWAP_EFFECT_DISCARD = 0
+	QUERY_EVENT = 0
+
+	ASYNC_GETDATA_DONOTFLUSH = 0x1
+
+	DXGI_SWAP_EFFECT_DISCARD         = 0
+	DXGI_SWAP_EFFECT_FLIP_SEQUENTIAL = 3
+
+	DXGI_SCALING_STRETCH = 0
+
+	DXGI_ALPHA_MODE_PREMULTIPLIED = 1
+
+	RTV_DIMENSION_TEXTURE2D = 4
 
 	FEATURE_LEVEL_9_1  = 0x9100
 	FEATURE_LEVEL_9_3  = 0x9300
@@ -704,7 +831,8 @@ const (
 	RESOURCE_MISC_BUFFER_ALLOW_RAW_VIEWS = 0x20
 	RESOURCE_MISC_GENERATE_MIPS          = 0x1
 
-	CREATE_DEVICE_DEBUG = 0x2
+	CREATE_DEVICE_DEBUG        = 0x2
+	CREATE_DEVICE_BGRA_SUPPORT = 0x20
 
 	FILL_SOLID = 3
 
@@ -1021,6 +1149,21 @@ func (d *Device) CreateSamplerState(desc *SAMPLER_DESC) (*SamplerState, error) {
 	return sampler, nil
 }
 
+func (d *Device) CreateQuery(desc *QUERY_DESC) (*Query, error) {
+	var query *Query
+	r, _, _ := syscall.Syscall(
+		d.Vtbl.CreateQuery,
+		3,
+		uintptr(unsafe.Pointer(d)),
+		uintptr(unsafe.Pointer(desc)),
+		uintptr(unsafe.Pointer(&query)),
+	)
+	if r != 0 {
+		return nil, ErrorCode{Name: "DeviceCreateQuery", Code: uint32(r)}
+	}
+	return query, nil
+}
+
 func (d *Device) CreateTexture2D(desc *TEXTURE2D_DESC) (*Texture2D, error) {
 	var tex *Texture2D
 	r, _, _ := syscall.Syscall6(
@@ -1055,6 +1198,29 @@ func (d *Device) CreateRenderTargetView(res *Resource) (*RenderTargetView, error
 	return target, nil
 }
 
+// CreateRenderTargetViewFormat is like CreateRenderTargetView but views
+// the resource as a 2D texture of the format.
+func (d *Device) CreateRenderTargetViewFormat(res *Resource, format uint32) (*RenderTargetView, error) {
+	desc := RENDER_TARGET_VIEW_DESC{
+		Format:        format,
+		ViewDimension: RTV_DIMENSION_TEXTURE2D,
+	}
+	var target *RenderTargetView
+	r, _, _ := syscall.Syscall6(
+		d.Vtbl.CreateRenderTargetView,
+		4,
+		uintptr(unsafe.Pointer(d)),
+		uintptr(unsafe.Pointer(res)),
+		uintptr(unsafe.Pointer(&desc)),
+		uintptr(unsafe.Pointer(&target)),
+		0, 0,
+	)
+	if r != 0 {
+		return nil, ErrorCode{Name: "DeviceCreateRenderTargetView", Code: uint32(r)}
+	}
+	return target, nil
+}
+
 func (d *Device) CreateBlendState(desc *BLEND_DESC) (*BlendState, error) {
 	var state *BlendState
 	r, _, _ := syscall.Syscall(
@@ -1202,6 +1368,39 @@ func (c *DeviceContext) GenerateMips(res *ShaderResourceView) {
 	)
 }
 
+func (c *DeviceContext) End(query *Query) {
+	syscall.Syscall(
+		c.Vtbl.End,
+		2,
+		uintptr(unsafe.Pointer(c)),
+		uintptr(unsafe.Pointer(query)),
+		0,
+	)
+}
+
+// GetData reports whether the data of query is available, and copies it
+// to data if so.
+func (c *DeviceContext) GetData(query *Query, data unsafe.Pointer, size, flags uint32) (bool, error) {
+	r, _, _ := syscall.Syscall6(
+		c.Vtbl.GetData,
+		5,
+		uintptr(unsafe.Pointer(c)),
+		uintptr(unsafe.Pointer(query)),
+		uintptr(data),
+		uintptr(size),
+		uintptr(flags),
+		0,
+	)
+	switch r {
+	case 0: // S_OK
+		return true, nil
+	case 1: // S_FALSE
+		return false, nil
+	default:
+		return false, ErrorCode{Name: "DeviceContextGetData", Code: uint32(r)}
+	}
+}
+
 func (c *DeviceContext) Unmap(resource *Resource, subResource uint32) {
 	syscall.Syscall(
 		c.Vtbl.Unmap,
@@ -1628,18 +1827,28 @@ func (e ErrorCode) Error() string {
 	return fmt.Sprintf("%s: %#x", e.Name, e.Code)
 }
 
-func CreateSwapChain(dev *Device, hwnd windows.Handle) (*IDXGISwapChain, error) {
+// dxgiFactory returns the factory of the adapter of dev, as the
+// interface iid.
+func dxgiFactory(dev *Device, iid *GUID) (*IDXGIObject, error) {
 	dxgiDev, err := IUnknownQueryInterface(unsafe.Pointer(dev), dev.Vtbl.QueryInterface, &IID_IDXGIDevice)
 	if err != nil {
-		return nil, fmt.Errorf("NewContext: %v", err)
+		return nil, err
 	}
 	adapter, err := (*IDXGIDevice)(unsafe.Pointer(dxgiDev)).GetAdapter()
 	IUnknownRelease(unsafe.Pointer(dxgiDev), dxgiDev.Vtbl.Release)
 	if err != nil {
-		return nil, fmt.Errorf("NewContext: %v", err)
+		return nil, err
 	}
-	dxgiFactory, err := (*IDXGIObject)(unsafe.Pointer(adapter)).GetParent(&IID_IDXGIFactory)
+	factory, err := (*IDXGIObject)(unsafe.Pointer(adapter)).GetParent(iid)
 	IUnknownRelease(unsafe.Pointer(adapter), adapter.Vtbl.Release)
+	if err != nil {
+		return nil, err
+	}
+	return factory, nil
+}
+
+func CreateSwapChain(dev *Device, hwnd windows.Handle) (*IDXGISwapChain, error) {
+	dxgiFactory, err := dxgiFactory(dev, &IID_IDXGIFactory)
 	if err != nil {
 		return nil, fmt.Errorf("NewContext: %v", err)
 	}
@@ -1666,6 +1875,144 @@ func CreateSwapChain(dev *Device, hwnd windows.Handle) (*IDXGISwapChain, error)
 	return swchain, nil
 }
 
+// CreateCompositionSwapChain creates a swap chain with premultiplied
+// alpha, displayed in hwnd through DirectComposition, so the content
+// blends with what is behind the window. Its buffers are in the
+// DXGI_FORMAT_R8G8B8A8_UNORM format, because flip model swap chains
+// don't support sRGB buffer formats; render to them through sRGB views.
+func CreateCompositionSwapChain(dev *Device, hwnd windows.Handle, width, height int) (*IDXGISwapChain, *Composition, error) {
+	if err := _DCompositionCreateDevice.Find(); err != nil {
+		return nil, nil, err
+	}
+	factory, err := dxgiFactory(dev, &IID_IDXGIFactory2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewContext: %v", err)
+	}
+	var swchain *IDXGISwapChain
+	desc := DXGI_SWAP_CHAIN_DESC1{
+		Width:  uint32(width),
+		Height: uint32(height),
+		Format: DXGI_FORMAT_R8G8B8A8_UNORM,
+		SampleDesc: DXGI_SAMPLE_DESC{
+			Count: 1,
+		},
+		BufferUsage: DXGI_USAGE_RENDER_TARGET_OUTPUT,
+		BufferCount: 2,
+		Scaling:     DXGI_SCALING_STRETCH,
+		SwapEffect:  DXGI_SWAP_EFFECT_FLIP_SEQUENTIAL,
+		AlphaMode:   DXGI_ALPHA_MODE_PREMULTIPLIED,
+	}
+	r, _, _ := syscall.Syscall6(
+		(*IDXGIFactory2)(unsafe.Pointer(factory)).Vtbl.CreateSwapChainForComposition,
+		4,
+		uintptr(unsafe.Pointer(factory)),
+		uintptr(unsafe.Pointer(dev)),
+		uintptr(unsafe.Pointer(&desc)),
+		0, // pRestrictToOutput
+		uintptr(unsafe.Pointer(&swchain)),
+		0,
+	)
+	IUnknownRelease(unsafe.Pointer(factory), factory.Vtbl.Release)
+	if r != 0 {
+		return nil, nil, ErrorCode{Name: "IDXGIFactory2CreateSwapChainForComposition", Code: uint32(r)}
+	}
+	comp, err := newComposition(dev, hwnd, swchain)
+	if err != nil {
+		IUnknownRelease(unsafe.Pointer(swchain), swchain.Vtbl.Release)
+		return nil, nil, err
+	}
+	return swchain, comp, nil
+}
+
+// newComposition creates a composition tree that displays swchain in
+// hwnd.
+func newComposition(dev *Device, hwnd windows.Handle, swchain *IDXGISwapChain) (*Composition, error) {
+	dxgiDev, err := IUnknownQueryInterface(unsafe.Pointer(dev), dev.Vtbl.QueryInterface, &IID_IDXGIDevice)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Composition)
+	r, _, _ := _DCompositionCreateDevice.Call(
+		uintptr(unsafe.Pointer(dxgiDev)),
+		uintptr(unsafe.Pointer(&IID_IDCompositionDevice)),
+		uintptr(unsafe.Pointer(&c.dev)),
+	)
+	IUnknownRelease(unsafe.Pointer(dxgiDev), dxgiDev.Vtbl.Release)
+	if r != 0 {
+		return nil, ErrorCode{Name: "DCompositionCreateDevice", Code: uint32(r)}
+	}
+	r, _, _ = syscall.Syscall6(
+		c.dev.Vtbl.CreateTargetForHwnd,
+		4,
+		uintptr(unsafe.Pointer(c.dev)),
+		uintptr(hwnd),
+		1, // topmost
+		uintptr(unsafe.Pointer(&c.target)),
+		0, 0,
+	)
+	if r != 0 {
+		c.Release()
+		return nil, ErrorCode{Name: "IDCompositionDeviceCreateTargetForHwnd", Code: uint32(r)}
+	}
+	r, _, _ = syscall.Syscall(
+		c.dev.Vtbl.CreateVisual,
+		2,
+		uintptr(unsafe.Pointer(c.dev)),
+		uintptr(unsafe.Pointer(&c.visual)),
+		0,
+	)
+	if r != 0 {
+		c.Release()
+		return nil, ErrorCode{Name: "IDCompositionDeviceCreateVisual", Code: uint32(r)}
+	}
+	r, _, _ = syscall.Syscall(
+		c.visual.Vtbl.SetContent,
+		2,
+		uintptr(unsafe.Pointer(c.visual)),
+		uintptr(unsafe.Pointer(swchain)),
+		0,
+	)
+	if r != 0 {
+		c.Release()
+		return nil, ErrorCode{Name: "IDCompositionVisualSetContent", Code: uint32(r)}
+	}
+	r, _, _ = syscall.Syscall(
+		c.target.Vtbl.SetRoot,
+		2,
+		uintptr(unsafe.Pointer(c.target)),
+		uintptr(unsafe.Pointer(c.visual)),
+		0,
+	)
+	if r != 0 {
+		c.Release()
+		return nil, ErrorCode{Name: "IDCompositionTargetSetRoot", Code: uint32(r)}
+	}
+	r, _, _ = syscall.Syscall(
+		c.dev.Vtbl.Commit,
+		1,
+		uintptr(unsafe.Pointer(c.dev)),
+		0, 0,
+	)
+	if r != 0 {
+		c.Release()
+		return nil, ErrorCode{Name: "IDCompositionDeviceCommit", Code: uint32(r)}
+	}
+	return c, nil
+}
+
+func (c *Composition) Release() {
+	if c.visual != nil {
+		IUnknownRelease(unsafe.Pointer(c.visual), c.visual.Vtbl.Release)
+	}
+	if c.target != nil {
+		IUnknownRelease(unsafe.Pointer(c.target), c.target.Vtbl.Release)
+	}
+	if c.dev != nil {
+		IUnknownRelease(unsafe.Pointer(c.dev), c.dev.Vtbl.Release)
+	}
+	*c = Composition{}
+}
+
 func CreateDepthView(d *Device, width, height, depthBits int) (*DepthStencilView, error) {
 	depthTex, err := d.CreateTexture2D(&TEXTURE2D_DESC{
 		Width:     uint32(width),
@@ -27,6 +27,8 @@ type eglContext struct {
 	visualID    int
 	srgb        bool
 	surfaceless bool
+	// major and minor are the requested version of the context.
+	major, minor int
 }
 
 var (
@@ -42,6 +44,7 @@ const (
 	_EGL_BLUE_SIZE              = 0x3022
 	_EGL_CONFIG_CAVEAT          = 0x3027
 	_EGL_CONTEXT_CLIENT_VERSION = 0x3098
+	_EGL_CONTEXT_MINOR_VERSION  = 0x30fb
 	_EGL_DEPTH_SIZE             = 0x3025
 	_EGL_GL_COLORSPACE_KHR      = 0x309d
 	_EGL_GL_COLORSPACE_SRGB_KHR = 0x3089
@@ -72,7 +75,21 @@ func (c *Context) Present() error {
 	return nil
 }
 
+// Version returns the OpenGL ES version the context was created with.
+// The implementation may provide a later, compatible version.
+func (c *Context) Version() (major, minor int) {
+	return c.eglCtx.major, c.eglCtx.minor
+}
+
 func NewContext(disp NativeDisplayType) (*Context, error) {
+	return NewContextVersion(disp, 0, 0)
+}
+
+// NewContextVersion is like NewContext, but attempts to create an
+// OpenGL ES context of the major and minor version. If major is zero or
+// the version can't be created, NewContextVersion falls back to the
+// default version.
+func NewContextVersion(disp NativeDisplayType, major, minor int) (*Context, error) {
 	if err := loadEGL(); err != nil {
 		return nil, err
 	}
@@ -86,7 +103,7 @@ func NewContext(disp NativeDisplayType) (*Context, error) {
 	if eglDisp == nilEGLDisplay {
 		return nil, fmt.Errorf("eglGetDisplay failed: 0x%x", eglGetError())
 	}
-	eglCtx, err := createContext(eglDisp)
+	eglCtx, err := createContext(eglDisp, major, minor)
 	if err != nil {
 		return nil, err
 	}
@@ -147,12 +164,13 @@ func (c *Context) MakeCurrent() error {
 	return nil
 }
 
-func (c *Context) EnableVSync(enable bool) {
+// EnableVSync sets the swap interval of the current surface, and
+// reports whether the interval was set.
+func (c *Context) EnableVSync(enable bool) bool {
 	if enable {
-		eglSwapInterval(c.disp, 1)
-	} else {
-		eglSwapInterval(c.disp, 0)
+		return eglSwapInterval(c.disp, 1)
 	}
+	return eglSwapInterval(c.disp, 0)
 }
 
 func hasExtension(exts []string, ext string) bool {
@@ -164,7 +182,7 @@ func hasExtension(exts []string, ext string) bool {
 	return false
 }
 
-func createContext(disp _EGLDisplay) (*eglContext, error) {
+func createContext(disp _EGLDisplay, reqMajor, reqMinor int) (*eglContext, error) {
 	major, minor, ret := eglInitialize(disp)
 	if !ret {
 		return nil, fmt.Errorf("eglInitialize failed: 0x%x", eglGetError())
@@ -208,11 +226,27 @@ func createContext(disp _EGLDisplay) (*eglContext, error) {
 			return nil, errors.New("newContext: eglGetConfigAttrib for _EGL_NATIVE_VISUAL_ID failed")
 		}
 	}
-	ctxAttribs := []_EGLint{
-		_EGL_CONTEXT_CLIENT_VERSION, 3,
-		_EGL_NONE,
+	eglCtx := nilEGLContext
+	ctxMajor, ctxMinor := 0, 0
+	if reqMajor > 0 {
+		ctxAttribs := []_EGLint{_EGL_CONTEXT_CLIENT_VERSION, _EGLint(reqMajor)}
+		ctxMajor = reqMajor
+		// Minor versions require EGL 1.5 or EGL_KHR_create_context.
+		if reqMinor > 0 && (major > 1 || minor >= 5 || hasExtension(exts, "EGL_KHR_create_context")) {
+			ctxAttribs = append(ctxAttribs, _EGL_CONTEXT_MINOR_VERSION, _EGLint(reqMinor))
+			ctxMinor = reqMinor
+		}
+		ctxAttribs = append(ctxAttribs, _EGL_NONE)
+		eglCtx = eglCreateContext(disp, eglCfg, nilEGLContext, ctxAttribs)
+	}
+	if eglCtx == nilEGLContext {
+		ctxAttribs := []_EGLint{
+			_EGL_CONTEXT_CLIENT_VERSION, 3,
+			_EGL_NONE,
+		}
+		eglCtx = eglCreateContext(disp, eglCfg, nilEGLContext, ctxAttribs)
+		ctxMajor, ctxMinor = 3, 0
 	}
-	eglCtx := eglCreateContext(disp, eglCfg, nilEGLContext, ctxAttribs)
 	if eglCtx == nilEGLContext {
 		// Fall back to OpenGL ES 2 and rely on extensions.
 		ctxAttribs := []_EGLint{
@@ -223,6 +257,7 @@ func createContext(disp _EGLDisplay) (*eglContext, error) {
 		if eglCtx == nilEGLContext {
 			return nil, fmt.Errorf("eglCreateContext failed: 0x%x", eglGetError())
 		}
+		ctxMajor, ctxMinor = 2, 0
 	}
 	return &eglContext{
 		config:      _EGLConfig(eglCfg),
@@ -230,6 +265,8 @@ func createContext(disp _EGLDisplay) (*eglContext, error) {
 		visualID:    int(visID),
 		srgb:        srgb,
 		surfaceless: hasExtension(exts, "EGL_KHR_surfaceless_context"),
+		major:       ctxMajor,
+		minor:       ctxMinor,
 	}, nil
 }
 
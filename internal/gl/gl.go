@@ -67,6 +67,7 @@ const (
 	ONE                                   = 0x1
 	ONE_MINUS_SRC_ALPHA                   = 0x303
 	PACK_ROW_LENGTH                       = 0x0D02
+	PIXEL_PACK_BUFFER                     = 0x88EB
 	PROGRAM_BINARY_LENGTH                 = 0x8741
 	QUERY_RESULT                          = 0x8866
 	QUERY_RESULT_AVAILABLE                = 0x8867
@@ -92,7 +93,10 @@ const (
 	SRGB_ALPHA_EXT                        = 0x8c42
 	SRGB8                                 = 0x8c41
 	SRGB8_ALPHA8                          = 0x8c43
+	SYNC_FLUSH_COMMANDS_BIT               = 0x00000001
+	SYNC_GPU_COMMANDS_COMPLETE            = 0x9117
 	STATIC_DRAW                           = 0x88e4
+	STREAM_READ                           = 0x88E1
 	STENCIL_BUFFER_BIT                    = 0x00000400
 	TEXTURE_2D                            = 0xde1
 	TEXTURE_BINDING_2D                    = 0x8069
@@ -102,6 +106,7 @@ const (
 	TEXTURE_WRAP_T                        = 0x2803
 	TEXTURE0                              = 0x84c0
 	TEXTURE1                              = 0x84c1
+	TIMEOUT_EXPIRED                       = 0x911B
 	TRIANGLE_STRIP                        = 0x5
 	TRIANGLES                             = 0x4
 	TRUE                                  = 1
@@ -122,6 +127,7 @@ const (
 	VERTEX_ATTRIB_ARRAY_SIZE              = 0x8623
 	VERTEX_ATTRIB_ARRAY_STRIDE            = 0x8624
 	VERTEX_ATTRIB_ARRAY_TYPE              = 0x8625
+	WAIT_FAILED                           = 0x911D
 	WRITE_ONLY                            = 0x88B9
 	ZERO                                  = 0x0
 
@@ -409,6 +409,14 @@ func (f *Functions) EndQuery(target Enum) {
 		f.EXT_disjoint_timer_query.Call("endQueryEXT", int(target))
 	}
 }
+func (f *Functions) FenceSync(condition Enum, flags Enum) Sync {
+	return Sync(js.Null())
+}
+func (f *Functions) ClientWaitSync(s Sync, flags Enum, timeout uint64) Enum {
+	return WAIT_FAILED
+}
+func (f *Functions) DeleteSync(s Sync) {
+}
 func (f *Functions) Finish() {
 	f._finish.Invoke()
 }
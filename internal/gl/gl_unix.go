@@ -33,6 +33,8 @@ typedef int GLint;
 typedef unsigned char GLboolean;
 typedef int GLsizei;
 typedef uint8_t GLubyte;
+typedef uint64_t GLuint64;
+typedef struct __GLsync *GLsync;
 
 typedef void (*_glActiveTexture)(GLenum texture);
 typedef void (*_glAttachShader)(GLuint program, GLuint shader);
@@ -129,6 +131,9 @@ typedef GLboolean (*_glUnmapBuffer)(GLenum target);
 typedef void (*_glBindImageTexture)(GLuint unit, GLuint texture, GLint level, GLboolean layered, GLint layer, GLenum access, GLenum format);
 typedef void (*_glTexStorage2D)(GLenum target, GLsizei levels, GLenum internalformat, GLsizei width, GLsizei height);
 typedef void (*_glBlitFramebuffer)(GLint srcX0, GLint srcY0, GLint srcX1, GLint srcY1, GLint dstX0, GLint dstY0, GLint dstX1, GLint dstY1, GLbitfield mask, GLenum filter);
+typedef GLsync (*_glFenceSync)(GLenum condition, GLbitfield flags);
+typedef GLenum (*_glClientWaitSync)(GLsync sync, GLbitfield flags, GLuint64 timeout);
+typedef void (*_glDeleteSync)(GLsync sync);
 
 static void glActiveTexture(_glActiveTexture f, GLenum texture) {
 	f(texture);
@@ -517,6 +522,18 @@ static void glTexStorage2D(_glTexStorage2D f, GLenum target, GLsizei levels, GLe
 static void glBlitFramebuffer(_glBlitFramebuffer f, GLint srcX0, GLint srcY0, GLint srcX1, GLint srcY1, GLint dstX0, GLint dstY0, GLint dstX1, GLint dstY1, GLbitfield mask, GLenum filter) {
 	f(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter);
 }
+
+static uintptr_t glFenceSync(_glFenceSync f, GLenum condition, GLbitfield flags) {
+	return (uintptr_t)f(condition, flags);
+}
+
+static GLenum glClientWaitSync(_glClientWaitSync f, uintptr_t sync, GLbitfield flags, GLuint64 timeout) {
+	return f((GLsync)sync, flags, timeout);
+}
+
+static void glDeleteSync(_glDeleteSync f, uintptr_t sync) {
+	f((GLsync)sync);
+}
 */
 import "C"
 
@@ -623,6 +640,9 @@ type Functions struct {
 	glBindImageTexture                    C._glBindImageTexture
 	glTexStorage2D                        C._glTexStorage2D
 	glBlitFramebuffer                     C._glBlitFramebuffer
+	glFenceSync                           C._glFenceSync
+	glClientWaitSync                      C._glClientWaitSync
+	glDeleteSync                          C._glDeleteSync
 }
 
 func NewFunctions(ctx Context, forceES bool) (*Functions, error) {
@@ -809,6 +829,9 @@ func (f *Functions) load(forceES bool) error {
 	f.glTexStorage2D = load("glTexStorage2D")
 	f.glBlitFramebuffer = load("glBlitFramebuffer")
 	f.glGetProgramBinary = load("glGetProgramBinary")
+	f.glFenceSync = load("glFenceSync")
+	f.glClientWaitSync = load("glClientWaitSync")
+	f.glDeleteSync = load("glDeleteSync")
 
 	return loadErr
 }
@@ -1039,6 +1062,22 @@ func (f *Functions) EnableVertexAttribArray(a Attrib) {
 	C.glEnableVertexAttribArray(f.glEnableVertexAttribArray, C.GLuint(a))
 }
 
+// FenceSync returns an invalid Sync if fences are not supported.
+func (f *Functions) FenceSync(condition Enum, flags Enum) Sync {
+	if f.glFenceSync == nil || f.glClientWaitSync == nil || f.glDeleteSync == nil {
+		return Sync{}
+	}
+	return Sync{V: uintptr(C.glFenceSync(f.glFenceSync, C.GLenum(condition), C.GLbitfield(flags)))}
+}
+
+func (f *Functions) ClientWaitSync(s Sync, flags Enum, timeout uint64) Enum {
+	return Enum(C.glClientWaitSync(f.glClientWaitSync, C.uintptr_t(s.V), C.GLbitfield(flags), C.GLuint64(timeout)))
+}
+
+func (f *Functions) DeleteSync(s Sync) {
+	C.glDeleteSync(f.glDeleteSync, C.uintptr_t(s.V))
+}
+
 func (f *Functions) Finish() {
 	C.glFinish(f.glFinish)
 }
@@ -16,6 +16,9 @@ var (
 	_glActiveTexture                       = LibGLESv2.NewProc("glActiveTexture")
 	_glAttachShader                        = LibGLESv2.NewProc("glAttachShader")
 	_glBeginQuery                          = LibGLESv2.NewProc("glBeginQuery")
+	_glClientWaitSync                      = LibGLESv2.NewProc("glClientWaitSync")
+	_glDeleteSync                          = LibGLESv2.NewProc("glDeleteSync")
+	_glFenceSync                           = LibGLESv2.NewProc("glFenceSync")
 	_glBindAttribLocation                  = LibGLESv2.NewProc("glBindAttribLocation")
 	_glBindBuffer                          = LibGLESv2.NewProc("glBindBuffer")
 	_glBindBufferBase                      = LibGLESv2.NewProc("glBindBufferBase")
@@ -292,6 +295,26 @@ func (c *Functions) EnableVertexAttribArray(a Attrib) {
 func (f *Functions) EndQuery(target Enum) {
 	syscall.Syscall(_glEndQuery.Addr(), 1, uintptr(target), 0, 0)
 }
+func (c *Functions) FenceSync(condition Enum, flags Enum) Sync {
+	if _glFenceSync.Find() != nil {
+		return Sync{}
+	}
+	s, _, _ := syscall.Syscall(_glFenceSync.Addr(), 2, uintptr(condition), uintptr(flags), 0)
+	return Sync{V: s}
+}
+func (c *Functions) ClientWaitSync(s Sync, flags Enum, timeout uint64) Enum {
+	var r uintptr
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		// The 64-bit timeout takes two arguments.
+		r, _, _ = syscall.Syscall6(_glClientWaitSync.Addr(), 4, s.V, uintptr(flags), uintptr(timeout), uintptr(timeout>>32), 0, 0)
+	} else {
+		r, _, _ = syscall.Syscall(_glClientWaitSync.Addr(), 3, s.V, uintptr(flags), uintptr(timeout))
+	}
+	return Enum(r)
+}
+func (c *Functions) DeleteSync(s Sync) {
+	syscall.Syscall(_glDeleteSync.Addr(), 1, s.V, 0, 0)
+}
 func (c *Functions) Finish() {
 	syscall.Syscall(_glFinish.Addr(), 0, 0, 0, 0)
 }
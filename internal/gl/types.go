@@ -14,6 +14,7 @@ type (
 	Query        Object
 	Uniform      struct{ V int }
 	VertexArray  Object
+	Sync         struct{ V uintptr }
 )
 
 func (o Object) valid() bool {
@@ -40,6 +41,10 @@ func (s Shader) Valid() bool {
 	return Object(s).valid()
 }
 
+func (s Sync) Valid() bool {
+	return s.V != 0
+}
+
 func (a VertexArray) Valid() bool {
 	return Object(a).valid()
 }
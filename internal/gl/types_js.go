@@ -15,6 +15,7 @@ type (
 	Query        Object
 	Uniform      Object
 	VertexArray  Object
+	Sync         Object
 )
 
 func (o Object) valid() bool {
@@ -88,3 +89,7 @@ func (t Texture) Equal(t2 Texture) bool {
 func (b Buffer) Equal(b2 Buffer) bool {
 	return Object(b).equal(Object(b2))
 }
+
+func (s Sync) Valid() bool {
+	return Object(s).valid()
+}
@@ -76,6 +76,7 @@ const (
 	TypeSnippet
 	TypeSelection
 	TypeActionInput
+	TypeKeyAccelerator
 )
 
 type StackID struct {
@@ -128,7 +129,7 @@ const (
 	TypeTransformLen        = 1 + 1 + 4*6
 	TypePopTransformLen     = 1
 	TypeRedrawLen           = 1 + 8
-	TypeImageLen            = 1
+	TypeImageLen            = 1 + 1
 	TypePaintLen            = 1
 	TypeColorLen            = 1 + 4
 	TypeLinearGradientLen   = 1 + 8*2 + 4*2
@@ -140,7 +141,7 @@ const (
 	TypeSourceLen           = 1
 	TypeTargetLen           = 1
 	TypeOfferLen            = 1
-	TypeKeyInputLen         = 1 + 1
+	TypeKeyInputLen         = 1 + 1 + 1
 	TypeKeyFocusLen         = 1 + 1
 	TypeKeySoftKeyboardLen  = 1 + 1
 	TypeSaveLen             = 1 + 4
@@ -160,6 +161,7 @@ const (
 	TypeSnippetLen          = 1 + 4 + 4
 	TypeSelectionLen        = 1 + 2*4 + 2*4 + 4 + 4
 	TypeActionInputLen      = 1 + 1
+	TypeKeyAcceleratorLen   = 1
 )
 
 func (op *ClipOp) Decode(data []byte) {
@@ -417,6 +419,7 @@ var opProps = [0x100]opProp{
 	TypeSnippet:          {Size: TypeSnippetLen, NumRefs: 2},
 	TypeSelection:        {Size: TypeSelectionLen, NumRefs: 1},
 	TypeActionInput:      {Size: TypeActionInputLen, NumRefs: 0},
+	TypeKeyAccelerator:   {Size: TypeKeyAcceleratorLen, NumRefs: 2},
 }
 
 func (t OpType) props() (size, numRefs int) {
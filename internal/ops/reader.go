@@ -68,6 +68,10 @@ func (r *Reader) Reset(ops *Ops) {
 
 // ResetAt is like Reset, except it starts reading from pc.
 func (r *Reader) ResetAt(ops *Ops, pc PC) {
+	// Leave the ops of earlier macros to the GC.
+	for i := range r.stack {
+		r.stack[i] = macro{}
+	}
 	r.stack = r.stack[:0]
 	Reset(&r.deferOps)
 	r.deferDone = false
@@ -86,6 +90,7 @@ func (r *Reader) Decode() (EncodedOp, bool) {
 			if r.pc == b.endPC {
 				r.ops = b.ops
 				r.pc = b.retPC
+				r.stack[len(r.stack)-1] = macro{}
 				r.stack = r.stack[:len(r.stack)-1]
 				continue
 			}
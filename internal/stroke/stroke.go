@@ -40,6 +40,10 @@ import (
 // op/clip, eliminating the duplicate types.
 type StrokeStyle struct {
 	Width float32
+	// Tolerance is the maximum distance between cubic Béziers and
+	// their quadratic approximations, or zero for a tolerance relative
+	// to the size of the curve.
+	Tolerance float32
 }
 
 // strokeTolerance is used to reconcile rounding errors arising
@@ -614,12 +618,12 @@ func dist(p1, p2 f32.Point) float64 {
 }
 
 func StrokePathCommands(style StrokeStyle, scene []byte) StrokeQuads {
-	quads := decodeToStrokeQuads(scene)
+	quads := decodeToStrokeQuads(scene, style.Tolerance)
 	return quads.stroke(style)
 }
 
 // decodeToStrokeQuads decodes scene commands to quads ready to stroke.
-func decodeToStrokeQuads(pathData []byte) StrokeQuads {
+func decodeToStrokeQuads(pathData []byte, tolerance float32) StrokeQuads {
 	quads := make(StrokeQuads, 0, 2*len(pathData)/(scene.CommandSize+4))
 	for len(pathData) >= scene.CommandSize+4 {
 		contour := binary.LittleEndian.Uint32(pathData)
@@ -645,7 +649,8 @@ func decodeToStrokeQuads(pathData []byte) StrokeQuads {
 			}
 			quads = append(quads, quad)
 		case scene.OpCubic:
-			for _, q := range SplitCubic(scene.DecodeCubic(cmd)) {
+			from, ctrl0, ctrl1, to := scene.DecodeCubic(cmd)
+			for _, q := range SplitCubic(from, ctrl0, ctrl1, to, tolerance) {
 				quad := StrokeQuad{
 					Contour: contour,
 					Quad:    q,
@@ -660,8 +665,15 @@ func decodeToStrokeQuads(pathData []byte) StrokeQuads {
 	return quads
 }
 
-func SplitCubic(from, ctrl0, ctrl1, to f32.Point) []QuadSegment {
+// SplitCubic approximates a cubic Bézier by quadratic Béziers that
+// deviate at most tolerance from it. A zero tolerance selects a
+// tolerance proportional to the size of the curve.
+func SplitCubic(from, ctrl0, ctrl1, to f32.Point, tolerance float32) []QuadSegment {
 	quads := make([]QuadSegment, 0, 10)
+	if tolerance > 0 {
+		approxCubeTo(&quads, 0, tolerance, from, ctrl0, ctrl1, to)
+		return quads
+	}
 	// Set the maximum distance proportionally to the longest side
 	// of the bounding rectangle.
 	hull := f32.Rectangle{
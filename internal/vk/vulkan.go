@@ -454,7 +454,8 @@ type (
 	Surface             = C.VkSurfaceKHR
 	SurfaceCapabilities = C.VkSurfaceCapabilitiesKHR
 
-	Swapchain = C.VkSwapchainKHR
+	PresentMode = C.VkPresentModeKHR
+	Swapchain   = C.VkSwapchainKHR
 )
 
 type VertexInputBindingDescription struct {
@@ -527,6 +528,10 @@ const (
 
 	FENCE_CREATE_SIGNALED_BIT = 0x00000001
 
+	PRESENT_MODE_IMMEDIATE_KHR PresentMode = C.VK_PRESENT_MODE_IMMEDIATE_KHR
+	PRESENT_MODE_MAILBOX_KHR   PresentMode = C.VK_PRESENT_MODE_MAILBOX_KHR
+	PRESENT_MODE_FIFO_KHR      PresentMode = C.VK_PRESENT_MODE_FIFO_KHR
+
 	BLEND_FACTOR_ZERO                BlendFactor = C.VK_BLEND_FACTOR_ZERO
 	BLEND_FACTOR_ONE                 BlendFactor = C.VK_BLEND_FACTOR_ONE
 	BLEND_FACTOR_ONE_MINUS_SRC_ALPHA BlendFactor = C.VK_BLEND_FACTOR_ONE_MINUS_SRC_ALPHA
@@ -989,23 +994,25 @@ func GetPhysicalDeviceSurfaceCapabilities(pd PhysicalDevice, surf Surface) (Surf
 	return caps, nil
 }
 
-func CreateSwapchain(pd PhysicalDevice, d Device, surf Surface, width, height int, old Swapchain) (Swapchain, []Image, Format, error) {
+// CreateSwapchain creates a swapchain for surf. The first supported present
+// mode of prefer is used, falling back to a default mode, and returned.
+func CreateSwapchain(pd PhysicalDevice, d Device, surf Surface, width, height int, old Swapchain, prefer ...PresentMode) (Swapchain, []Image, Format, PresentMode, error) {
 	caps, err := GetPhysicalDeviceSurfaceCapabilities(pd, surf)
 	if err != nil {
-		return nilSwapchain, nil, 0, err
+		return nilSwapchain, nil, 0, 0, err
 	}
-	mode, modeOK, err := choosePresentMode(pd, surf)
+	mode, modeOK, err := choosePresentMode(pd, surf, prefer...)
 	if err != nil {
-		return nilSwapchain, nil, 0, err
+		return nilSwapchain, nil, 0, 0, err
 	}
 	format, fmtOK, err := chooseFormat(pd, surf)
 	if err != nil {
-		return nilSwapchain, nil, 0, err
+		return nilSwapchain, nil, 0, 0, err
 	}
 	if !modeOK || !fmtOK {
 		// This shouldn't happen because CreateDeviceAndQueue found at least
 		// one valid format and present mode.
-		return nilSwapchain, nil, 0, errors.New("vulkan: no valid format and present mode found")
+		return nilSwapchain, nil, 0, 0, errors.New("vulkan: no valid format and present mode found")
 	}
 	// Find supported alpha composite mode. It doesn't matter which one, because rendering is
 	// always opaque.
@@ -1015,7 +1022,7 @@ func CreateSwapchain(pd PhysicalDevice, d Device, surf Surface, width, height in
 	}
 	trans := C.VkSurfaceTransformFlagBitsKHR(C.VK_SURFACE_TRANSFORM_IDENTITY_BIT_KHR)
 	if caps.supportedTransforms&C.VkSurfaceTransformFlagsKHR(trans) == 0 {
-		return nilSwapchain, nil, 0, errors.New("vulkan: VK_SURFACE_TRANSFORM_IDENTITY_BIT_KHR not supported")
+		return nilSwapchain, nil, 0, 0, errors.New("vulkan: VK_SURFACE_TRANSFORM_IDENTITY_BIT_KHR not supported")
 	}
 	inf := C.VkSwapchainCreateInfoKHR{
 		sType:            C.VK_STRUCTURE_TYPE_SWAPCHAIN_CREATE_INFO_KHR,
@@ -1035,23 +1042,23 @@ func CreateSwapchain(pd PhysicalDevice, d Device, surf Surface, width, height in
 	}
 	var swchain Swapchain
 	if err := vkErr(C.vkCreateSwapchainKHR(funcs.vkCreateSwapchainKHR, d, &inf, nil, &swchain)); err != nil {
-		return nilSwapchain, nil, 0, fmt.Errorf("vulkan: vkCreateSwapchainKHR: %w", err)
+		return nilSwapchain, nil, 0, 0, fmt.Errorf("vulkan: vkCreateSwapchainKHR: %w", err)
 	}
 	var count C.uint32_t
 	if err := vkErr(C.vkGetSwapchainImagesKHR(funcs.vkGetSwapchainImagesKHR, d, swchain, &count, nil)); err != nil {
 		DestroySwapchain(d, swchain)
-		return nilSwapchain, nil, 0, fmt.Errorf("vulkan: vkGetSwapchainImagesKHR: %w", err)
+		return nilSwapchain, nil, 0, 0, fmt.Errorf("vulkan: vkGetSwapchainImagesKHR: %w", err)
 	}
 	if count == 0 {
 		DestroySwapchain(d, swchain)
-		return nilSwapchain, nil, 0, errors.New("vulkan: vkGetSwapchainImagesKHR returned no images")
+		return nilSwapchain, nil, 0, 0, errors.New("vulkan: vkGetSwapchainImagesKHR returned no images")
 	}
 	imgs := make([]Image, count)
 	if err := vkErr(C.vkGetSwapchainImagesKHR(funcs.vkGetSwapchainImagesKHR, d, swchain, &count, &imgs[0])); err != nil {
 		DestroySwapchain(d, swchain)
-		return nilSwapchain, nil, 0, fmt.Errorf("vulkan: vkGetSwapchainImagesKHR: %w", err)
+		return nilSwapchain, nil, 0, 0, fmt.Errorf("vulkan: vkGetSwapchainImagesKHR: %w", err)
 	}
-	return swchain, imgs, format.format, nil
+	return swchain, imgs, format.format, mode, nil
 }
 
 func DestroySwapchain(d Device, swchain Swapchain) {
@@ -2045,7 +2052,7 @@ func findMemoryTypeIndex(pd C.VkPhysicalDevice, constraints C.uint32_t, wantProp
 	return 0, false
 }
 
-func choosePresentMode(pd C.VkPhysicalDevice, surf Surface) (C.VkPresentModeKHR, bool, error) {
+func choosePresentMode(pd C.VkPhysicalDevice, surf Surface, prefer ...PresentMode) (C.VkPresentModeKHR, bool, error) {
 	var count C.uint32_t
 	err := vkErr(C.vkGetPhysicalDeviceSurfacePresentModesKHR(funcs.vkGetPhysicalDeviceSurfacePresentModesKHR, pd, surf, &count, nil))
 	if err != nil {
@@ -2059,6 +2066,13 @@ func choosePresentMode(pd C.VkPhysicalDevice, surf Surface) (C.VkPresentModeKHR,
 	if err != nil {
 		return 0, false, fmt.Errorf("vulkan: kGetPhysicalDeviceSurfacePresentModesKHR: %w", err)
 	}
+	for _, p := range prefer {
+		for _, m := range modes {
+			if m == p {
+				return m, true, nil
+			}
+		}
+	}
 	for _, m := range modes {
 		if m == C.VK_PRESENT_MODE_MAILBOX_KHR || m == C.VK_PRESENT_MODE_FIFO_KHR {
 			return m, true, nil
@@ -11,8 +11,29 @@ import (
 // Event is generated when the clipboard content is requested.
 type Event struct {
 	Text string
+	// Type is the MIME type of Data for reads of
+	// clipboard data.
+	Type string
+	// Data is the clipboard content of type Type, or nil
+	// if the clipboard doesn't contain data of that type.
+	Data []byte
+	// Types lists the MIME types available on the clipboard,
+	// if known.
+	Types []string
 }
 
+// Selection identifies a system clipboard.
+type Selection uint8
+
+const (
+	// SelectionClipboard is the regular clipboard.
+	SelectionClipboard Selection = iota
+	// SelectionPrimary is the X11 primary selection, used for
+	// middle-click pasting. Platforms without a primary selection
+	// use the regular clipboard in its place.
+	SelectionPrimary
+)
+
 // ReadOp requests the text of the clipboard, delivered to
 // the current handler through an Event.
 type ReadOp struct {
@@ -28,6 +28,8 @@ type InputOp struct {
 	Tag event.Tag
 	// Hint describes the type of text expected by Tag.
 	Hint InputHint
+	// Options control the text corrections of the input method.
+	Options InputOptions
 	// Keys is the set of keys Tag can handle. That is, Tag will only
 	// receive an Event if its key and modifiers are accepted by Keys.Contains.
 	// As a special case, the topmost (first added) InputOp handler receives all
@@ -51,6 +53,18 @@ type InputOp struct {
 //   - Shift-(Ctrl)-A matches A if shift is pressed, and optionally ctrl.
 type Set string
 
+// AcceleratorOp registers Tag for the key combinations in Keys, such as
+// "Short-S" for saving. A key event that matches an accelerator has its
+// Accelerator field set, so a focused text field can tell shortcuts from
+// typed text. The event is routed like any other key event, and is
+// delivered to Tag only if no key handler accepts it. If several
+// accelerators match, the first added receives the event. Accelerators
+// outside the area of a modal handler are ignored.
+type AcceleratorOp struct {
+	Tag  event.Tag
+	Keys Set
+}
+
 // SoftKeyboardOp shows or hide the on-screen keyboard, if available.
 // It replaces any previous SoftKeyboardOp.
 type SoftKeyboardOp struct {
@@ -127,6 +141,8 @@ type Event struct {
 	Modifiers Modifiers
 	// State is the state of the key when the event was fired.
 	State State
+	// Accelerator is set for events that match an AcceleratorOp.
+	Accelerator bool
 }
 
 // An EditEvent requests an edit by an input method.
@@ -155,6 +171,45 @@ const (
 	HintTelephone
 )
 
+// InputOptions are flags that control the corrections the input method
+// and on-screen keyboard apply to text input, such as for code,
+// user name and password fields. The zero value leaves the corrections
+// to the platform.
+//
+// At most one of the capitalization flags should be set. If several are,
+// NoAutocapitalize takes precedence, followed by the flags in the order
+// they are declared.
+type InputOptions uint8
+
+const (
+	// NoAutocorrect disables automatic corrections and suggestions.
+	NoAutocorrect InputOptions = 1 << iota
+	// NoSpellcheck disables marking misspelled words.
+	NoSpellcheck
+	// NoAutocapitalize disables automatic capitalization.
+	NoAutocapitalize
+	// AutocapitalizeCharacters capitalizes every character.
+	AutocapitalizeCharacters
+	// AutocapitalizeWords capitalizes the first letter of every word.
+	AutocapitalizeWords
+	// AutocapitalizeSentences capitalizes the first letter of every
+	// sentence.
+	AutocapitalizeSentences
+)
+
+// TextInputState is a change of the text input state, such as
+// showing or hiding the on-screen keyboard.
+type TextInputState uint8
+
+const (
+	// TextInputKeep leaves the text input unchanged.
+	TextInputKeep TextInputState = iota
+	// TextInputClose closes the text input.
+	TextInputClose
+	// TextInputOpen opens the text input.
+	TextInputOpen
+)
+
 // State is the state of a key during an event.
 type State uint8
 
@@ -325,6 +380,16 @@ func (h InputOp) Add(o *op.Ops) {
 	data := ops.Write2(&o.Internal, ops.TypeKeyInputLen, h.Tag, &filter)
 	data[0] = byte(ops.TypeKeyInput)
 	data[1] = byte(h.Hint)
+	data[2] = byte(h.Options)
+}
+
+func (a AcceleratorOp) Add(o *op.Ops) {
+	if a.Tag == nil {
+		panic("Tag must be non-nil")
+	}
+	keys := a.Keys
+	data := ops.Write2(&o.Internal, ops.TypeKeyAcceleratorLen, a.Tag, &keys)
+	data[0] = byte(ops.TypeKeyAccelerator)
 }
 
 func (h SoftKeyboardOp) Add(o *op.Ops) {
@@ -390,6 +455,19 @@ func (m Modifiers) String() string {
 	return strings.Join(strs, "-")
 }
 
+func (t TextInputState) String() string {
+	switch t {
+	case TextInputKeep:
+		return "Keep"
+	case TextInputClose:
+		return "Close"
+	case TextInputOpen:
+		return "Open"
+	default:
+		panic("unexpected value")
+	}
+}
+
 func (s State) String() string {
 	switch s {
 	case Press:
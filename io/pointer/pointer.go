@@ -37,9 +37,30 @@ type Event struct {
 	Position f32.Point
 	// Scroll is the scroll amount, if any.
 	Scroll f32.Point
+	// Delta is the relative motion of the pointer in pixels. It is
+	// only set for Move and Drag events while the window is in
+	// relative mouse mode, where Position doesn't change.
+	Delta f32.Point
 	// Modifiers is the set of active modifiers when
 	// the mouse button was pressed.
 	Modifiers key.Modifiers
+	// History points to the positions of the earlier Move or Drag
+	// events merged into this event, oldest first, when pointer move
+	// coalescing is enabled, and is nil otherwise. Position is the
+	// latest position. History is a pointer to keep Event comparable.
+	History *[]f32.Point
+}
+
+// LongPressEvent is sent to the handlers of a touch pointer that is held
+// down for the long-press duration without moving farther than the touch
+// slop from where it was pressed. The pointer remains pressed, and its
+// Release or Cancel event follows.
+type LongPressEvent struct {
+	// PointerID is the id of the pressed pointer.
+	PointerID ID
+	// Position is the position of the pointer, relative to the
+	// current transformation, as set by op.TransformOp.
+	Position f32.Point
 }
 
 // PassOp sets the pass-through mode. InputOps added while the pass-through
@@ -61,6 +82,10 @@ type InputOp struct {
 	// Grab, if set, request that the handler get
 	// Grabbed priority.
 	Grab bool
+	// Consume, if set, stops the delivery of events received
+	// by the handler to handlers behind it, including the
+	// handlers of enclosing areas.
+	Consume bool
 	// Types is a bitwise-or of event types to receive.
 	Types Type
 	// ScrollBounds describe the maximum scrollable distances in both
@@ -184,6 +209,9 @@ const (
 	Leave
 	// Scroll of a pointer.
 	Scroll
+	// Dismiss is sent to a modal handler when a pointer is pressed
+	// outside its area. See Router.SetModal.
+	Dismiss
 )
 
 const (
@@ -249,7 +277,10 @@ func (op InputOp) Add(o *op.Ops) {
 	data := ops.Write1(&o.Internal, ops.TypePointerInputLen, op.Tag)
 	data[0] = byte(ops.TypePointerInput)
 	if op.Grab {
-		data[1] = 1
+		data[1] |= 1
+	}
+	if op.Consume {
+		data[1] |= 2
 	}
 	bo := binary.LittleEndian
 	bo.PutUint16(data[2:], uint16(op.Types))
@@ -293,6 +324,8 @@ func (t Type) string() string {
 		return "Leave"
 	case Scroll:
 		return "Scroll"
+	case Dismiss:
+		return "Dismiss"
 	default:
 		panic("unknown Type")
 	}
@@ -402,3 +435,5 @@ func (c Cursor) String() string {
 }
 
 func (Event) ImplementsEvent() {}
+
+func (LongPressEvent) ImplementsEvent() {}
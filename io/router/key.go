@@ -21,7 +21,7 @@ type EditorState struct {
 	Snippet key.Snippet
 }
 
-type TextInputState uint8
+type TextInputState = key.TextInputState
 
 type keyQueue struct {
 	focus    event.Tag
@@ -30,7 +30,18 @@ type keyQueue struct {
 	handlers map[event.Tag]*keyHandler
 	state    TextInputState
 	hint     key.InputHint
+	options  key.InputOptions
 	content  EditorState
+	accels   []accelerator
+	// open tracks whether the most recent state returned by InputState
+	// opened the text input.
+	open bool
+}
+
+// accelerator is an AcceleratorOp and the area it was added in.
+type accelerator struct {
+	op   key.AcceleratorOp
+	area int
 }
 
 type keyHandler struct {
@@ -39,9 +50,13 @@ type keyHandler struct {
 	visible  bool
 	new      bool
 	hint     key.InputHint
+	options  key.InputOptions
 	order    int
 	dirOrder int
 	filter   key.Set
+	// text is set for handlers that describe their content with
+	// key.SnippetOp or key.SelectionOp, such as text fields.
+	text bool
 }
 
 // keyCollector tracks state required to update a keyQueue
@@ -60,9 +75,9 @@ type dirFocusEntry struct {
 }
 
 const (
-	TextInputKeep TextInputState = iota
-	TextInputClose
-	TextInputOpen
+	TextInputKeep  = key.TextInputKeep
+	TextInputClose = key.TextInputClose
+	TextInputOpen  = key.TextInputOpen
 )
 
 type FocusDirection int
@@ -81,21 +96,28 @@ const (
 func (q *keyQueue) InputState() TextInputState {
 	state := q.state
 	q.state = TextInputKeep
+	switch state {
+	case TextInputOpen:
+		q.open = true
+	case TextInputClose:
+		q.open = false
+	}
 	return state
 }
 
-// InputHint returns the input mode from the most recent key.InputOp.
-func (q *keyQueue) InputHint() (key.InputHint, bool) {
+// InputHint returns the input mode and options from the most recent
+// key.InputOp.
+func (q *keyQueue) InputHint() (key.InputHint, key.InputOptions, bool) {
 	if q.focus == nil {
-		return q.hint, false
+		return q.hint, q.options, false
 	}
 	focused, ok := q.handlers[q.focus]
 	if !ok {
-		return q.hint, false
+		return q.hint, q.options, false
 	}
-	old := q.hint
-	q.hint = focused.hint
-	return q.hint, old != q.hint
+	oldHint, oldOpts := q.hint, q.options
+	q.hint, q.options = focused.hint, focused.options
+	return q.hint, q.options, oldHint != q.hint || oldOpts != q.options
 }
 
 func (q *keyQueue) Reset() {
@@ -108,6 +130,7 @@ func (q *keyQueue) Reset() {
 	}
 	q.order = q.order[:0]
 	q.dirOrder = q.dirOrder[:0]
+	q.accels = q.accels[:0]
 }
 
 func (q *keyQueue) Frame(events *handlerEvents, collector keyCollector) {
@@ -269,6 +292,17 @@ func (q *keyQueue) Accepts(t event.Tag, e key.Event) bool {
 	return q.handlers[t].filter.Contains(e.Name, e.Modifiers)
 }
 
+// AcceleratorFor returns the tag of the first accelerator that matches e
+// and whose area is accepted by inArea.
+func (q *keyQueue) AcceleratorFor(e key.Event, inArea func(area int) bool) (event.Tag, bool) {
+	for _, a := range q.accels {
+		if a.op.Keys.Contains(e.Name, e.Modifiers) && inArea(a.area) {
+			return a.op.Tag, true
+		}
+	}
+	return nil, false
+}
+
 func (q *keyQueue) setFocus(focus event.Tag, events *handlerEvents) {
 	if focus != nil {
 		if _, exists := q.handlers[focus]; !exists {
@@ -286,8 +320,15 @@ func (q *keyQueue) setFocus(focus event.Tag, events *handlerEvents) {
 	if q.focus != nil {
 		events.Add(q.focus, key.FocusEvent{Focus: true})
 	}
-	if q.focus == nil || q.state == TextInputKeep {
+	switch {
+	case q.focus == nil:
 		q.state = TextInputClose
+	case q.state == TextInputKeep:
+		// Moving between text fields keeps an open text input, switching
+		// its target instead of closing and reopening it.
+		if !q.open || !q.handlers[q.focus].text {
+			q.state = TextInputClose
+		}
 	}
 }
 
@@ -296,6 +337,10 @@ func (k *keyCollector) focusOp(tag event.Tag) {
 	k.changed = true
 }
 
+func (k *keyCollector) acceleratorOp(op key.AcceleratorOp, area int) {
+	k.q.accels = append(k.q.accels, accelerator{op: op, area: area})
+}
+
 func (k *keyCollector) softKeyboard(show bool) {
 	if show {
 		k.q.state = TextInputOpen
@@ -322,10 +367,12 @@ func (k *keyCollector) inputOp(op key.InputOp, area int, bounds image.Rectangle)
 	h := k.handlerFor(op.Tag, area, bounds)
 	h.visible = true
 	h.hint = op.Hint
+	h.options = op.Options
 	h.filter = op.Keys
 }
 
 func (k *keyCollector) selectionOp(t f32.Affine2D, op key.SelectionOp) {
+	k.textOp(op.Tag)
 	if op.Tag == k.q.focus {
 		k.q.content.Selection.Range = op.Range
 		k.q.content.Selection.Caret = op.Caret
@@ -334,20 +381,15 @@ func (k *keyCollector) selectionOp(t f32.Affine2D, op key.SelectionOp) {
 }
 
 func (k *keyCollector) snippetOp(op key.SnippetOp) {
+	k.textOp(op.Tag)
 	if op.Tag == k.q.focus {
 		k.q.content.Snippet = op.Snippet
 	}
 }
 
-func (t TextInputState) String() string {
-	switch t {
-	case TextInputKeep:
-		return "Keep"
-	case TextInputClose:
-		return "Close"
-	case TextInputOpen:
-		return "Open"
-	default:
-		panic("unexpected value")
+// textOp marks the handler for tag as a text field.
+func (k *keyCollector) textOp(tag event.Tag) {
+	if h, ok := k.q.handlers[tag]; ok {
+		h.text = true
 	}
 }
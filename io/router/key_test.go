@@ -97,6 +97,33 @@ func TestKeySoftKeyboardNoFocus(t *testing.T) {
 	assertKeyboard(t, r, TextInputOpen)
 }
 
+func TestKeyInputOptions(t *testing.T) {
+	handler := new(int)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	opts := key.NoAutocorrect | key.NoAutocapitalize
+	key.InputOp{Tag: handler, Hint: key.HintEmail, Options: opts}.Add(ops)
+	key.FocusOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+
+	hint, gotOpts, changed := r.TextInputHint()
+	if hint != key.HintEmail || gotOpts != opts || !changed {
+		t.Errorf("got hint %v, options %v, changed %v; want %v, %v, true", hint, gotOpts, changed, key.HintEmail, opts)
+	}
+
+	ops.Reset()
+	key.InputOp{Tag: handler, Hint: key.HintEmail}.Add(ops)
+	r.Frame(ops)
+
+	if _, gotOpts, changed := r.TextInputHint(); gotOpts != 0 || !changed {
+		t.Errorf("got options %v, changed %v; want 0, true", gotOpts, changed)
+	}
+	if _, _, changed := r.TextInputHint(); changed {
+		t.Error("options changed without a new InputOp")
+	}
+}
+
 func TestKeyRemoveFocus(t *testing.T) {
 	handlers := make([]int, 2)
 	ops := new(op.Ops)
@@ -224,6 +251,138 @@ func TestKeyFocusedInvisible(t *testing.T) {
 
 }
 
+func TestKeyFocusTextFields(t *testing.T) {
+	handlers := make([]int, 3)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	frame := func(focus *int) {
+		ops.Reset()
+		for i := range handlers[:2] {
+			key.InputOp{Tag: &handlers[i]}.Add(ops)
+			key.SnippetOp{Tag: &handlers[i]}.Add(ops)
+		}
+		key.InputOp{Tag: &handlers[2]}.Add(ops)
+		if focus != nil {
+			key.FocusOp{Tag: focus}.Add(ops)
+		}
+		r.Frame(ops)
+	}
+
+	frame(&handlers[0])
+	key.SoftKeyboardOp{Show: true}.Add(ops)
+	r.Frame(ops)
+	if got := r.TextInputState(); got != TextInputOpen {
+		t.Fatalf("expected %v keyboard, got %v", TextInputOpen, got)
+	}
+
+	// Moving between text fields keeps the keyboard open and
+	// switches the target.
+	frame(&handlers[1])
+	assertFocus(t, r, &handlers[1])
+	if got := r.TextInputState(); got != TextInputKeep {
+		t.Errorf("expected %v keyboard, got %v", TextInputKeep, got)
+	}
+	if got := r.TextInputTarget(); got != &handlers[1] {
+		t.Errorf("expected target %v, got %v", &handlers[1], got)
+	}
+
+	// Moving to a handler that is not a text field closes the
+	// keyboard.
+	frame(&handlers[2])
+	if got := r.TextInputState(); got != TextInputClose {
+		t.Errorf("expected %v keyboard, got %v", TextInputClose, got)
+	}
+
+	// A closed keyboard stays closed.
+	frame(&handlers[0])
+	if got := r.TextInputState(); got != TextInputClose {
+		t.Errorf("expected %v keyboard, got %v", TextInputClose, got)
+	}
+
+	// A keyboard opened by SetTextInputState is tracked like one opened
+	// by SoftKeyboardOp.
+	r.SetTextInputState(TextInputOpen)
+	if got := r.TextInputState(); got != TextInputOpen {
+		t.Errorf("expected %v keyboard, got %v", TextInputOpen, got)
+	}
+	frame(&handlers[1])
+	if got := r.TextInputState(); got != TextInputKeep {
+		t.Errorf("expected %v keyboard, got %v", TextInputKeep, got)
+	}
+}
+
+func TestKeyAccelerator(t *testing.T) {
+	handlers := make([]int, 3)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	key.InputOp{Tag: &handlers[0], Keys: "Short-S|A"}.Add(ops)
+	key.FocusOp{Tag: &handlers[0]}.Add(ops)
+	key.AcceleratorOp{Tag: &handlers[1], Keys: "Short-S"}.Add(ops)
+	key.AcceleratorOp{Tag: &handlers[2], Keys: "Short-[S,O]"}.Add(ops)
+	r.Frame(ops)
+	r.Events(&handlers[0])
+
+	save := key.Event{Name: "S", Modifiers: key.ModShortcut, State: key.Press}
+	open := key.Event{Name: "O", Modifiers: key.ModShortcut, State: key.Press}
+	typed := key.Event{Name: "A", State: key.Press}
+	r.Queue(save, open, typed)
+
+	accel := func(e key.Event) key.Event {
+		e.Accelerator = true
+		return e
+	}
+	// The focused handler receives the accelerators it accepts, marked
+	// as such.
+	if got, want := r.Events(&handlers[0]), []event.Event{accel(save), typed}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+	if got := r.Events(&handlers[1]); len(got) != 0 {
+		t.Errorf("got events %v, want none", got)
+	}
+	// Accelerators no handler accepts are delivered to their tag.
+	if got, want := r.Events(&handlers[2]), []event.Event{accel(open)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+
+	// A modal grab restricts accelerators to its area.
+	background, modal, dialog := new(int), new(int), new(int)
+	ops.Reset()
+	key.AcceleratorOp{Tag: background, Keys: "Short-[S,O]"}.Add(ops)
+	cl := clip.Rect(image.Rect(50, 50, 100, 100)).Push(ops)
+	pointer.InputOp{Tag: modal, Types: pointer.Press}.Add(ops)
+	key.AcceleratorOp{Tag: dialog, Keys: "Short-O"}.Add(ops)
+	cl.Pop()
+	r.Frame(ops)
+	r.SetModal(modal)
+	r.Queue(save, open)
+	if got := r.Events(background); len(got) != 0 {
+		t.Errorf("got events %v outside the modal area", got)
+	}
+	if got, want := r.Events(dialog), []event.Event{accel(open)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+func TestPressedKeys(t *testing.T) {
+	r := new(Router)
+	r.Queue(
+		key.Event{Name: "A", State: key.Press},
+		key.Event{Name: key.NameShift, State: key.Press},
+		key.Event{Name: "B", State: key.Press},
+		key.Event{Name: "A", State: key.Release},
+	)
+	if got, want := r.PressedKeys(), []string{"B", key.NameShift}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got pressed keys %v, want %v", got, want)
+	}
+	// Losing focus releases all keys.
+	r.Queue(key.FocusEvent{Focus: false})
+	if got := r.PressedKeys(); len(got) != 0 {
+		t.Errorf("got pressed keys %v after focus loss", got)
+	}
+}
+
 func TestNoOps(t *testing.T) {
 	r := new(Router)
 	r.Frame(nil)
@@ -5,6 +5,7 @@ package router
 import (
 	"image"
 	"io"
+	"time"
 
 	"gioui.org/f32"
 	f32internal "gioui.org/internal/f32"
@@ -24,9 +25,24 @@ type pointerQueue struct {
 	handlers  map[event.Tag]*pointerHandler
 	pointers  []pointerInfo
 	transfers []io.ReadCloser // pending data transfers
+	// modal is the handler tag that restricts pointer input to
+	// its area, or nil.
+	modal event.Tag
 
 	scratch []event.Tag
 
+	// touchSlop and longPressTime configure long presses. Zero values
+	// select the defaults.
+	touchSlop     float32
+	longPressTime time.Duration
+	// timeBase is the earliest estimate of the time corresponding to
+	// the zero event time, for converting event timestamps to times.
+	// Events are delivered after they occur, so the estimate from the
+	// least delayed event is the most accurate.
+	timeBase time.Time
+	// clock returns the current time, or is nil for time.Now.
+	clock func() time.Time
+
 	semantic struct {
 		idsAssigned bool
 		lastID      SemanticID
@@ -60,12 +76,18 @@ type pointerInfo struct {
 
 	dataSource event.Tag // dragging source tag
 	dataTarget event.Tag // dragging target tag
+
+	// longPress is the time of the pending long press of a touch
+	// pointer pressed at pressPos, or zero.
+	longPress time.Time
+	pressPos  f32.Point
 }
 
 type pointerHandler struct {
 	area      int
 	active    bool
 	wantsGrab bool
+	consumes  bool
 	types     pointer.Type
 	// min and max horizontal/vertical scroll
 	scrollRange image.Rectangle
@@ -140,6 +162,15 @@ const (
 	areaEllipse
 )
 
+const (
+	// defaultTouchSlop is the distance in pixels a touch pointer may
+	// move without canceling a long press.
+	defaultTouchSlop = 8
+	// defaultLongPressTime is the time a touch pointer is held before
+	// a long press.
+	defaultLongPressTime = 500 * time.Millisecond
+)
+
 func (c *pointerCollector) resetState() {
 	c.state = collectState{}
 	c.nodeStack = c.nodeStack[:0]
@@ -277,6 +308,7 @@ func (c *pointerCollector) inputOp(op pointer.InputOp, events *handlerEvents) {
 	area.semantic.valid = area.semantic.content.gestures != 0
 	h := c.newHandler(op.Tag, events)
 	h.wantsGrab = h.wantsGrab || op.Grab
+	h.consumes = h.consumes || op.Consume
 	h.types = h.types | op.Types
 	h.scrollRange = op.ScrollBounds
 }
@@ -366,6 +398,30 @@ func (q *pointerQueue) assignSemIDs() {
 	}
 }
 
+func (q *pointerQueue) AppendInputAreas(areas []InputArea) []InputArea {
+	for i := len(q.hitTree) - 1; i >= 0; i-- {
+		n := &q.hitTree[i]
+		if n.tag == nil || n.area == -1 {
+			continue
+		}
+		h, ok := q.handlers[n.tag]
+		if !ok {
+			continue
+		}
+		bounds := q.areas[n.area].bounds()
+		for p := q.areas[n.area].parent; p != -1; p = q.areas[p].parent {
+			bounds = bounds.Intersect(q.areas[p].bounds())
+		}
+		areas = append(areas, InputArea{
+			Tag:         n.tag,
+			Bounds:      bounds,
+			Types:       h.types,
+			PassThrough: n.pass,
+		})
+	}
+	return areas
+}
+
 func (q *pointerQueue) AppendSemantics(nodes []SemanticNode) []SemanticNode {
 	q.assignSemIDs()
 	nodes = q.appendSemanticChildren(nodes, 0)
@@ -470,7 +526,7 @@ func (q *pointerQueue) opHit(pos f32.Point) ([]event.Tag, pointer.Cursor) {
 	for idx >= 0 {
 		n := &q.hitTree[idx]
 		hit, c := q.hit(n.area, pos)
-		if !hit {
+		if !hit || !q.inModal(n.area) {
 			idx--
 			continue
 		}
@@ -493,6 +549,34 @@ func (q *pointerQueue) opHit(pos f32.Point) ([]event.Tag, pointer.Cursor) {
 	return hits, cursor
 }
 
+// modalHandler returns the active modal handler, if any.
+func (q *pointerQueue) modalHandler() (*pointerHandler, bool) {
+	if q.modal == nil {
+		return nil, false
+	}
+	h, ok := q.handlers[q.modal]
+	if !ok || !h.active {
+		return nil, false
+	}
+	return h, true
+}
+
+// inModal reports whether the area is the area of the active modal
+// handler or one of its descendants. Every area is inside the modal
+// when no modal handler is active.
+func (q *pointerQueue) inModal(area int) bool {
+	h, ok := q.modalHandler()
+	if !ok {
+		return true
+	}
+	for ; area != -1; area = q.areas[area].parent {
+		if area == h.area {
+			return true
+		}
+	}
+	return false
+}
+
 func (q *pointerQueue) invTransform(areaIdx int, p f32.Point) f32.Point {
 	if areaIdx == -1 {
 		return p
@@ -524,6 +608,7 @@ func (q *pointerQueue) reset() {
 		// Reset handler.
 		h.active = false
 		h.wantsGrab = false
+		h.consumes = false
 		h.types = 0
 		h.sourceMimes = h.sourceMimes[:0]
 		h.targetMimes = h.targetMimes[:0]
@@ -649,7 +734,7 @@ func (q *pointerQueue) Deliver(areaIdx int, e pointer.Event, events *handlerEven
 		}
 		e.Position = q.invTransform(h.area, e.Position)
 		events.Add(n.tag, e)
-		if e.Type != pointer.Scroll {
+		if e.Type != pointer.Scroll || h.consumes {
 			break
 		}
 	}
@@ -668,6 +753,21 @@ func (q *pointerQueue) SemanticArea(areaIdx int) (semanticContent, int) {
 	return semanticContent{}, -1
 }
 
+// deliverDismissEvent notifies the modal handler of a press outside its
+// area.
+func (q *pointerQueue) deliverDismissEvent(events *handlerEvents, e pointer.Event) {
+	h, ok := q.modalHandler()
+	if !ok || h.types&pointer.Dismiss == 0 {
+		return
+	}
+	if hit, _ := q.hit(h.area, e.Position); hit {
+		return
+	}
+	e.Type = pointer.Dismiss
+	e.Position = q.invTransform(h.area, e.Position)
+	events.Add(q.modal, e)
+}
+
 func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	if e.Type == pointer.Cancel {
 		q.pointers = q.pointers[:0]
@@ -679,16 +779,31 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	pidx := q.pointerOf(e)
 	p := &q.pointers[pidx]
 	p.last = e
+	at := q.eventTime(e)
 
 	switch e.Type {
 	case pointer.Press:
 		q.deliverEnterLeaveEvents(p, events, e)
 		p.pressed = true
 		q.deliverEvent(p, events, e)
+		q.deliverDismissEvent(events, e)
+		if e.Source == pointer.Touch {
+			p.longPress = at.Add(q.longPressDuration())
+			p.pressPos = e.Position
+		}
 	case pointer.Move:
 		if p.pressed {
 			e.Type = pointer.Drag
 		}
+		if !p.longPress.IsZero() {
+			slop := q.touchSlop
+			if slop == 0 {
+				slop = defaultTouchSlop
+			}
+			if d := e.Position.Sub(p.pressPos); d.X*d.X+d.Y*d.Y > slop*slop {
+				p.longPress = time.Time{}
+			}
+		}
 		q.deliverEnterLeaveEvents(p, events, e)
 		q.deliverEvent(p, events, e)
 		if p.pressed {
@@ -697,6 +812,7 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	case pointer.Release:
 		q.deliverEvent(p, events, e)
 		p.pressed = false
+		p.longPress = time.Time{}
 		q.deliverEnterLeaveEvents(p, events, e)
 		q.deliverDropEvent(p, events)
 	case pointer.Scroll:
@@ -712,6 +828,65 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	}
 }
 
+// now returns the current time.
+func (q *pointerQueue) now() time.Time {
+	if q.clock == nil {
+		return time.Now()
+	}
+	return q.clock()
+}
+
+// eventTime returns the time e occurred from its timestamp, or the
+// current time for events without a timestamp.
+func (q *pointerQueue) eventTime(e pointer.Event) time.Time {
+	now := q.now()
+	if e.Time == 0 {
+		return now
+	}
+	if base := now.Add(-e.Time); q.timeBase.IsZero() || base.Before(q.timeBase) {
+		q.timeBase = base
+	}
+	return q.timeBase.Add(e.Time)
+}
+
+func (q *pointerQueue) longPressDuration() time.Duration {
+	if q.longPressTime == 0 {
+		return defaultLongPressTime
+	}
+	return q.longPressTime
+}
+
+// LongPresses delivers a pointer.LongPressEvent for every pending long
+// press that is due at now, and returns the time of the earliest long
+// press still pending, if any.
+func (q *pointerQueue) LongPresses(now time.Time, events *handlerEvents) (time.Time, bool) {
+	var next time.Time
+	for i := range q.pointers {
+		p := &q.pointers[i]
+		if p.longPress.IsZero() {
+			continue
+		}
+		if now.Before(p.longPress) {
+			if next.IsZero() || p.longPress.Before(next) {
+				next = p.longPress
+			}
+			continue
+		}
+		p.longPress = time.Time{}
+		for _, k := range p.handlers {
+			h, ok := q.handlers[k]
+			if !ok {
+				continue
+			}
+			events.Add(k, pointer.LongPressEvent{
+				PointerID: p.id,
+				Position:  q.invTransform(h.area, p.last.Position),
+			})
+		}
+	}
+	return next, !next.IsZero()
+}
+
 func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e pointer.Event) {
 	foremost := true
 	if p.pressed && len(p.handlers) == 1 {
@@ -739,6 +914,9 @@ func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e poi
 		}
 		e.Position = q.invTransform(h.area, e.Position)
 		events.Add(k, e)
+		if h.consumes {
+			return
+		}
 	}
 }
 
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"image"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"gioui.org/f32"
 	"gioui.org/gesture"
@@ -17,6 +19,7 @@ import (
 	"gioui.org/io/transfer"
 	"gioui.org/op"
 	"gioui.org/op/clip"
+	"gioui.org/op/paint"
 )
 
 func TestPointerWakeup(t *testing.T) {
@@ -61,6 +64,125 @@ func TestPointerDrag(t *testing.T) {
 	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Cancel, pointer.Enter, pointer.Press, pointer.Leave, pointer.Drag)
 }
 
+func TestPointerCoalescing(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+
+	var r Router
+	r.SetPointerCoalescing(true)
+	r.Frame(&ops)
+	r.Events(handler)
+	r.Queue(
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(10, 10)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 20)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(30, 30)},
+		pointer.Event{Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(30, 30)},
+		pointer.Event{Type: pointer.Move, Buttons: pointer.ButtonPrimary, Position: f32.Pt(40, 40)},
+		pointer.Event{Type: pointer.Move, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 50)},
+	)
+	evs := r.Events(handler)
+	assertEventPointerTypeSequence(t, evs, pointer.Enter, pointer.Move, pointer.Press, pointer.Drag)
+	if got, want := evs[1].(pointer.Event).History, []f32.Point{{X: 10, Y: 10}, {X: 20, Y: 20}}; got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("got move history %v, want %v", got, want)
+	}
+	drag := evs[3].(pointer.Event)
+	if got, want := drag.History, []f32.Point{{X: 40, Y: 40}}; got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("got drag history %v, want %v", got, want)
+	}
+	if got, want := drag.Position, f32.Pt(50, 50); got != want {
+		t.Errorf("got drag position %v, want %v", got, want)
+	}
+	// Comparing events panics if they're not comparable.
+	if evs[1] == evs[3] {
+		t.Error("distinct events compare equal")
+	}
+}
+
+func TestPointerLongPress(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+
+	var r Router
+	start := time.Unix(1000, 0)
+	now := start
+	r.pointer.queue.clock = func() time.Time { return now }
+	r.SetLongPressTime(100 * time.Millisecond)
+	r.SetTouchSlop(5)
+	r.Frame(&ops)
+	r.Events(handler)
+	r.Queue(pointer.Event{
+		Type:     pointer.Press,
+		Source:   pointer.Touch,
+		Position: f32.Pt(50, 50),
+		Time:     time.Second,
+	})
+	now = now.Add(10 * time.Millisecond)
+	r.Queue(
+		// Move within the slop.
+		pointer.Event{
+			Type:     pointer.Move,
+			Source:   pointer.Touch,
+			Position: f32.Pt(53, 53),
+			Time:     time.Second + 10*time.Millisecond,
+		},
+	)
+	r.Frame(&ops)
+	if wake, ok := r.LongPressTime(); !ok || !wake.Equal(start.Add(100*time.Millisecond)) {
+		t.Errorf("pending long press scheduled wakeup %v (%v), want %v", wake, ok, start.Add(100*time.Millisecond))
+	}
+	r.Events(handler)
+	now = start.Add(99 * time.Millisecond)
+	r.Frame(&ops)
+	if got := r.Events(handler); len(got) > 0 {
+		t.Errorf("got events %v before the long press time", got)
+	}
+	now = start.Add(100 * time.Millisecond)
+	r.Frame(&ops)
+	want := []event.Event{pointer.LongPressEvent{Position: f32.Pt(53, 53)}}
+	if got := r.Events(handler); !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+
+	// A press delivered late is timed from its timestamp.
+	r.Queue(pointer.Event{
+		Type:     pointer.Release,
+		Source:   pointer.Touch,
+		Position: f32.Pt(53, 53),
+		Time:     1100 * time.Millisecond,
+	})
+	now = start.Add(time.Second + 50*time.Millisecond)
+	r.Queue(pointer.Event{
+		Type:     pointer.Press,
+		Source:   pointer.Touch,
+		Position: f32.Pt(50, 50),
+		Time:     2 * time.Second,
+	})
+	r.Frame(&ops)
+	if wake, ok := r.LongPressTime(); !ok || !wake.Equal(start.Add(time.Second+100*time.Millisecond)) {
+		t.Errorf("late press scheduled wakeup %v (%v), want %v", wake, ok, start.Add(time.Second+100*time.Millisecond))
+	}
+	r.Events(handler)
+
+	// Moving past the slop cancels the long press.
+	r.Queue(pointer.Event{
+		Type:     pointer.Move,
+		Source:   pointer.Touch,
+		Position: f32.Pt(60, 50),
+		Time:     2*time.Second + 10*time.Millisecond,
+	})
+	r.Frame(&ops)
+	r.Events(handler)
+	now = start.Add(3 * time.Second)
+	r.Frame(&ops)
+	for _, e := range r.Events(handler) {
+		if _, ok := e.(pointer.LongPressEvent); ok {
+			t.Errorf("unexpected long press after moving past the slop")
+		}
+	}
+}
+
 func TestPointerDragNegative(t *testing.T) {
 	handler := new(int)
 	var ops op.Ops
@@ -83,6 +205,33 @@ func TestPointerDragNegative(t *testing.T) {
 	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Cancel, pointer.Enter, pointer.Press, pointer.Leave, pointer.Drag)
 }
 
+func TestPointerFrameNotRetained(t *testing.T) {
+	var r Router
+	collected := make(chan struct{})
+	func() {
+		var ops op.Ops
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		runtime.SetFinalizer(img, func(*image.RGBA) { close(collected) })
+		// The reader keeps the ops of macros while decoding.
+		m := op.Record(&ops)
+		addPointerHandler(&ops, new(int), image.Rect(0, 0, 100, 100))
+		paint.NewImageOp(img).Add(&ops)
+		m.Stop().Add(&ops)
+		r.Frame(&ops)
+	}()
+	// The router must not refer to the frame after Frame returns.
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	runtime.KeepAlive(&r)
+	t.Error("Frame retained the frame operations")
+}
+
 func TestPointerGrab(t *testing.T) {
 	handler1 := new(int)
 	handler2 := new(int)
@@ -294,6 +443,128 @@ func TestPointerPriority(t *testing.T) {
 	assertScrollEvent(t, hev3[1], f32.Pt(-20, -30))
 }
 
+func TestPointerConsume(t *testing.T) {
+	handler1 := new(int)
+	handler2 := new(int)
+	var ops op.Ops
+
+	r1 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: handler1, Types: pointer.Press | pointer.Scroll}.Add(&ops)
+	r2 := clip.Rect(image.Rect(0, 0, 100, 50)).Push(&ops)
+	pointer.InputOp{Tag: handler2, Types: pointer.Press, Consume: true}.Add(&ops)
+	r2.Pop()
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		// Hit handler 2, which consumes the press.
+		pointer.Event{
+			Type:     pointer.Press,
+			Position: f32.Pt(50, 25),
+		},
+		pointer.Event{
+			Type:     pointer.Release,
+			Position: f32.Pt(50, 25),
+		},
+		// Handler 2 doesn't consume events it doesn't receive.
+		pointer.Event{
+			Type:     pointer.Scroll,
+			Position: f32.Pt(50, 25),
+			Scroll:   f32.Pt(0, 10),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler1), pointer.Cancel, pointer.Scroll)
+	assertEventPointerTypeSequence(t, r.Events(handler2), pointer.Cancel, pointer.Press)
+}
+
+func TestInputAreas(t *testing.T) {
+	handler1 := new(int)
+	handler2 := new(int)
+	var ops op.Ops
+
+	r1 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: handler1, Types: pointer.Press}.Add(&ops)
+	t1 := op.Offset(image.Pt(50, 50)).Push(&ops)
+	r2 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: handler2, Types: pointer.Scroll}.Add(&ops)
+	r2.Pop()
+	t1.Pop()
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	got := r.AppendInputAreas(nil)
+	want := []InputArea{
+		// Clipped by the area of handler1.
+		{Tag: handler2, Bounds: image.Rect(50, 50, 100, 100), Types: pointer.Scroll},
+		{Tag: handler1, Bounds: image.Rect(0, 0, 100, 100), Types: pointer.Press},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d areas, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("area %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPointerModal(t *testing.T) {
+	background := new(int)
+	modal := new(int)
+	var ops op.Ops
+
+	addPointerHandler(&ops, background, image.Rect(0, 0, 200, 200))
+	r1 := clip.Rect(image.Rect(50, 50, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: modal, Types: pointer.Press | pointer.Dismiss}.Add(&ops)
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.SetModal(modal)
+	r.Events(background)
+	r.Events(modal)
+
+	// A press outside the modal area dismisses.
+	r.Queue(
+		pointer.Event{
+			Type:     pointer.Press,
+			Position: f32.Pt(10, 10),
+		},
+		pointer.Event{
+			Type:     pointer.Release,
+			Position: f32.Pt(10, 10),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(background))
+	assertEventPointerTypeSequence(t, r.Events(modal), pointer.Dismiss)
+
+	// A press inside the modal area is delivered as usual.
+	r.Queue(
+		pointer.Event{
+			Type:     pointer.Press,
+			Position: f32.Pt(60, 60),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(background))
+	assertEventPointerTypeSequence(t, r.Events(modal), pointer.Press)
+
+	// Clearing the modal restores background input.
+	r.SetModal(nil)
+	r.Queue(
+		pointer.Event{
+			Type:     pointer.Release,
+			Position: f32.Pt(60, 60),
+		},
+		pointer.Event{
+			Type:     pointer.Press,
+			Position: f32.Pt(10, 10),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(background), pointer.Enter, pointer.Press)
+}
+
 func TestPointerEnterLeave(t *testing.T) {
 	handler1 := new(int)
 	handler2 := new(int)
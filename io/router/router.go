@@ -15,6 +15,7 @@ import (
 	"image"
 	"io"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -54,10 +55,17 @@ type Router struct {
 	// InvalidateOp summary.
 	wakeup     bool
 	wakeupTime time.Time
+	// longPress is the time of the earliest pending long press, if
+	// hasLongPress is set.
+	hasLongPress bool
+	longPress    time.Time
 
 	// ProfileOp summary.
 	profHandlers map[event.Tag]struct{}
 	profile      profile.Event
+
+	// pressed is the set of keys held down.
+	pressed map[string]struct{}
 }
 
 // SemanticNode represents a node in the tree describing the components
@@ -71,6 +79,20 @@ type SemanticNode struct {
 	areaIdx int
 }
 
+// InputArea describes an area registered for pointer input.
+type InputArea struct {
+	// Tag is the tag of the handler of the area.
+	Tag event.Tag
+	// Bounds of the area in window coordinates, clipped by the areas
+	// it is contained in.
+	Bounds image.Rectangle
+	// Types are the pointer event types the handler receives.
+	Types pointer.Type
+	// PassThrough reports whether hits pass through the area to the
+	// areas behind it.
+	PassThrough bool
+}
+
 // SemanticDesc provides a semantic description of a UI component.
 type SemanticDesc struct {
 	Class       semantic.ClassOp
@@ -98,6 +120,8 @@ type SemanticID uint64
 type handlerEvents struct {
 	handlers  map[event.Tag][]event.Event
 	hadEvents bool
+	// coalesce is set when consecutive pointer moves are merged.
+	coalesce bool
 }
 
 // Events returns the available events for the handler key.
@@ -113,6 +137,9 @@ func (q *Router) Events(k event.Tag) []event.Event {
 // Frame replaces the declared handlers from the supplied
 // operation list. The text input state, wakeup time and whether
 // there are active profile handlers is also saved.
+//
+// Frame doesn't retain frame after it returns, and frame may be
+// reset and re-used immediately.
 func (q *Router) Frame(frame *op.Ops) {
 	q.handlers.Clear()
 	q.wakeup = false
@@ -125,21 +152,50 @@ func (q *Router) Frame(frame *op.Ops) {
 	}
 	q.reader.Reset(ops)
 	q.collect()
+	// Drop the reference to frame.
+	q.reader.Reset(nil)
 
 	q.pointer.queue.Frame(&q.handlers)
 	q.key.queue.Frame(&q.handlers, q.key.collector)
+	q.longPresses()
 	if q.handlers.HadEvents() {
 		q.wakeup = true
 		q.wakeupTime = time.Time{}
 	}
 }
 
+// longPresses delivers the long presses that are due, and notes the
+// time of the next.
+func (q *Router) longPresses() {
+	q.longPress, q.hasLongPress = q.pointer.queue.LongPresses(q.pointer.queue.now(), &q.handlers)
+}
+
+// SetTouchSlop sets the distance in pixels a touch pointer may move
+// without canceling a long press. Zero selects a default of 8 pixels.
+func (q *Router) SetTouchSlop(px float32) {
+	q.pointer.queue.touchSlop = px
+}
+
+// SetPointerCoalescing controls whether consecutive Move or Drag events
+// of a pointer are merged into the latest event until they're delivered,
+// with the earlier positions in pointer.Event.History.
+func (q *Router) SetPointerCoalescing(enable bool) {
+	q.handlers.coalesce = enable
+}
+
+// SetLongPressTime sets the time a touch pointer must be held without
+// moving to generate a pointer.LongPressEvent. Zero selects a default
+// of 500 milliseconds.
+func (q *Router) SetLongPressTime(d time.Duration) {
+	q.pointer.queue.longPressTime = d
+}
+
 // Queue key events to the topmost handler.
 func (q *Router) QueueTopmost(events ...key.Event) bool {
 	var topmost event.Tag
 	pq := &q.pointer.queue
 	for _, h := range pq.hitTree {
-		if h.ktag != nil {
+		if h.ktag != nil && pq.inModal(h.area) {
 			topmost = h.ktag
 			break
 		}
@@ -162,6 +218,7 @@ func (q *Router) Queue(events ...event.Event) bool {
 		case pointer.Event:
 			q.pointer.queue.Push(e, &q.handlers)
 		case key.Event:
+			q.trackKey(e)
 			q.queueKeyEvent(e)
 		case key.SnippetEvent:
 			// Expand existing, overlapping snippet.
@@ -176,7 +233,15 @@ func (q *Router) Queue(events ...event.Event) bool {
 			if f := q.key.queue.focus; f != nil {
 				q.handlers.Add(f, e)
 			}
-		case key.EditEvent, key.FocusEvent, key.SelectionEvent:
+		case key.FocusEvent:
+			if !e.Focus {
+				// Release events are not delivered without focus.
+				q.pressed = nil
+			}
+			if f := q.key.queue.focus; f != nil {
+				q.handlers.Add(f, e)
+			}
+		case key.EditEvent, key.SelectionEvent:
 			if f := q.key.queue.focus; f != nil {
 				q.handlers.Add(f, e)
 			}
@@ -187,6 +252,30 @@ func (q *Router) Queue(events ...event.Event) bool {
 	return q.handlers.HadEvents()
 }
 
+func (q *Router) trackKey(e key.Event) {
+	switch e.State {
+	case key.Press:
+		if q.pressed == nil {
+			q.pressed = make(map[string]struct{})
+		}
+		q.pressed[e.Name] = struct{}{}
+	case key.Release:
+		delete(q.pressed, e.Name)
+	}
+}
+
+// PressedKeys returns the names of the keys held down, in sorted
+// order. The set is updated by the key.Event press and release events
+// passed to Queue, and cleared by a key.FocusEvent that removes focus.
+func (q *Router) PressedKeys() []string {
+	keys := make([]string, 0, len(q.pressed))
+	for k := range q.pressed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func rangeOverlaps(r1, r2 key.Range) bool {
 	r1 = rangeNorm(r1)
 	r2 = rangeNorm(r2)
@@ -203,12 +292,15 @@ func rangeNorm(r key.Range) key.Range {
 
 func (q *Router) queueKeyEvent(e key.Event) {
 	kq := &q.key.queue
+	pq := &q.pointer.queue
+	// Accelerators outside the modal handler are ignored.
+	accel, isAccel := kq.AcceleratorFor(e, pq.inModal)
+	e.Accelerator = isAccel
 	f := q.key.queue.focus
-	if f != nil && kq.Accepts(f, e) {
+	if f != nil && kq.Accepts(f, e) && q.keyInModal(f) {
 		q.handlers.Add(f, e)
 		return
 	}
-	pq := &q.pointer.queue
 	idx := len(pq.hitTree) - 1
 	focused := f != nil
 	if focused {
@@ -230,14 +322,39 @@ func (q *Router) queueKeyEvent(e key.Event) {
 		} else {
 			idx--
 		}
-		if n.ktag == nil {
+		if n.ktag == nil || !pq.inModal(n.area) {
 			continue
 		}
 		if kq.Accepts(n.ktag, e) {
 			q.handlers.Add(n.ktag, e)
-			break
+			return
+		}
+	}
+	if isAccel {
+		// No handler accepts the event; deliver it to the accelerator.
+		q.handlers.Add(accel, e)
+	}
+}
+
+// keyInModal reports whether the key handler is inside the area of
+// the modal handler, if any.
+func (q *Router) keyInModal(tag event.Tag) bool {
+	pq := &q.pointer.queue
+	for _, n := range pq.hitTree {
+		if n.ktag == tag {
+			return pq.inModal(n.area)
 		}
 	}
+	return true
+}
+
+// SetModal restricts pointer and key input to the handler for tag and
+// the handlers inside its area. Presses outside the area are reported
+// to the modal handler as pointer.Dismiss events, if it has
+// requested them. A nil tag or a modal handler that is no longer
+// present in the frame lifts the restriction.
+func (q *Router) SetModal(tag event.Tag) {
+	q.pointer.queue.modal = tag
 }
 
 func (q *Router) MoveFocus(dir FocusDirection) bool {
@@ -335,8 +452,25 @@ func (q *Router) TextInputState() TextInputState {
 	return q.key.queue.InputState()
 }
 
-// TextInputHint returns the input mode from the most recent key.InputOp.
-func (q *Router) TextInputHint() (key.InputHint, bool) {
+// SetTextInputState requests a change of the text input state, as if by
+// a key.SoftKeyboardOp. TextInputState returns the state until the next
+// call to Frame changes it.
+func (q *Router) SetTextInputState(state TextInputState) {
+	if state != TextInputKeep {
+		q.key.queue.state = state
+	}
+}
+
+// TextInputTarget returns the focused key handler, which is the target
+// of the text input. A text input that stays open when the focus moves
+// between text fields must switch to the new target.
+func (q *Router) TextInputTarget() event.Tag {
+	return q.key.queue.focus
+}
+
+// TextInputHint returns the input mode and options from the most recent
+// key.InputOp, and whether they changed.
+func (q *Router) TextInputHint() (key.InputHint, key.InputOptions, bool) {
 	return q.key.queue.InputHint()
 }
 
@@ -370,6 +504,13 @@ func (q *Router) AppendSemantics(nodes []SemanticNode) []SemanticNode {
 	return q.pointer.queue.AppendSemantics(nodes)
 }
 
+// AppendInputAreas appends the pointer input areas of the most recent
+// frame to areas, and returns the result. Areas are in hit test order,
+// topmost first. AppendInputAreas is meant for debugging.
+func (q *Router) AppendInputAreas(areas []InputArea) []InputArea {
+	return q.pointer.queue.AppendInputAreas(areas)
+}
+
 // EditorState returns the editor state for the focused handler, or the
 // zero value if there is none.
 func (q *Router) EditorState() EditorState {
@@ -442,9 +583,10 @@ func (q *Router) collect() {
 			pc.popPass()
 		case ops.TypePointerInput:
 			op := pointer.InputOp{
-				Tag:   encOp.Refs[0].(event.Tag),
-				Grab:  encOp.Data[1] != 0,
-				Types: pointer.Type(bo.Uint16(encOp.Data[2:])),
+				Tag:     encOp.Refs[0].(event.Tag),
+				Grab:    encOp.Data[1]&1 != 0,
+				Consume: encOp.Data[1]&2 != 0,
+				Types:   pointer.Type(bo.Uint16(encOp.Data[2:])),
 				ScrollBounds: image.Rectangle{
 					Min: image.Point{
 						X: int(int32(bo.Uint32(encOp.Data[4:]))),
@@ -490,6 +632,12 @@ func (q *Router) collect() {
 				Tag: tag,
 			}
 			kc.focusOp(op.Tag)
+		case ops.TypeKeyAccelerator:
+			op := key.AcceleratorOp{
+				Tag:  encOp.Refs[0].(event.Tag),
+				Keys: *encOp.Refs[1].(*key.Set),
+			}
+			kc.acceleratorOp(op, pc.currentArea())
 		case ops.TypeKeySoftKeyboard:
 			op := key.SoftKeyboardOp{
 				Show: encOp.Data[1] != 0,
@@ -498,9 +646,10 @@ func (q *Router) collect() {
 		case ops.TypeKeyInput:
 			filter := encOp.Refs[1].(*key.Set)
 			op := key.InputOp{
-				Tag:  encOp.Refs[0].(event.Tag),
-				Hint: key.InputHint(encOp.Data[1]),
-				Keys: *filter,
+				Tag:     encOp.Refs[0].(event.Tag),
+				Hint:    key.InputHint(encOp.Data[1]),
+				Options: key.InputOptions(encOp.Data[2]),
+				Keys:    *filter,
 			}
 			a := pc.currentArea()
 			b := pc.currentAreaBounds()
@@ -569,11 +718,19 @@ func (q *Router) Profiling() bool {
 }
 
 // WakeupTime returns the most recent time for doing another frame,
-// as determined from the last call to Frame.
+// as determined from the last call to Frame. The time is in the frame
+// time of InvalidateOp.
 func (q *Router) WakeupTime() (time.Time, bool) {
 	return q.wakeupTime, q.wakeup
 }
 
+// LongPressTime returns the wall clock time of the earliest pending
+// long press, as determined from the last call to Frame. Another frame
+// is needed at that time to deliver the pointer.LongPressEvent.
+func (q *Router) LongPressTime() (time.Time, bool) {
+	return q.longPress, q.hasLongPress
+}
+
 func (h *handlerEvents) init() {
 	if h.handlers == nil {
 		h.handlers = make(map[event.Tag][]event.Event)
@@ -582,7 +739,39 @@ func (h *handlerEvents) init() {
 
 func (h *handlerEvents) AddNoRedraw(k event.Tag, e event.Event) {
 	h.init()
-	h.handlers[k] = append(h.handlers[k], e)
+	events := h.handlers[k]
+	if h.coalesce && len(events) > 0 {
+		if e, ok := coalesceMove(events[len(events)-1], e); ok {
+			events[len(events)-1] = e
+			return
+		}
+	}
+	h.handlers[k] = append(events, e)
+}
+
+// coalesceMove merges the pointer move e into the pending event prev,
+// if they are moves of the same pointer in the same state.
+func coalesceMove(prev, e event.Event) (pointer.Event, bool) {
+	p, ok := prev.(pointer.Event)
+	if !ok {
+		return pointer.Event{}, false
+	}
+	n, ok := e.(pointer.Event)
+	if !ok || n.Type != p.Type || (n.Type != pointer.Move && n.Type != pointer.Drag) {
+		return pointer.Event{}, false
+	}
+	if n.PointerID != p.PointerID || n.Source != p.Source || n.Buttons != p.Buttons ||
+		n.Modifiers != p.Modifiers || n.Priority != p.Priority {
+		return pointer.Event{}, false
+	}
+	var hist []f32.Point
+	if p.History != nil {
+		hist = *p.History
+	}
+	hist = append(hist, p.Position)
+	n.History = &hist
+	n.Delta = n.Delta.Add(p.Delta)
+	return n, true
 }
 
 func (h *handlerEvents) Add(k event.Tag, e event.Event) {
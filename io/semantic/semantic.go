@@ -7,6 +7,9 @@
 // Semantic descriptions are organized in a tree, with clip operations as
 // nodes. Operations in this package are associated with the current semantic
 // node, that is the most recent pushed clip operation.
+//
+// Semantic descriptions are exposed to TalkBack on Android and VoiceOver
+// on macOS.
 package semantic
 
 import (
@@ -26,8 +26,15 @@ type FrameEvent struct {
 	Size image.Point
 	// Insets represent the space occupied by system decorations and controls.
 	Insets Insets
+	// Locale is the language and layout direction of the window.
+	Locale Locale
 	// Frame completes the FrameEvent by drawing the graphical operations
 	// from ops into the window.
+	//
+	// Frame doesn't retain the operations after it returns, even though the
+	// window may not yet have presented them. The caller is free to reset
+	// and re-use the ops, or build the next frame concurrently in a different
+	// ops list.
 	Frame func(frame *op.Ops)
 	// Queue supplies the events for event handlers.
 	Queue event.Queue
@@ -39,8 +46,28 @@ type DestroyEvent struct {
 	// Err is nil for normal window closures. If a
 	// window is prematurely closed, Err is the cause.
 	Err error
+	// Reason describes why the window was destroyed.
+	Reason DestroyReason
 }
 
+// DestroyReason describes why a window was destroyed.
+type DestroyReason uint8
+
+const (
+	// DestroyUserClosed is for windows closed by the user, for
+	// example through the window close button.
+	DestroyUserClosed DestroyReason = iota
+	// DestroyAppClosed is for windows closed by the program
+	// through ActionClose.
+	DestroyAppClosed
+	// DestroyError is for windows closed prematurely because
+	// of an error. DestroyEvent.Err holds the error.
+	DestroyError
+	// DestroySystemShutdown is for windows closed because the
+	// user session is ending.
+	DestroySystemShutdown
+)
+
 // Insets is the space taken up by
 // system decoration such as translucent
 // system bars and software keyboards.
@@ -59,8 +86,8 @@ type StageEvent struct {
 type Stage uint8
 
 const (
-	// StagePaused is the stage for windows that have no on-screen representation.
-	// Paused windows don't receive FrameEvent.
+	// StagePaused is the stage for windows that have no on-screen representation,
+	// including windows with a zero size. Paused windows don't receive FrameEvent.
 	StagePaused Stage = iota
 	// StageInactive is the stage for windows that are visible, but not active.
 	// Inactive windows receive FrameEvent.
@@ -84,6 +111,22 @@ func (l Stage) String() string {
 	}
 }
 
+// String implements fmt.Stringer.
+func (r DestroyReason) String() string {
+	switch r {
+	case DestroyUserClosed:
+		return "DestroyUserClosed"
+	case DestroyAppClosed:
+		return "DestroyAppClosed"
+	case DestroyError:
+		return "DestroyError"
+	case DestroySystemShutdown:
+		return "DestroySystemShutdown"
+	default:
+		panic("unexpected DestroyReason value")
+	}
+}
+
 func (FrameEvent) ImplementsEvent()   {}
 func (StageEvent) ImplementsEvent()   {}
 func (DestroyEvent) ImplementsEvent() {}
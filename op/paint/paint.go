@@ -15,8 +15,24 @@ import (
 	"gioui.org/op/clip"
 )
 
+// ImageFilter is the filter for sampling an image when it is scaled.
+type ImageFilter uint8
+
+const (
+	// FilterLinear interpolates between the pixels of the image, for
+	// smooth scaling.
+	FilterLinear ImageFilter = iota
+	// FilterNearest samples the nearest pixel of the image, for crisp
+	// scaling of pixel art.
+	FilterNearest
+)
+
 // ImageOp sets the brush to an image.
 type ImageOp struct {
+	// Filter is the filter for scaling the image. The filter is ignored
+	// by the compute renderer.
+	Filter ImageFilter
+
 	uniform bool
 	color   color.NRGBA
 	src     *image.RGBA
@@ -95,6 +111,7 @@ func (i ImageOp) Add(o *op.Ops) {
 	}
 	data := ops.Write2(&o.Internal, ops.TypeImageLen, i.src, i.handle)
 	data[0] = byte(ops.TypeImage)
+	data[1] = byte(i.Filter)
 }
 
 func (c ColorOp) Add(o *op.Ops) {
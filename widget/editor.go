@@ -50,6 +50,9 @@ type Editor struct {
 	Mask rune
 	// InputHint specifies the type of on-screen keyboard to be displayed.
 	InputHint key.InputHint
+	// InputOptions control the corrections of the input method, such as
+	// to disable autocorrection for code or user names.
+	InputOptions key.InputOptions
 	// MaxLen limits the editor content to a maximum length. Zero means no limit.
 	MaxLen int
 	// Filter is the list of characters allowed in the Editor. If Filter is empty,
@@ -620,7 +623,7 @@ func (e *Editor) layout(gtx layout.Context, content layout.Widget) layout.Dimens
 			keys = keyFilterAllArrows
 		}
 	}
-	key.InputOp{Tag: &e.eventKey, Hint: e.InputHint, Keys: keys}.Add(gtx.Ops)
+	key.InputOp{Tag: &e.eventKey, Hint: e.InputHint, Options: e.InputOptions, Keys: keys}.Add(gtx.Ops)
 	if e.requestFocus {
 		key.FocusOp{Tag: &e.eventKey}.Add(gtx.Ops)
 		key.SoftKeyboardOp{Show: true}.Add(gtx.Ops)